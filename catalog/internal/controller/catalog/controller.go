@@ -18,10 +18,13 @@ type catalogRepository interface {
 	Categories(ctx context.Context) ([]*model.Category, error)
 	ProductsByCategoryID(ctx context.Context, id int64) ([]*model.Product, error)
 	ProductByID(ctx context.Context, id int64) (*model.Product, error)
+	ProductsByIDs(ctx context.Context, ids []int64) ([]*model.Product, error)
 	DecreaseProductQuantity(ctx context.Context, id int64, amount int32) error
 	IncreaseProductQuantity(ctx context.Context, id int64, amount int32) error
 	PutCategory(ctx context.Context, category *model.Category) error
 	PutProduct(ctx context.Context, product *model.Product) error
+	UpdateCategory(ctx context.Context, category *model.Category) error
+	UpdateProduct(ctx context.Context, product *model.Product, originalQuantity int32) error
 }
 
 type Controller struct {
@@ -61,6 +64,39 @@ func (c *Controller) ProductByID(ctx context.Context, id int64) (*model.Product,
 	return product, nil
 }
 
+// ProductsByIDs returns the products with the IDs in the order the IDs are
+// given, each once, and the IDs of the products that do not exist.
+func (c *Controller) ProductsByIDs(ctx context.Context, ids []int64) ([]*model.Product, []int64, error) {
+	found, err := c.repo.ProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[int64]*model.Product, len(found))
+	for _, product := range found {
+		byID[product.ID] = product
+	}
+
+	products := make([]*model.Product, 0, len(found))
+	missing := []int64{}
+	seen := make(map[int64]bool, len(ids))
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if product, ok := byID[id]; ok {
+			products = append(products, product)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	return products, missing, nil
+}
+
 func (c *Controller) DecreaseProductQuantity(ctx context.Context, id int64, amount int32) error {
 	err := c.repo.DecreaseProductQuantity(ctx, id, amount)
 	if err != nil {
@@ -98,3 +134,34 @@ func (c *Controller) PutCategory(ctx context.Context, category *model.Category)
 func (c *Controller) PutProduct(ctx context.Context, product *model.Product) error {
 	return c.repo.PutProduct(ctx, product)
 }
+
+func (c *Controller) UpdateCategory(ctx context.Context, category *model.Category) error {
+	err := c.repo.UpdateCategory(ctx, category)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdateProduct saves the product. originalQuantity is the stock the edit
+// started from; if the stock has changed since, ErrEditConflict is returned
+// and nothing is saved.
+func (c *Controller) UpdateProduct(ctx context.Context, product *model.Product, originalQuantity int32) error {
+	err := c.repo.UpdateProduct(ctx, product, originalQuantity)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrNotFound
+		case errors.Is(err, repository.ErrEditConflict):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
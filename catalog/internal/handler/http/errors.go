@@ -34,6 +34,10 @@ func (h *Handler) badRequestResponse(w http.ResponseWriter, r *http.Request, err
 	h.errorResponse(w, r, http.StatusBadRequest, err.Error())
 }
 
+func (h *Handler) failedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
+	h.errorResponse(w, r, http.StatusUnprocessableEntity, errors)
+}
+
 func (h *Handler) editConflictResponse(w http.ResponseWriter, r *http.Request) {
 	message := "unable to update due to an edit conflict, please try again"
 	h.errorResponse(w, r, http.StatusConflict, message)
@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
 )
 
 type envelope map[string]any
@@ -101,3 +104,44 @@ func (h *Handler) readJSON(w http.ResponseWriter, r *http.Request, dst any) erro
 
 	return nil
 }
+
+// maxLookupIDs caps the number of products looked up at once.
+const maxLookupIDs = 100
+
+// readIDs reads a comma-separated list of product IDs.
+func readIDs(s string) ([]int64, error) {
+	if s == "" {
+		return nil, errors.New("must be provided")
+	}
+
+	fields := strings.Split(s, ",")
+	if len(fields) > maxLookupIDs {
+		return nil, fmt.Errorf("must not contain more than %d IDs", maxLookupIDs)
+	}
+
+	ids := make([]int64, 0, len(fields))
+	for _, field := range fields {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil || id < 1 {
+			return nil, errors.New("must be a comma-separated list of positive integers")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// validateProduct reports the problems with the price of a product, keyed by
+// JSON field name.
+func validateProduct(product *model.Product) map[string]string {
+	errs := map[string]string{}
+
+	switch {
+	case product.Price.Amount < 0:
+		errs["price"] = "must not be negative"
+	case !money.ValidCurrency(product.Price.Currency):
+		errs["price"] = "currency must be a three-letter ISO 4217 code"
+	}
+
+	return errs
+}
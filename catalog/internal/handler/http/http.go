@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Maksim-Kot/Commons/money"
 	"github.com/Maksim-Kot/Tech-store-catalog/config"
 	"github.com/Maksim-Kot/Tech-store-catalog/internal/controller/catalog"
 	"github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
@@ -107,6 +108,31 @@ func (h *Handler) ProductByIDHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ProductsHandler looks up several products at once, e.g. ?ids=1,2,3. The
+// products come in the order of the IDs; IDs of products that do not exist
+// are listed as missing.
+func (h *Handler) ProductsHandler(w http.ResponseWriter, r *http.Request) {
+	ids, err := readIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		h.failedValidationResponse(w, r, map[string]string{"ids": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	products, missing, err := h.ctrl.ProductsByIDs(ctx, ids)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"products": products, "missing": missing}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
 func (h *Handler) DecreaseProductQuantityHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := h.getID(r)
 	if err != nil || id < 1 {
@@ -201,11 +227,12 @@ func (h *Handler) PutProductHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
 		Name        string          `json:"name"`
 		Description string          `json:"description,omitempty"`
-		Price       float64         `json:"price"`
+		Price       money.Money     `json:"price"`
 		Quantity    int32           `json:"quantity"`
 		ImageURL    string          `json:"image_url,omitempty"`
 		Attributes  json.RawMessage `json:"attributes"`
 		CategoryID  int64           `json:"category_id"`
+		Weight      float64         `json:"weight"`
 	}
 
 	err := h.readJSON(w, r, &input)
@@ -217,11 +244,17 @@ func (h *Handler) PutProductHandler(w http.ResponseWriter, r *http.Request) {
 	product := &model.Product{
 		Name:        input.Name,
 		Description: input.Description,
-		Price:       input.Price,
+		Price:       money.New(input.Price.Amount, model.NormalizeCurrency(input.Price.Currency)),
 		Quantity:    input.Quantity,
 		ImageURL:    input.ImageURL,
 		Attributes:  input.Attributes,
 		CategoryID:  input.CategoryID,
+		Weight:      input.Weight,
+	}
+
+	if errs := validateProduct(product); len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -238,3 +271,114 @@ func (h *Handler) PutProductHandler(w http.ResponseWriter, r *http.Request) {
 		h.ServerErrorResponse(w, r, err)
 	}
 }
+
+func (h *Handler) UpdateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	category := &model.Category{
+		ID:   id,
+		Name: input.Name,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = h.ctrl.UpdateCategory(ctx, category)
+	if err != nil {
+		switch {
+		case errors.Is(err, catalog.ErrNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"category": category}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) UpdateProductHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Price       money.Money     `json:"price"`
+		Quantity    int32           `json:"quantity"`
+		ImageURL    string          `json:"image_url,omitempty"`
+		Attributes  json.RawMessage `json:"attributes"`
+		CategoryID  int64           `json:"category_id"`
+		Weight      float64         `json:"weight"`
+		// OriginalQuantity is the stock the edit started from.
+		OriginalQuantity *int32 `json:"original_quantity"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	product := &model.Product{
+		ID:          id,
+		Name:        input.Name,
+		Description: input.Description,
+		Price:       money.New(input.Price.Amount, model.NormalizeCurrency(input.Price.Currency)),
+		Quantity:    input.Quantity,
+		ImageURL:    input.ImageURL,
+		Attributes:  input.Attributes,
+		CategoryID:  input.CategoryID,
+		Weight:      input.Weight,
+	}
+
+	errs := validateProduct(product)
+	if input.OriginalQuantity == nil {
+		errs["original_quantity"] = "must be provided"
+	}
+	if len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = h.ctrl.UpdateProduct(ctx, product, *input.OriginalQuantity)
+	if err != nil {
+		switch {
+		case errors.Is(err, catalog.ErrNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, catalog.ErrEditConflict):
+			h.editConflictResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"product": product}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
@@ -73,6 +73,24 @@ func (r *Repository) ProductByID(_ context.Context, id int64) (*model.Product, e
 	return product, nil
 }
 
+func (r *Repository) ProductsByIDs(_ context.Context, ids []int64) ([]*model.Product, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var products []*model.Product
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok && !slices.Contains(products, product) {
+			products = append(products, product)
+		}
+	}
+
+	slices.SortFunc(products, func(a, b *model.Product) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return products, nil
+}
+
 func (r *Repository) DecreaseProductQuantity(_ context.Context, id int64, amount int32) error {
 	r.Lock()
 	defer r.Unlock()
@@ -126,3 +144,33 @@ func (r *Repository) PutProduct(ctx context.Context, product *model.Product) err
 
 	return nil
 }
+
+func (r *Repository) UpdateCategory(_ context.Context, category *model.Category) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.categories[category.ID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	r.categories[category.ID] = category
+
+	return nil
+}
+
+func (r *Repository) UpdateProduct(_ context.Context, product *model.Product, originalQuantity int32) error {
+	r.Lock()
+	defer r.Unlock()
+
+	current, ok := r.products[product.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if current.Quantity != originalQuantity {
+		return repository.ErrEditConflict
+	}
+
+	r.products[product.ID] = product
+
+	return nil
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/Maksim-Kot/Tech-store-catalog/internal/repository"
 	"github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -83,7 +83,7 @@ func (r *Repository) Categories(ctx context.Context) ([]*model.Category, error)
 
 func (r *Repository) ProductsByCategoryID(ctx context.Context, id int64) ([]*model.Product, error) {
 	query := `
-		SELECT id, name, description, price, quantity, image_url, attributes, category_id
+		SELECT id, name, description, price, currency, quantity, image_url, attributes, category_id, weight
 		FROM items
 		WHERE category_id = $1
 		ORDER BY id`
@@ -104,10 +104,12 @@ func (r *Repository) ProductsByCategoryID(ctx context.Context, id int64) ([]*mod
 			&product.Name,
 			&product.Description,
 			&product.Price,
+			&product.Price.Currency,
 			&product.Quantity,
 			&product.ImageURL,
 			&product.Attributes,
 			&product.CategoryID,
+			&product.Weight,
 		)
 		if err != nil {
 			return nil, err
@@ -133,7 +135,7 @@ func (r *Repository) ProductByID(ctx context.Context, id int64) (*model.Product,
 	}
 
 	query := `
-		SELECT id, name, description, price, quantity, image_url, attributes, category_id
+		SELECT id, name, description, price, currency, quantity, image_url, attributes, category_id, weight
 		FROM items
 		WHERE id = $1`
 
@@ -144,10 +146,12 @@ func (r *Repository) ProductByID(ctx context.Context, id int64) (*model.Product,
 		&product.Name,
 		&product.Description,
 		&product.Price,
+		&product.Price.Currency,
 		&product.Quantity,
 		&product.ImageURL,
 		&product.Attributes,
 		&product.CategoryID,
+		&product.Weight,
 	)
 
 	if err != nil {
@@ -162,6 +166,51 @@ func (r *Repository) ProductByID(ctx context.Context, id int64) (*model.Product,
 	return &product, nil
 }
 
+// ProductsByIDs returns the products with the IDs, ordered by ID. IDs of
+// products that do not exist are skipped.
+func (r *Repository) ProductsByIDs(ctx context.Context, ids []int64) ([]*model.Product, error) {
+	query := `
+		SELECT id, name, description, price, currency, quantity, image_url, attributes, category_id, weight
+		FROM items
+		WHERE id = ANY($1)
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*model.Product
+
+	for rows.Next() {
+		var product model.Product
+		err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.Description,
+			&product.Price,
+			&product.Price.Currency,
+			&product.Quantity,
+			&product.ImageURL,
+			&product.Attributes,
+			&product.CategoryID,
+			&product.Weight,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		products = append(products, &product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
 func (r *Repository) DecreaseProductQuantity(ctx context.Context, id int64, amount int32) error {
 	queryExist := `SELECT quantity FROM items WHERE id = $1`
 	var quantity int32
@@ -239,18 +288,20 @@ func (r *Repository) PutCategory(ctx context.Context, category *model.Category)
 
 func (r *Repository) PutProduct(ctx context.Context, product *model.Product) error {
 	query := `
-		INSERT INTO items (name, description, price, quantity, image_url, attributes, category_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO items (name, description, price, currency, quantity, image_url, attributes, category_id, weight)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
 	args := []any{
 		product.Name,
 		product.Description,
 		product.Price,
+		product.Price.Currency,
 		product.Quantity,
 		product.ImageURL,
 		product.Attributes,
 		product.CategoryID,
+		product.Weight,
 	}
 	err := r.DB.QueryRowContext(ctx, query, args...).Scan(&product.ID)
 
@@ -259,3 +310,74 @@ func (r *Repository) PutProduct(ctx context.Context, product *model.Product) err
 	}
 	return nil
 }
+
+func (r *Repository) UpdateCategory(ctx context.Context, category *model.Category) error {
+	query := `
+		UPDATE categories
+		SET name = $2
+		WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, category.ID, category.Name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateProduct saves the product only if its stock is still
+// originalQuantity, the quantity the editor started from. Otherwise the stock
+// has moved since, through an order or a restock, and writing the edited
+// quantity over it would lose that change, so ErrEditConflict is returned.
+func (r *Repository) UpdateProduct(ctx context.Context, product *model.Product, originalQuantity int32) error {
+	query := `
+		UPDATE items
+		SET name = $2, description = $3, price = $4, currency = $5, quantity = $6, image_url = $7, attributes = $8, category_id = $9, weight = $10
+		WHERE id = $1 AND quantity = $11`
+
+	args := []any{
+		product.ID,
+		product.Name,
+		product.Description,
+		product.Price,
+		product.Price.Currency,
+		product.Quantity,
+		product.ImageURL,
+		product.Attributes,
+		product.CategoryID,
+		product.Weight,
+		originalQuantity,
+	}
+
+	res, err := r.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT true FROM items WHERE id = $1)`, product.ID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+
+	return repository.ErrEditConflict
+}
@@ -13,12 +13,15 @@ func (s *Server) routes() http.Handler {
 	router.HandleFunc("GET /catalog", s.handler.CategoriesHandler)
 	router.HandleFunc("GET /category/{id}", s.handler.ProductsByCategoryIDHandler)
 	router.HandleFunc("GET /product/{id}", s.handler.ProductByIDHandler)
+	router.HandleFunc("GET /products", s.handler.ProductsHandler)
 
 	router.HandleFunc("POST /product/{id}/decrease/{amount}", s.handler.DecreaseProductQuantityHandler)
 	router.HandleFunc("POST /product/{id}/increase/{amount}", s.handler.IncreaseProductQuantityHandler)
 
 	router.HandleFunc("POST /category", s.handler.PutCategoryHandler)
 	router.HandleFunc("POST /product", s.handler.PutProductHandler)
+	router.HandleFunc("PUT /category/{id}", s.handler.UpdateCategoryHandler)
+	router.HandleFunc("PUT /product/{id}", s.handler.UpdateProductHandler)
 
 	v1 := http.NewServeMux()
 	v1.Handle("/v1/", http.StripPrefix("/v1", router))
@@ -1,6 +1,14 @@
 package model
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+// DefaultCurrency is the currency of products stored without one.
+const DefaultCurrency = "BYN"
 
 type Category struct {
 	ID   int64  `json:"id"`
@@ -11,9 +19,21 @@ type Product struct {
 	ID          int64           `json:"id"`
 	Name        string          `json:"name"`
 	Description string          `json:"description,omitempty"`
-	Price       float64         `json:"price"`
+	Price       money.Money     `json:"price"`
 	Quantity    int32           `json:"quantity"`
 	ImageURL    string          `json:"image_url,omitempty"`
 	Attributes  json.RawMessage `json:"attributes"`
 	CategoryID  int64           `json:"category_id"`
+	// Weight is the shipping weight of one unit in kilograms.
+	Weight float64 `json:"weight"`
+}
+
+// NormalizeCurrency makes currency codes upper case and defaults an empty one
+// to DefaultCurrency.
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency
+	}
+	return code
 }
@@ -7,9 +7,11 @@ CREATE TABLE items (
     id          BIGSERIAL PRIMARY KEY,
     name        TEXT NOT NULL,
     description TEXT NOT NULL,
-    price       NUMERIC(10, 2) NOT NULL,
+    price       BIGINT NOT NULL,
+    currency    CHAR(3) NOT NULL DEFAULT 'BYN',
     quantity    INTEGER NOT NULL,
     image_url   TEXT NOT NULL,
     attributes  JSONB NOT NULL,
-    category_id BIGINT NOT NULL REFERENCES categories(id)
+    category_id BIGINT NOT NULL REFERENCES categories(id),
+    weight      NUMERIC(10, 3) NOT NULL DEFAULT 0
 );
\ No newline at end of file
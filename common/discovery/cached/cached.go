@@ -0,0 +1,182 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/discovery"
+)
+
+const (
+	// pollInterval is how often the addresses are fetched again from
+	// registries that cannot watch them.
+	pollInterval = 10 * time.Second
+	// retryInterval is how long to wait after the registry failed to answer.
+	retryInterval = 5 * time.Second
+)
+
+// Registry keeps the addresses of the services asked about in memory and
+// refreshes them in the background, so ServiceAddresses does not reach the
+// registry on every call. Registries that implement discovery.Watcher are
+// watched; the others are polled. When the registry cannot be reached, the
+// last addresses it gave keep being served. Registering and reporting health
+// go straight to the registry.
+type Registry struct {
+	discovery.Registry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	services map[string]*service
+}
+
+type service struct {
+	// ready is closed once the first answer from the registry is in.
+	ready chan struct{}
+
+	mu    sync.RWMutex
+	addrs []string
+	err   error
+}
+
+func New(registry discovery.Registry) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Registry{
+		Registry: registry,
+		ctx:      ctx,
+		cancel:   cancel,
+		services: map[string]*service{},
+	}
+}
+
+// Close stops refreshing the addresses.
+func (r *Registry) Close() {
+	r.cancel()
+}
+
+// ServiceAddresses returns the addresses of active instances of the service
+// as last known. The first call for a service waits for the registry to
+// answer; if it fails and no addresses are known yet, its error is returned.
+func (r *Registry) ServiceAddresses(ctx context.Context, serviceName string) ([]string, error) {
+	s := r.service(serviceName)
+
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.addrs) == 0 {
+		return nil, discovery.ErrNotFound
+	}
+
+	return slices.Clone(s.addrs), nil
+}
+
+// service returns the cached state of the service, starting to refresh it
+// when it is asked about for the first time.
+func (r *Registry) service(serviceName string) *service {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.services[serviceName]
+	if !ok {
+		s = &service{ready: make(chan struct{})}
+		r.services[serviceName] = s
+
+		if w, ok := r.Registry.(discovery.Watcher); ok {
+			go r.watch(serviceName, s, w)
+		} else {
+			go r.poll(serviceName, s)
+		}
+	}
+
+	return s
+}
+
+func (r *Registry) watch(serviceName string, s *service, w discovery.Watcher) {
+	var index uint64
+
+	for r.ctx.Err() == nil {
+		addrs, next, err := w.WatchServiceAddresses(r.ctx, serviceName, index)
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			r.update(serviceName, s, nil, err)
+			r.wait(retryInterval)
+			continue
+		}
+		r.update(serviceName, s, addrs, nil)
+
+		// An index going backwards means the registry lost its state, so
+		// start over. An index of 0 would make the next watch return at once.
+		if next < index {
+			next = 0
+		}
+		if next == 0 {
+			r.wait(pollInterval)
+		}
+		index = next
+	}
+}
+
+func (r *Registry) poll(serviceName string, s *service) {
+	for r.ctx.Err() == nil {
+		addrs, err := r.Registry.ServiceAddresses(r.ctx, serviceName)
+		if errors.Is(err, discovery.ErrNotFound) {
+			addrs, err = nil, nil
+		}
+		if err != nil && r.ctx.Err() != nil {
+			return
+		}
+		r.update(serviceName, s, addrs, err)
+
+		if err != nil {
+			r.wait(retryInterval)
+		} else {
+			r.wait(pollInterval)
+		}
+	}
+}
+
+// update records an answer from the registry. A failure only replaces the
+// addresses while none are known; otherwise the last known ones stay.
+func (r *Registry) update(serviceName string, s *service, addrs []string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case err == nil:
+		s.addrs, s.err = addrs, nil
+	case len(s.addrs) == 0:
+		s.err = err
+	default:
+		log.Printf("[registry] failed to refresh %s, keeping %d known addresses: %v", serviceName, len(s.addrs), err)
+	}
+
+	select {
+	case <-s.ready:
+	default:
+		close(s.ready)
+	}
+}
+
+func (r *Registry) wait(d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-r.ctx.Done():
+	}
+}
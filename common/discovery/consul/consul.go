@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Maksim-Kot/Commons/discovery"
 
 	consul "github.com/hashicorp/consul/api"
 )
 
+// watchWait is how long a blocking query waits for a change before Consul
+// answers with the current state.
+const watchWait = 5 * time.Minute
+
 type Registry struct {
 	client *consul.Client
 }
@@ -65,6 +70,23 @@ func (r *Registry) ServiceAddresses(ctx context.Context, serviceName string) ([]
 	return res, nil
 }
 
+// WatchServiceAddresses is a Consul blocking query for the healthy instances
+// of the service, which waits up to watchWait for a change.
+func (r *Registry) WatchServiceAddresses(ctx context.Context, serviceName string, index uint64) ([]string, uint64, error) {
+	opts := (&consul.QueryOptions{WaitIndex: index, WaitTime: watchWait}).WithContext(ctx)
+
+	entries, meta, err := r.client.Health().Service(serviceName, "", true, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]string, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, fmt.Sprintf("%s:%d", e.Service.Address, e.Service.Port))
+	}
+	return res, meta.LastIndex, nil
+}
+
 // ReportHealthyState is a push mechanism for reporting healthy state to the registry.
 func (r *Registry) ReportHealthyState(instanceID string, _ string) error {
 	return r.client.Agent().PassTTL(instanceID, "")
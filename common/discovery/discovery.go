@@ -19,6 +19,16 @@ type Registry interface {
 	ReportHealthyState(instanceID string, serviceName string) error
 }
 
+// Watcher is implemented by registries that can wait for the addresses of a
+// service to change instead of being asked again and again.
+type Watcher interface {
+	// WatchServiceAddresses returns the addresses of active instances of the
+	// service once they differ from the state at index, or after a while if
+	// nothing changes, along with the index of the state returned. An index
+	// of 0 returns at once. No instances give an empty list, not an error.
+	WatchServiceAddresses(ctx context.Context, serviceName string, index uint64) ([]string, uint64, error)
+}
+
 var ErrNotFound = errors.New("no service addresses found")
 
 func GenerateInstanceID(serviceName string) string {
@@ -0,0 +1,283 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/discovery"
+)
+
+// Strategies a Balancer can pick instances with.
+const (
+	Random        = "random"
+	RoundRobin    = "round_robin"
+	LeastRequests = "least_requests"
+	Weighted      = "weighted"
+)
+
+const (
+	defaultMaxFailures  = 5
+	defaultEjectionTime = 30 * time.Second
+)
+
+// BalancerConfig says how a Balancer spreads requests over the instances of
+// a service. Strategy is one of the strategies above; empty means random.
+// Weights gives the weighted strategy the weight of instances by address,
+// instances not listed weighing 1. An instance that fails MaxFailures
+// requests in a row is left out for EjectionTime; empty values mean 5 and
+// 30s.
+type BalancerConfig struct {
+	Strategy     string         `yaml:"strategy"`
+	Weights      map[string]int `yaml:"weights"`
+	MaxFailures  int            `yaml:"maxFailures"`
+	EjectionTime string         `yaml:"ejectionTime"`
+}
+
+// Balancer picks the instance of a service each request goes to. It is also
+// the http.RoundTripper for those requests, which is how it learns how many
+// are outstanding on an instance and which instances fail.
+type Balancer struct {
+	serviceName  string
+	registry     discovery.Registry
+	transport    http.RoundTripper
+	strategy     func(b *Balancer, addrs []string) string
+	weights      map[string]int
+	maxFailures  int
+	ejectionTime time.Duration
+
+	mu        sync.Mutex
+	next      int
+	instances map[string]*instance
+}
+
+type instance struct {
+	outstanding  int
+	failures     int
+	ejectedUntil time.Time
+	// current is the running weight of the smooth weighted round-robin.
+	current int
+}
+
+func NewBalancer(serviceName string, registry discovery.Registry, cfg BalancerConfig) (*Balancer, error) {
+	b := &Balancer{
+		serviceName:  serviceName,
+		registry:     registry,
+		transport:    http.DefaultTransport,
+		weights:      cfg.Weights,
+		maxFailures:  cfg.MaxFailures,
+		ejectionTime: defaultEjectionTime,
+		instances:    map[string]*instance{},
+	}
+
+	switch cfg.Strategy {
+	case "", Random:
+		b.strategy = (*Balancer).random
+	case RoundRobin:
+		b.strategy = (*Balancer).roundRobin
+	case LeastRequests:
+		b.strategy = (*Balancer).leastRequests
+	case Weighted:
+		b.strategy = (*Balancer).weighted
+	default:
+		return nil, fmt.Errorf("unknown balancing strategy %q", cfg.Strategy)
+	}
+
+	for addr, weight := range cfg.Weights {
+		if weight < 1 {
+			return nil, fmt.Errorf("weight of %s must be positive", addr)
+		}
+	}
+
+	if b.maxFailures < 1 {
+		b.maxFailures = defaultMaxFailures
+	}
+
+	if cfg.EjectionTime != "" {
+		d, err := time.ParseDuration(cfg.EjectionTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ejection time: %w", err)
+		}
+		b.ejectionTime = d
+	}
+
+	return b, nil
+}
+
+// Pick returns the address of the instance the next request should go to.
+// Ejected instances are skipped unless every instance is ejected, in which
+// case all of them are tried again rather than none.
+func (b *Balancer) Pick(ctx context.Context) (string, error) {
+	addrs, err := b.registry.ServiceAddresses(ctx, b.serviceName)
+	if err != nil && !errors.Is(err, discovery.ErrNotFound) {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("no addresses found for service %q", b.serviceName)
+	}
+
+	// Registries need not list the instances in the same order every time,
+	// which the round-robin strategies rely on.
+	addrs = slices.Clone(addrs)
+	slices.Sort(addrs)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.forget(addrs)
+
+	now := time.Now()
+	healthy := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if now.After(b.instance(addr).ejectedUntil) {
+			healthy = append(healthy, addr)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = addrs
+	}
+
+	return b.strategy(b, healthy), nil
+}
+
+// RoundTrip sends the request and records its outcome against the instance
+// it went to. Connection errors and 5xx responses count as failures; a
+// request the caller cancelled does not count either way. The request is
+// outstanding until its response body is closed.
+func (b *Balancer) RoundTrip(req *http.Request) (*http.Response, error) {
+	addr := req.URL.Host
+
+	b.mu.Lock()
+	b.instance(addr).outstanding++
+	b.mu.Unlock()
+
+	resp, err := b.transport.RoundTrip(req)
+	if err != nil {
+		cancelled := req.Context().Err() != nil
+		b.done(addr, !cancelled, cancelled)
+		return nil, err
+	}
+
+	resp.Body = &trackedBody{ReadCloser: resp.Body, done: func() {
+		b.done(addr, resp.StatusCode >= http.StatusInternalServerError, false)
+	}}
+
+	return resp, nil
+}
+
+// done ends a request to the instance, counting it as a failure or, unless
+// ignored, a success.
+func (b *Balancer) done(addr string, failed, ignored bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	inst := b.instance(addr)
+	inst.outstanding--
+
+	switch {
+	case failed:
+		inst.failures++
+		if inst.failures >= b.maxFailures {
+			inst.failures = 0
+			inst.ejectedUntil = time.Now().Add(b.ejectionTime)
+		}
+	case !ignored:
+		inst.failures = 0
+	}
+}
+
+// instance returns the state of the instance, creating it when it is new.
+// The caller must hold b.mu.
+func (b *Balancer) instance(addr string) *instance {
+	inst, ok := b.instances[addr]
+	if !ok {
+		inst = &instance{}
+		b.instances[addr] = inst
+	}
+	return inst
+}
+
+// forget drops the state of instances that have left the registry, once no
+// requests to them are outstanding. The caller must hold b.mu.
+func (b *Balancer) forget(addrs []string) {
+	current := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		current[addr] = true
+	}
+
+	for addr, inst := range b.instances {
+		if !current[addr] && inst.outstanding == 0 {
+			delete(b.instances, addr)
+		}
+	}
+}
+
+func (b *Balancer) random(addrs []string) string {
+	return addrs[rand.Intn(len(addrs))]
+}
+
+func (b *Balancer) roundRobin(addrs []string) string {
+	b.next++
+	return addrs[b.next%len(addrs)]
+}
+
+// leastRequests picks the instance with the fewest outstanding requests,
+// going round the instances to break ties.
+func (b *Balancer) leastRequests(addrs []string) string {
+	b.next++
+
+	best := ""
+	for i := range addrs {
+		addr := addrs[(b.next+i)%len(addrs)]
+		if best == "" || b.instances[addr].outstanding < b.instances[best].outstanding {
+			best = addr
+		}
+	}
+	return best
+}
+
+// weighted is the smooth weighted round-robin of nginx: every instance gains
+// its weight, the one with the most is picked and pays back the total. It
+// spreads the picks of a heavy instance out instead of sending them in a
+// row.
+func (b *Balancer) weighted(addrs []string) string {
+	total := 0
+	var best *instance
+	bestAddr := ""
+
+	for _, addr := range addrs {
+		weight := b.weights[addr]
+		if weight == 0 {
+			weight = 1
+		}
+		total += weight
+
+		inst := b.instances[addr]
+		inst.current += weight
+		if best == nil || inst.current > best.current {
+			best, bestAddr = inst, addr
+		}
+	}
+
+	best.current -= total
+	return bestAddr
+}
+
+// trackedBody calls done once, when the body is first closed.
+type trackedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (t *trackedBody) Close() error {
+	err := t.ReadCloser.Close()
+	t.once.Do(t.done)
+	return err
+}
@@ -0,0 +1,279 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/discovery/memory"
+)
+
+const testService = "catalog"
+
+// roundTripFunc answers requests without a network.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestBalancer returns a balancer over instances registered at addrs
+// whose requests are answered by transport.
+func newTestBalancer(t *testing.T, addrs []string, cfg BalancerConfig, transport http.RoundTripper) *Balancer {
+	t.Helper()
+
+	registry := memory.NewRegistry()
+	for _, addr := range addrs {
+		if err := registry.Register(context.Background(), addr, testService, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := NewBalancer(testService, registry, cfg)
+	if err != nil {
+		t.Fatalf("NewBalancer: %v", err)
+	}
+	b.transport = transport
+	return b
+}
+
+func picks(t *testing.T, b *Balancer, n int) []string {
+	t.Helper()
+
+	got := make([]string, n)
+	for i := range got {
+		addr, err := b.Pick(context.Background())
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		got[i] = addr
+	}
+	return got
+}
+
+// respond answers every request with the status.
+func respond(status int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+}
+
+// send makes a request to the instance through the balancer and returns the
+// response body, which the request is outstanding until it is closed.
+func send(t *testing.T, ctx context.Context, b *Balancer, addr string) io.Closer {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := b.RoundTrip(req)
+	if err != nil {
+		return io.NopCloser(nil)
+	}
+	return resp.Body
+}
+
+func TestBalancerSequence(t *testing.T) {
+	addrs := []string{"c:8080", "a:8080", "b:8080"}
+
+	tests := []struct {
+		name string
+		cfg  BalancerConfig
+		want []string
+	}{
+		{
+			name: "round robin",
+			cfg:  BalancerConfig{Strategy: RoundRobin},
+			want: []string{"b:8080", "c:8080", "a:8080", "b:8080", "c:8080", "a:8080"},
+		},
+		{
+			name: "least requests breaks ties round the instances",
+			cfg:  BalancerConfig{Strategy: LeastRequests},
+			want: []string{"b:8080", "c:8080", "a:8080", "b:8080", "c:8080", "a:8080"},
+		},
+		{
+			name: "weighted spreads out the heavy instance",
+			cfg:  BalancerConfig{Strategy: Weighted, Weights: map[string]int{"a:8080": 5}},
+			want: []string{
+				"a:8080", "a:8080", "b:8080", "a:8080", "c:8080", "a:8080", "a:8080",
+				"a:8080", "a:8080", "b:8080", "a:8080", "c:8080", "a:8080", "a:8080",
+			},
+		},
+		{
+			name: "weighted with equal weights",
+			cfg:  BalancerConfig{Strategy: Weighted, Weights: map[string]int{"a:8080": 2, "b:8080": 2, "c:8080": 2}},
+			want: []string{"a:8080", "b:8080", "c:8080", "a:8080", "b:8080", "c:8080"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBalancer(t, addrs, tt.cfg, respond(http.StatusOK))
+
+			if got := picks(t, b, len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Errorf("picks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBalancerLeastRequests(t *testing.T) {
+	addrs := []string{"a:8080", "b:8080", "c:8080"}
+
+	tests := []struct {
+		name        string
+		outstanding map[string]int
+		want        string
+	}{
+		{"one idle instance", map[string]int{"a:8080": 2, "c:8080": 1}, "b:8080"},
+		{"fewest outstanding", map[string]int{"a:8080": 3, "b:8080": 2, "c:8080": 4}, "b:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBalancer(t, addrs, BalancerConfig{Strategy: LeastRequests}, respond(http.StatusOK))
+
+			var bodies []io.Closer
+			for addr, n := range tt.outstanding {
+				for range n {
+					bodies = append(bodies, send(t, context.Background(), b, addr))
+				}
+			}
+
+			for range len(addrs) {
+				if got := picks(t, b, 1)[0]; got != tt.want {
+					t.Fatalf("Pick = %s, want %s", got, tt.want)
+				}
+			}
+
+			// Once the responses are read, the instances are even again and
+			// the picks go round them.
+			for _, body := range bodies {
+				body.Close()
+			}
+			got := picks(t, b, len(addrs))
+			slices.Sort(got)
+			if !slices.Equal(got, addrs) {
+				t.Errorf("picks after the requests ended = %v, want each of %v", got, addrs)
+			}
+		})
+	}
+}
+
+func TestBalancerEjection(t *testing.T) {
+	const (
+		failing = "a:8080"
+		healthy = "b:8080"
+	)
+
+	errRefused := errors.New("connection refused")
+
+	// An outcome is a response status, 0 for a transport error or -1 for a
+	// request the caller cancelled.
+	tests := []struct {
+		name     string
+		outcomes []int
+		ejected  bool
+	}{
+		{"failures in a row", []int{500, 503, 502}, true},
+		{"transport errors", []int{0, 0, 0}, true},
+		{"too few failures", []int{500, 500}, false},
+		{"success resets the failures", []int{500, 500, 200, 500, 500}, false},
+		{"client errors are not failures", []int{500, 500, 404, 500}, false},
+		{"cancelled requests do not count", []int{500, 500, -1}, false},
+		{"cancelled requests do not reset the failures", []int{500, 500, -1, 500}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status int
+			transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				switch status {
+				case 0:
+					return nil, errRefused
+				case -1:
+					return nil, req.Context().Err()
+				}
+				return respond(status).RoundTrip(req)
+			})
+
+			cfg := BalancerConfig{Strategy: RoundRobin, MaxFailures: 3, EjectionTime: "1h"}
+			b := newTestBalancer(t, []string{failing, healthy}, cfg, transport)
+
+			for _, status = range tt.outcomes {
+				ctx, cancel := context.WithCancel(context.Background())
+				if status == -1 {
+					cancel()
+				}
+				send(t, ctx, b, failing).Close()
+				cancel()
+			}
+
+			got := picks(t, b, 4)
+			if ejected := !slices.Contains(got, failing); ejected != tt.ejected {
+				t.Errorf("picks = %v, ejected = %v, want %v", got, ejected, tt.ejected)
+			}
+		})
+	}
+}
+
+func TestBalancerReadmission(t *testing.T) {
+	addrs := []string{"a:8080", "b:8080"}
+
+	cfg := BalancerConfig{Strategy: RoundRobin, MaxFailures: 1, EjectionTime: "50ms"}
+	b := newTestBalancer(t, addrs, cfg, respond(http.StatusInternalServerError))
+
+	send(t, context.Background(), b, "a:8080").Close()
+	if got := picks(t, b, 4); slices.Contains(got, "a:8080") {
+		t.Fatalf("picks with a:8080 ejected = %v", got)
+	}
+
+	// With every instance ejected, all of them are tried.
+	send(t, context.Background(), b, "b:8080").Close()
+	got := picks(t, b, 4)
+	if !slices.Contains(got, "a:8080") || !slices.Contains(got, "b:8080") {
+		t.Errorf("picks with every instance ejected = %v, want both", got)
+	}
+
+	// Once the ejection time is up, an instance is picked again.
+	b = newTestBalancer(t, addrs, cfg, respond(http.StatusInternalServerError))
+	send(t, context.Background(), b, "a:8080").Close()
+	time.Sleep(60 * time.Millisecond)
+	if got := picks(t, b, 4); !slices.Contains(got, "a:8080") {
+		t.Errorf("picks after the ejection time = %v, want a:8080 back", got)
+	}
+}
+
+func TestNewBalancerRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  BalancerConfig
+	}{
+		{"unknown strategy", BalancerConfig{Strategy: "fastest"}},
+		{"zero weight", BalancerConfig{Strategy: Weighted, Weights: map[string]int{"a:8080": 0}}},
+		{"invalid ejection time", BalancerConfig{EjectionTime: "30"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBalancer(testService, memory.NewRegistry(), tt.cfg); err == nil {
+				t.Error("NewBalancer accepted the config")
+			}
+		})
+	}
+}
+
+func TestPickNoInstances(t *testing.T) {
+	b := newTestBalancer(t, nil, BalancerConfig{}, respond(http.StatusOK))
+
+	if _, err := b.Pick(context.Background()); err == nil {
+		t.Error("Pick returned an instance of a service with none")
+	}
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/Maksim-Kot/Commons/discovery"
 )
 
+// ServiceAddr returns the address of a random instance of the service.
+//
+// Deprecated: use a Balancer, which picks instances with a choice of
+// strategies and leaves out the ones that fail.
 func ServiceAddr(ctx context.Context, serviceName string, registry discovery.Registry) (string, error) {
 	addrs, err := registry.ServiceAddresses(ctx, serviceName)
 	if err != nil {
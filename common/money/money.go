@@ -0,0 +1,284 @@
+// Package money holds amounts of money as whole minor units of a currency,
+// e.g. kopecks for BYN, so sums never drift the way float64 amounts do.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount   = errors.New("money: invalid amount")
+	ErrInvalidCurrency = errors.New("money: invalid currency")
+)
+
+// Money is an amount in minor units of Currency. The zero value is zero in
+// any currency: it can be added to an amount in any currency and takes that
+// currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns amount minor units of the currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse reads a non-negative decimal amount with at most two places, such as
+// "12", "12.5" or "12.50", in the currency.
+func Parse(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || len(frac) > 2 || (hasFrac && frac == "") {
+		return Money{}, ErrInvalidAmount
+	}
+
+	units, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil || units > (math.MaxInt64-99)/100 {
+		return Money{}, ErrInvalidAmount
+	}
+
+	var cents uint64
+	if frac != "" {
+		cents, err = strconv.ParseUint(frac, 10, 8)
+		if err != nil {
+			return Money{}, ErrInvalidAmount
+		}
+		if len(frac) == 1 {
+			cents *= 10
+		}
+	}
+
+	return New(int64(units*100+cents), currency), nil
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns m + o. Both must be in the same currency; adding amounts in
+// different currencies is a programming error and panics.
+func (m Money) Add(o Money) Money {
+	return New(m.Amount+o.Amount, m.currencyWith(o))
+}
+
+// Sub returns m - o, under the same rules as Add.
+func (m Money) Sub(o Money) Money {
+	return New(m.Amount-o.Amount, m.currencyWith(o))
+}
+
+// Cmp compares m and o, under the same rules as Add, and returns -1, 0 or +1.
+func (m Money) Cmp(o Money) int {
+	m.currencyWith(o)
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Min returns the smaller of m and o.
+func (m Money) Min(o Money) Money {
+	if m.Cmp(o) <= 0 {
+		return New(m.Amount, m.currencyWith(o))
+	}
+	return New(o.Amount, m.currencyWith(o))
+}
+
+// Mul returns m multiplied by n.
+func (m Money) Mul(n int64) Money {
+	return New(m.Amount*n, m.Currency)
+}
+
+// Percent returns rate percent of m, rounded to the nearest minor unit with
+// halves away from zero. The rate is taken as the decimal it prints as, so
+// 7.1 is exactly 7.1 percent rather than the float64 closest to it.
+func (m Money) Percent(rate float64) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		panic(fmt.Sprintf("money: invalid rate %v", rate))
+	}
+
+	num := new(big.Int).Mul(big.NewInt(m.Amount), r.Num())
+	den := new(big.Int).Mul(r.Denom(), big.NewInt(100))
+
+	return New(roundQuo(num, den), m.Currency)
+}
+
+// Share returns the part/whole share of m, rounded to the nearest minor unit
+// with halves away from zero. It is exact for any amount that fits in Money.
+func (m Money) Share(part, whole int64) Money {
+	if whole == 0 {
+		panic("money: share of a zero whole")
+	}
+
+	num := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(part))
+	return New(roundQuo(num, big.NewInt(whole)), m.Currency)
+}
+
+// Allocate splits m into parts in proportion to the weights. Every part but
+// the last is its Share of m and the last takes what is left, so the parts
+// always add up to m. The weights must add up to more than zero.
+func (m Money) Allocate(weights ...int64) []Money {
+	var whole int64
+	for _, w := range weights {
+		whole += w
+	}
+	if whole <= 0 {
+		panic("money: allocation with no weight")
+	}
+
+	parts := make([]Money, len(weights))
+	remaining := m
+	for i, w := range weights[:len(weights)-1] {
+		parts[i] = m.Share(w, whole)
+		remaining = remaining.Sub(parts[i])
+	}
+	parts[len(parts)-1] = remaining
+
+	return parts
+}
+
+// Convert converts m to another currency at rate units of it per unit of
+// m's currency, rounding to the nearest minor unit.
+func (m Money) Convert(rate float64, currency string) Money {
+	return New(int64(math.Round(float64(m.Amount)*rate)), currency)
+}
+
+// Decimal formats the amount as a decimal number with two places, e.g.
+// "12.50".
+func (m Money) Decimal() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	// Work in uint64 so the most negative amount does not overflow.
+	abs := uint64(amount)
+	if amount < 0 {
+		abs = -abs
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, abs/100, abs%100)
+}
+
+// String formats the amount with its currency code, e.g. "12.50 BYN".
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// UnmarshalJSON reads {"amount": 1250, "currency": "BYN"}. The currency code
+// is made upper case and must look like an ISO 4217 code unless it is empty.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	type plain Money
+
+	var v plain
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	v.Currency = strings.ToUpper(strings.TrimSpace(v.Currency))
+	if v.Currency != "" && !ValidCurrency(v.Currency) {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, v.Currency)
+	}
+
+	*m = Money(v)
+	return nil
+}
+
+// Value stores the amount in minor units. The currency goes in a column of
+// its own, written and scanned through the Currency field.
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+// Scan reads an amount in minor units from an integer column, leaving the
+// currency as it is.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		m.Amount = v
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case nil:
+		m.Amount = 0
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	amount, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	m.Amount = amount
+	return nil
+}
+
+// roundQuo returns num/den rounded to the nearest integer with halves away
+// from zero. It changes num and den.
+func roundQuo(num, den *big.Int) int64 {
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+
+	// (2*num + sign*den) / (2*den), truncated.
+	num.Lsh(num, 1)
+	if num.Sign() < 0 {
+		num.Sub(num, den)
+	} else {
+		num.Add(num, den)
+	}
+	num.Quo(num, den.Lsh(den, 1))
+
+	return num.Int64()
+}
+
+// currencyWith returns the currency of the result of an operation on m and
+// o. A zero amount without a currency takes the other's currency.
+func (m Money) currencyWith(o Money) string {
+	switch {
+	case m.Currency == o.Currency:
+		return m.Currency
+	case m.Currency == "" && m.Amount == 0:
+		return o.Currency
+	case o.Currency == "" && o.Amount == 0:
+		return m.Currency
+	default:
+		panic(fmt.Sprintf("money: currency mismatch: %s and %s", m.Currency, o.Currency))
+	}
+}
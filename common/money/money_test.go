@@ -0,0 +1,286 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+// roundHalfAway is the reference rounding the arithmetic is checked against:
+// num/den rounded to the nearest integer with halves away from zero, worked
+// out from the remainder rather than the way roundQuo does it.
+func roundHalfAway(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func TestAddSubRoundTrip(t *testing.T) {
+	f := func(a, b int64) bool {
+		// Halved so the sum cannot overflow.
+		m, o := New(a/2, "BYN"), New(b/2, "BYN")
+		return m.Add(o).Sub(o) == m && m.Sub(o).Add(o) == m
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddZeroTakesCurrency(t *testing.T) {
+	var zero Money
+	if got := zero.Add(New(150, "BYN")); got != New(150, "BYN") {
+		t.Errorf("zero + 1.50 BYN = %v", got)
+	}
+	if got := New(150, "BYN").Sub(zero); got != New(150, "BYN") {
+		t.Errorf("1.50 BYN - zero = %v", got)
+	}
+}
+
+func TestShareMatchesReference(t *testing.T) {
+	f := func(amount int64, part int32, whole int32) bool {
+		if whole == 0 {
+			return true
+		}
+		// Keep the share within int64.
+		amount /= 1 << 32
+
+		got := New(amount, "BYN").Share(int64(part), int64(whole))
+
+		num := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(part)))
+		want := roundHalfAway(num, big.NewInt(int64(whole)))
+		return big.NewInt(got.Amount).Cmp(want) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShareHalves(t *testing.T) {
+	tests := []struct {
+		amount, part, whole, want int64
+	}{
+		{1, 1, 2, 1},
+		{-1, 1, 2, -1},
+		{1, -1, 2, -1},
+		{1, 1, -2, -1},
+		{3, 1, 2, 2},
+		{5, 1, 4, 1},
+		{6, 1, 4, 2},
+		{100, 1, 3, 33},
+		{200, 1, 3, 67},
+	}
+
+	for _, tt := range tests {
+		got := New(tt.amount, "BYN").Share(tt.part, tt.whole)
+		if got.Amount != tt.want {
+			t.Errorf("%d * %d/%d = %d, want %d", tt.amount, tt.part, tt.whole, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestPercentMatchesReference(t *testing.T) {
+	// Rates are generated in hundredths of a percent, the precision tax and
+	// discount rates are written with.
+	f := func(amount int32, hundredths uint16) bool {
+		rate := float64(hundredths) / 100
+
+		got := New(int64(amount), "BYN").Percent(rate)
+
+		num := new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(int64(hundredths)))
+		want := roundHalfAway(num, big.NewInt(10000))
+		return big.NewInt(got.Amount).Cmp(want) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPercentHalves(t *testing.T) {
+	tests := []struct {
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{50, 1, 1},
+		{-50, 1, -1},
+		{1000, 7.25, 73},
+		{2000, 7.1, 142},
+		{5, 10, 1},
+		{4, 12.5, 1},
+		{12345, 20, 2469},
+		{12345, 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := New(tt.amount, "BYN").Percent(tt.rate)
+		if got.Amount != tt.want {
+			t.Errorf("%v%% of %d = %d, want %d", tt.rate, tt.amount, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestAllocateAddsUp(t *testing.T) {
+	f := func(amount int32, weights []uint16) bool {
+		var whole int64
+		ws := make([]int64, len(weights))
+		for i, w := range weights {
+			ws[i] = int64(w)
+			whole += int64(w)
+		}
+		if whole == 0 {
+			return true
+		}
+
+		m := New(int64(amount), "BYN")
+		parts := m.Allocate(ws...)
+		if len(parts) != len(ws) {
+			return false
+		}
+
+		sum := New(0, "BYN")
+		for i, part := range parts {
+			if i < len(parts)-1 && part != m.Share(ws[i], whole) {
+				return false
+			}
+			sum = sum.Add(part)
+		}
+		return sum == m
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseStringRoundTrip(t *testing.T) {
+	f := func(units uint32, cents uint8) bool {
+		s := fmt.Sprintf("%d.%02d", units, cents%100)
+
+		m, err := Parse(s, "BYN")
+		if err != nil {
+			return false
+		}
+		return m.Decimal() == s && m.String() == s+" BYN"
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"12", 1200, true},
+		{"12.5", 1250, true},
+		{"12.50", 1250, true},
+		{" 0.07 ", 7, true},
+		{"", 0, false},
+		{".5", 0, false},
+		{"12.", 0, false},
+		{"12.505", 0, false},
+		{"-1", 0, false},
+		{"1e3", 0, false},
+		{"92233720368547758.07", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in, "BYN")
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("Parse(%q): %v", tt.in, err)
+		case !tt.ok && err == nil:
+			t.Errorf("Parse(%q) = %v, want an error", tt.in, got)
+		case tt.ok && got != New(tt.want, "BYN"):
+			t.Errorf("Parse(%q) = %v, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScanValueRoundTrip(t *testing.T) {
+	f := func(amount int64) bool {
+		v, err := New(amount, "BYN").Value()
+		if err != nil {
+			return false
+		}
+
+		sources := []any{v, fmt.Sprint(v), []byte(fmt.Sprint(v))}
+		for _, src := range sources {
+			m := Money{Currency: "BYN"}
+			if err := m.Scan(src); err != nil || m != New(amount, "BYN") {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	m := New(100, "BYN")
+	if err := m.Scan(nil); err != nil || m.Amount != 0 {
+		t.Errorf("Scan(nil) = %v, %v", m, err)
+	}
+	if err := m.Scan("abc"); err == nil {
+		t.Error("Scan(\"abc\") did not fail")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	f := func(amount int64) bool {
+		want := New(amount, "BYN")
+
+		data, err := json.Marshal(want)
+		if err != nil {
+			return false
+		}
+
+		var got Money
+		return json.Unmarshal(data, &got) == nil && got == want
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount": 5, "currency": " byn "}`), &m); err != nil || m != New(5, "BYN") {
+		t.Errorf("lower-case currency gave %v, %v", m, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 5, "currency": "BYNN"}`), &m); err == nil {
+		t.Error("invalid currency was accepted")
+	}
+}
+
+func TestCurrencyMismatchPanics(t *testing.T) {
+	byn, usd := New(100, "BYN"), New(100, "USD")
+
+	ops := map[string]func(){
+		"Add": func() { byn.Add(usd) },
+		"Sub": func() { byn.Sub(usd) },
+		"Cmp": func() { byn.Cmp(usd) },
+		"Min": func() { byn.Min(usd) },
+	}
+
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s of BYN and USD did not panic", name)
+				}
+			}()
+			op()
+		})
+	}
+}
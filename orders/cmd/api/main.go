@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/Maksim-Kot/Commons/discovery/cached"
 	"github.com/Maksim-Kot/Commons/discovery/consul"
 	"github.com/Maksim-Kot/Tech-store-orders/config"
 	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/expiry"
+	cataloggateway "github.com/Maksim-Kot/Tech-store-orders/internal/gateway/catalog/http"
 	httphandler "github.com/Maksim-Kot/Tech-store-orders/internal/handler/http"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/payment/mock"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/pricing"
 	"github.com/Maksim-Kot/Tech-store-orders/internal/repository/postgre"
 	httpserver "github.com/Maksim-Kot/Tech-store-orders/internal/server/http"
 )
@@ -29,7 +38,46 @@ func main() {
 	defer repo.Close()
 	log.Printf("[server] database connection pool established")
 
-	ctrl := orders.New(repo)
+	var payments orders.PaymentProvider
+	switch cfg.Payment.Provider {
+	case "", "mock":
+		payments = mock.New()
+		log.Printf("[server] using the mock payment provider")
+	default:
+		log.Fatalf("unknown payment provider %q", cfg.Payment.Provider)
+	}
+
+	rulesPath := cfg.Pricing.Rules
+	if rulesPath == "" {
+		rulesPath = "pricing.yaml"
+	}
+
+	rules, err := pricing.Load(rulesPath)
+	if err != nil {
+		log.Fatalf("failed to load pricing rules: %v", err)
+	}
+
+	if !cfg.Expiry.Disabled {
+		addresses := cached.New(registry)
+		defer addresses.Close()
+
+		catalog, err := cataloggateway.New(addresses, cfg.Gateways.Catalog)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		scheduler, err := expiry.New(repo, catalog, cfg.Expiry)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go scheduler.Run(ctx)
+	}
+
+	ctrl := orders.New(repo, payments, rules)
 	h := httphandler.New(ctrl, cfg.Api)
 
 	srv := httpserver.New(h, cfg.Api, registry)
@@ -3,12 +3,18 @@ package config
 import (
 	"os"
 
+	"github.com/Maksim-Kot/Commons/httputil"
+
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Api      APIConfig      `yaml:"api"`
 	Database DatabaseConfig `yaml:"database"`
+	Payment  PaymentConfig  `yaml:"payment"`
+	Pricing  PricingConfig  `yaml:"pricing"`
+	Expiry   ExpiryConfig   `yaml:"expiry"`
+	Gateways GatewaysConfig `yaml:"gateways"`
 }
 
 type APIConfig struct {
@@ -25,6 +31,33 @@ type DatabaseConfig struct {
 	MaxIdleTime  string `yaml:"maxIdleTime"`
 }
 
+type PaymentConfig struct {
+	Provider string `yaml:"provider"`
+}
+
+// PricingConfig points to the shipping and tax rules; an empty path means
+// pricing.yaml in the working directory.
+type PricingConfig struct {
+	Rules string `yaml:"rules"`
+}
+
+// ExpiryConfig controls the cancelling of orders that are never paid for.
+// Every Interval, orders still awaiting payment MaxAge after they were placed
+// are cancelled and their stock goes back to the catalog. The same run gives
+// back the stock of orders staff cancelled. Empty values mean 24h and 5m;
+// Disabled turns it off, and with it the return of stock.
+type ExpiryConfig struct {
+	Disabled bool   `yaml:"disabled"`
+	MaxAge   string `yaml:"maxAge"`
+	Interval string `yaml:"interval"`
+}
+
+// GatewaysConfig says how requests to the catalog service are balanced over
+// its instances.
+type GatewaysConfig struct {
+	Catalog httputil.BalancerConfig `yaml:"catalog"`
+}
+
 func New(path string) (*Config, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,36 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// CreateComment adds a staff comment to the order. The comment is expected
+// to be valid.
+func (c *Controller) CreateComment(ctx context.Context, comment *model.Comment) error {
+	if _, err := c.OrderByID(ctx, comment.OrderID); err != nil {
+		return err
+	}
+
+	err := c.repo.CreateComment(ctx, comment)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CommentsByOrderID returns the staff comments on the order, oldest first.
+func (c *Controller) CommentsByOrderID(ctx context.Context, orderID int64) ([]*model.Comment, error) {
+	if _, err := c.OrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return c.repo.CommentsByOrderID(ctx, orderID)
+}
@@ -3,42 +3,177 @@ package orders
 import (
 	"context"
 	"errors"
+	"strings"
 
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/pricing"
 	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
 	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
 )
 
 var (
-	ErrNotFound   = errors.New("order not found")
-	ErrNotCreated = errors.New("order not created")
+	ErrNotFound       = errors.New("order not found")
+	ErrNotCreated     = errors.New("order not created")
+	ErrBadStatus      = errors.New("invalid order status")
+	ErrStatusChange   = errors.New("the order cannot be moved to this status from its current one")
+	ErrInvalidDisplay = errors.New("must be a three-letter ISO 4217 code with a positive rate")
+	ErrItemCurrency   = errors.New("prices must be in the settlement currency")
 )
 
 type ordersRepository interface {
-	CreateOrder(ctx context.Context, userID int64, price float64, items []model.Item) (int64, error)
+	CreateOrder(ctx context.Context, order *model.Order) (int64, error)
 	OrderByID(ctx context.Context, id int64) (*model.Order, error)
-	OrdersByUserID(ctx context.Context, id int64) ([]*model.Order, error)
+	Orders(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error)
+	TransitionOrderStatus(ctx context.Context, id int64, from, to string) error
+	CancelOrder(ctx context.Context, id int64, from string) error
+	CreatePayment(ctx context.Context, payment *model.Payment) error
+	PaymentsByOrderID(ctx context.Context, orderID int64) ([]*model.Payment, error)
+	CreateReturn(ctx context.Context, ret *model.Return) error
+	ReturnByID(ctx context.Context, id int64) (*model.Return, error)
+	ReturnsByOrderID(ctx context.Context, orderID int64) ([]*model.Return, error)
+	Returns(ctx context.Context, status string, limit int) ([]*model.Return, error)
+	TransitionReturn(ctx context.Context, id int64, from, to, note string) error
+	SetReturnRefund(ctx context.Context, id int64, amount money.Money, paymentID *int64) error
+	MarkReturnRestocked(ctx context.Context, id int64) error
+	UnmarkReturnRestocked(ctx context.Context, id int64) error
+	CreateCoupon(ctx context.Context, coupon *model.Coupon) error
+	CouponByID(ctx context.Context, id int64) (*model.Coupon, error)
+	CouponByCode(ctx context.Context, code string) (*model.Coupon, error)
+	Coupons(ctx context.Context, limit int) ([]*model.Coupon, error)
+	SetCouponActive(ctx context.Context, id int64, active bool) error
+	CouponUserUses(ctx context.Context, couponID, userID int64) (int, error)
+	CreateInvoice(ctx context.Context, invoice *model.Invoice) error
+	InvoiceByOrderID(ctx context.Context, orderID int64) (*model.Invoice, error)
+	SalesByPeriod(ctx context.Context, rng model.ReportRange, interval string) ([]*model.SalesPeriod, error)
+	TopProducts(ctx context.Context, rng model.ReportRange, by string, limit int) ([]*model.ProductSales, error)
+	SalesByStatus(ctx context.Context, rng model.ReportRange) ([]*model.StatusSales, error)
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	CommentsByOrderID(ctx context.Context, orderID int64) ([]*model.Comment, error)
+	CreateShipment(ctx context.Context, shipment *model.Shipment) (string, error)
+	ShipmentsByOrderID(ctx context.Context, orderID int64) ([]*model.Shipment, error)
+	EraseUser(ctx context.Context, userID int64) (int64, error)
 }
 
 type Controller struct {
-	repo ordersRepository
+	repo     ordersRepository
+	payments PaymentProvider
+	pricing  *pricing.Rules
 }
 
-func New(repo ordersRepository) *Controller {
-	return &Controller{repo}
+func New(repo ordersRepository, payments PaymentProvider, rules *pricing.Rules) *Controller {
+	return &Controller{repo: repo, payments: payments, pricing: rules}
 }
 
-func (c *Controller) CreateOrder(ctx context.Context, userID int64, price float64, items []model.Item) (int64, error) {
-	id, err := c.repo.CreateOrder(ctx, userID, price, items)
+// CreateOrder places the order and returns its ID. The price is worked out
+// here from the lines, the coupon and the pricing rules for the address, in
+// the settlement currency; a non-empty coupon code must be valid. The
+// discount of every line is stored with it so returns refund what was
+// actually paid. The display currency and the notes are only recorded.
+func (c *Controller) CreateOrder(ctx context.Context, userID int64, items []model.Item, address *model.Address, coupon string, display model.Display, notes model.Notes) (int64, error) {
+	display, err := c.display(display)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.checkItems(items); err != nil {
+		return 0, err
+	}
+
+	order := &model.Order{
+		UserID:  userID,
+		Display: display,
+		Items:   items,
+		Address: address,
+		Notes:   notes,
+	}
+
+	if coupon != "" {
+		promotion, err := c.applyCoupon(ctx, coupon, userID, items)
+		if err != nil {
+			return 0, err
+		}
+		order.Promotion = promotion
+		order.Discount = promotion.Discount
+	}
+
+	order.Breakdown = c.pricing.Price(items, order.Discount, address)
+	order.Price = order.Total()
+
+	id, err := c.repo.CreateOrder(ctx, order)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotCreated) {
 			return 0, ErrNotCreated
 		}
-		return 0, err
+		return 0, couponError(err)
 	}
 
 	return id, nil
 }
 
+// Quote works out what the order would cost if it were placed now. A coupon
+// that cannot be used is reported in the quote rather than as an error.
+func (c *Controller) Quote(ctx context.Context, userID int64, items []model.Item, address *model.Address, coupon string) (*model.Quote, error) {
+	if err := c.checkItems(items); err != nil {
+		return nil, err
+	}
+
+	quote := &model.Quote{}
+
+	var discount money.Money
+	if coupon != "" {
+		quote.Coupon = &model.CouponQuote{Code: model.NormalizeCouponCode(coupon)}
+
+		promotion, err := c.applyCoupon(ctx, coupon, userID, items)
+		switch {
+		case err == nil:
+			quote.Coupon.Valid = true
+			quote.Coupon.Discount = promotion.Discount
+			discount = promotion.Discount
+		case IsCouponError(err):
+			quote.Coupon.Reason = err.Error()
+		default:
+			return nil, err
+		}
+	}
+
+	quote.Breakdown = c.pricing.Price(items, discount, address)
+
+	return quote, nil
+}
+
+// Currency returns the settlement currency orders are priced and paid in.
+func (c *Controller) Currency() string {
+	return c.pricing.Currency
+}
+
+// checkItems makes sure the lines are priced in the settlement currency and
+// clears their discounts: those are only ever worked out here, never taken
+// from the caller.
+func (c *Controller) checkItems(items []model.Item) error {
+	for i := range items {
+		if items[i].Price.Currency != c.pricing.Currency {
+			return ErrItemCurrency
+		}
+		items[i].Discount = money.New(0, c.pricing.Currency)
+	}
+	return nil
+}
+
+// display checks the display currency of a new order. An empty one means the
+// settlement currency.
+func (c *Controller) display(display model.Display) (model.Display, error) {
+	display.Currency = strings.ToUpper(strings.TrimSpace(display.Currency))
+
+	switch {
+	case display.Currency == "" || display.Currency == c.pricing.Currency:
+		return model.Display{Currency: c.pricing.Currency, Rate: 1}, nil
+	case !money.ValidCurrency(display.Currency) || display.Rate <= 0:
+		return model.Display{}, ErrInvalidDisplay
+	default:
+		return display, nil
+	}
+}
+
 func (c *Controller) OrderByID(ctx context.Context, id int64) (*model.Order, error) {
 	order, err := c.repo.OrderByID(ctx, id)
 	if err != nil {
@@ -51,14 +186,90 @@ func (c *Controller) OrderByID(ctx context.Context, id int64) (*model.Order, err
 	return order, nil
 }
 
-func (c *Controller) OrdersByUserID(ctx context.Context, id int64) ([]*model.Order, error) {
-	orders, err := c.repo.OrdersByUserID(ctx, id)
-	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, ErrNotFound
+// OrdersByUserID returns a page of the orders of the user that match the
+// filter, which is expected to be valid.
+func (c *Controller) OrdersByUserID(ctx context.Context, id int64, filter model.OrderFilter) (*model.OrderPage, error) {
+	filter.UserID = id
+	return c.Orders(ctx, filter)
+}
+
+// Orders returns a page of the orders of all users that match the filter.
+// The filter is expected to be valid; total bounds without a currency are in
+// the settlement currency.
+func (c *Controller) Orders(ctx context.Context, filter model.OrderFilter) (*model.OrderPage, error) {
+	limit := filter.Limit
+
+	for _, bound := range []*money.Money{filter.MinTotal, filter.MaxTotal} {
+		if bound != nil && bound.Currency == "" {
+			bound.Currency = c.pricing.Currency
 		}
+	}
+
+	// One more order than asked for tells whether there is a next page.
+	filter.Limit++
+	orders, err := c.repo.Orders(ctx, filter)
+	if err != nil {
 		return nil, err
 	}
 
-	return orders, nil
+	page := &model.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		page.NextCursor = model.CursorAfter(page.Orders[limit-1], filter.Sort).Encode()
+	}
+
+	return page, nil
+}
+
+// UpdateOrderStatus moves the order to the status, if model.StatusChanges
+// allows it from the current one. Cancelling an order that has been paid for
+// refunds whatever has not been refunded yet; the order is only cancelled
+// once the refund succeeds, and its stock is then queued for release to the
+// catalog.
+func (c *Controller) UpdateOrderStatus(ctx context.Context, id int64, status string) error {
+	if !model.ValidStatus(status) {
+		return ErrBadStatus
+	}
+
+	order, err := c.OrderByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !model.CanChangeStatus(order.Status, status) {
+		return ErrStatusChange
+	}
+
+	if status == model.StatusCancelled {
+		payments, err := c.Payments(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if capture, remaining := refundable(payments); remaining.Amount > 0 {
+			if _, err := c.refund(ctx, capture, remaining); err != nil {
+				return err
+			}
+		}
+	}
+
+	if status == model.StatusCancelled {
+		err = c.repo.CancelOrder(ctx, id, order.Status)
+	} else {
+		err = c.repo.TransitionOrderStatus(ctx, id, order.Status, status)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrNotFound
+		case errors.Is(err, repository.ErrBadStatus):
+			return ErrBadStatus
+		case errors.Is(err, repository.ErrEditConflict):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,195 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+var (
+	ErrCouponNotFound  = errors.New("coupon not found")
+	ErrDuplicateCoupon = errors.New("a coupon with this code already exists")
+)
+
+// Reasons a coupon is refused for an order. Their messages are meant to be
+// shown to the shopper.
+var (
+	ErrCouponUnknown       = errors.New("this coupon code does not exist")
+	ErrCouponInactive      = errors.New("this coupon is no longer available")
+	ErrCouponExpired       = errors.New("this coupon has expired")
+	ErrCouponMinBasket     = errors.New("the order total is below the coupon minimum")
+	ErrCouponNotApplicable = errors.New("this coupon does not apply to any product in the order")
+	ErrCouponUsedUp        = errors.New("this coupon has reached its usage limit")
+	ErrCouponUserUsed      = errors.New("you have already used this coupon")
+)
+
+var couponErrors = []error{
+	ErrCouponUnknown,
+	ErrCouponInactive,
+	ErrCouponExpired,
+	ErrCouponMinBasket,
+	ErrCouponNotApplicable,
+	ErrCouponUsedUp,
+	ErrCouponUserUsed,
+}
+
+// IsCouponError reports whether err is one of the reasons a coupon is
+// refused for an order.
+func IsCouponError(err error) bool {
+	for _, target := range couponErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// couponsListLimit caps the number of coupons returned by Coupons.
+const couponsListLimit = 100
+
+func (c *Controller) CreateCoupon(ctx context.Context, coupon *model.Coupon) error {
+	err := c.repo.CreateCoupon(ctx, coupon)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateCode) {
+			return ErrDuplicateCoupon
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) Coupon(ctx context.Context, id int64) (*model.Coupon, error) {
+	coupon, err := c.repo.CouponByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+// Coupons returns the most recently created coupons.
+func (c *Controller) Coupons(ctx context.Context) ([]*model.Coupon, error) {
+	return c.repo.Coupons(ctx, couponsListLimit)
+}
+
+// SetCouponActive enables or disables a coupon. Disabled coupons are refused
+// for new orders; orders already placed with them keep their discount.
+func (c *Controller) SetCouponActive(ctx context.Context, id int64, active bool) (*model.Coupon, error) {
+	err := c.repo.SetCouponActive(ctx, id, active)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+
+	return c.Coupon(ctx, id)
+}
+
+// applyCoupon checks that the coupon can be used by the user for the items
+// and spreads its discount over the lines in its scope. The usage limits are
+// checked here for a friendly answer and again when the order is stored.
+func (c *Controller) applyCoupon(ctx context.Context, code string, userID int64, items []model.Item) (*model.Promotion, error) {
+	coupon, err := c.repo.CouponByCode(ctx, model.NormalizeCouponCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCouponUnknown
+		}
+		return nil, err
+	}
+
+	minBasket := money.New(coupon.MinBasket, c.pricing.Currency)
+
+	switch {
+	case !coupon.Active:
+		return nil, ErrCouponInactive
+	case coupon.Expired(time.Now()):
+		return nil, ErrCouponExpired
+	case subtotal(items).Cmp(minBasket) < 0:
+		return nil, fmt.Errorf("%w of %s", ErrCouponMinBasket, minBasket)
+	case coupon.UsageLimit > 0 && coupon.Uses >= coupon.UsageLimit:
+		return nil, ErrCouponUsedUp
+	}
+
+	if coupon.PerUserLimit > 0 {
+		uses, err := c.repo.CouponUserUses(ctx, coupon.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if uses >= coupon.PerUserLimit {
+			return nil, ErrCouponUserUsed
+		}
+	}
+
+	discount, err := discountItems(coupon, items, c.pricing.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Promotion{CouponID: coupon.ID, Code: coupon.Code, Discount: discount}, nil
+}
+
+// discountItems sets the discount of every line in the coupon's scope and
+// returns the total. A fixed discount is shared between the lines in
+// proportion to their totals, the last line taking the rounding remainder.
+func discountItems(coupon *model.Coupon, items []model.Item, currency string) (money.Money, error) {
+	zero := money.New(0, currency)
+	eligible := zero
+
+	var applies []int
+	var weights []int64
+	for i := range items {
+		items[i].Discount = zero
+		if coupon.Applies(items[i]) {
+			eligible = eligible.Add(items[i].Total())
+			applies = append(applies, i)
+			weights = append(weights, items[i].Total().Amount)
+		}
+	}
+
+	if len(applies) == 0 || eligible.Amount <= 0 {
+		return zero, ErrCouponNotApplicable
+	}
+
+	var total money.Money
+	switch coupon.Kind {
+	case model.CouponPercent:
+		total = eligible.Share(coupon.Value, 100)
+	default:
+		total = money.New(coupon.Value, currency).Min(eligible)
+	}
+
+	for j, share := range total.Allocate(weights...) {
+		items[applies[j]].Discount = share
+	}
+
+	return total, nil
+}
+
+func subtotal(items []model.Item) money.Money {
+	var total money.Money
+	for _, item := range items {
+		total = total.Add(item.Total())
+	}
+	return total
+}
+
+func couponError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCouponUsedUp):
+		return ErrCouponUsedUp
+	case errors.Is(err, repository.ErrCouponUserUsed):
+		return ErrCouponUserUsed
+	default:
+		return err
+	}
+}
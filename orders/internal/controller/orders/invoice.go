@@ -0,0 +1,60 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+var ErrNotInvoiceable = errors.New("only paid orders can be invoiced")
+
+// Invoice returns the invoice of the order, issuing it the first time it is
+// asked for. Only orders with a captured payment are invoiced. The invoice
+// keeps a snapshot of the order as it was when issued.
+func (c *Controller) Invoice(ctx context.Context, orderID int64) (*model.Invoice, error) {
+	invoice, err := c.repo.InvoiceByOrderID(ctx, orderID)
+	switch {
+	case err == nil:
+		return invoice, nil
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	order, err := c.OrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := c.repo.PaymentsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if capture, _ := refundable(payments); capture == nil {
+		return nil, ErrNotInvoiceable
+	}
+
+	now := time.Now()
+	invoice = &model.Invoice{
+		Year:     now.Year(),
+		OrderID:  orderID,
+		IssuedAt: now,
+		Order:    *order,
+	}
+
+	err = c.repo.CreateInvoice(ctx, invoice)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvoiceExists):
+			// Issued by a concurrent request in the meantime.
+			return c.repo.InvoiceByOrderID(ctx, orderID)
+		default:
+			return nil, err
+		}
+	}
+
+	return invoice, nil
+}
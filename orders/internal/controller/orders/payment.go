@@ -0,0 +1,175 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/payment"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+var (
+	ErrNotPayable         = errors.New("order cannot be paid in its current status")
+	ErrPaymentDeclined    = errors.New("payment declined")
+	ErrPaymentUnavailable = errors.New("payment provider unavailable")
+	ErrNothingToRefund    = errors.New("refund exceeds the captured amount")
+)
+
+// PaymentProvider moves money for orders. Authorize reserves the amount on
+// the card, Capture takes an authorized amount and Refund returns part or all
+// of a captured amount. Each call returns the provider's reference for the
+// operation.
+type PaymentProvider interface {
+	Authorize(ctx context.Context, amount money.Money, card model.Card) (string, error)
+	Capture(ctx context.Context, authorization string, amount money.Money) (string, error)
+	Refund(ctx context.Context, capture string, amount money.Money) (string, error)
+}
+
+// Pay authorizes and captures the order total and marks the order as paid.
+// Every provider call is recorded, and the last recorded attempt is returned
+// even when the payment fails, so callers can show the reason.
+func (c *Controller) Pay(ctx context.Context, id int64, card model.Card) (*model.Payment, error) {
+	order, err := c.OrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != model.StatusCreated {
+		return nil, ErrNotPayable
+	}
+
+	auth := &model.Payment{
+		OrderID:   id,
+		Operation: model.PaymentAuthorize,
+		Amount:    order.Price,
+		CardLast4: card.Last4(),
+	}
+
+	reference, authErr := c.payments.Authorize(ctx, order.Price, card)
+	if err := c.recordPayment(ctx, auth, reference, authErr); err != nil {
+		return nil, err
+	}
+	if authErr != nil {
+		return auth, paymentError(authErr)
+	}
+
+	capture := &model.Payment{
+		OrderID:   id,
+		Operation: model.PaymentCapture,
+		Amount:    order.Price,
+		CardLast4: auth.CardLast4,
+	}
+
+	reference, captureErr := c.payments.Capture(ctx, auth.Reference, order.Price)
+	if err := c.recordPayment(ctx, capture, reference, captureErr); err != nil {
+		return nil, err
+	}
+	if captureErr != nil {
+		return capture, paymentError(captureErr)
+	}
+
+	err = c.repo.TransitionOrderStatus(ctx, id, model.StatusCreated, model.StatusPaid)
+	if err != nil {
+		if !errors.Is(err, repository.ErrEditConflict) {
+			return nil, err
+		}
+
+		// The order was paid or cancelled while the card was being charged,
+		// so the money goes back.
+		if _, err := c.refund(ctx, capture, order.Price); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotPayable
+	}
+
+	return capture, nil
+}
+
+// Payments returns every payment attempt of the order, oldest first.
+func (c *Controller) Payments(ctx context.Context, id int64) ([]*model.Payment, error) {
+	if _, err := c.OrderByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return c.repo.PaymentsByOrderID(ctx, id)
+}
+
+// Refund returns the amount to the card the order was paid with. It does not
+// change the status of the order.
+func (c *Controller) Refund(ctx context.Context, id int64, amount money.Money) (*model.Payment, error) {
+	payments, err := c.Payments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	capture, remaining := refundable(payments)
+	if capture == nil || amount.Currency != remaining.Currency || amount.Amount <= 0 || amount.Cmp(remaining) > 0 {
+		return nil, ErrNothingToRefund
+	}
+
+	return c.refund(ctx, capture, amount)
+}
+
+func (c *Controller) refund(ctx context.Context, capture *model.Payment, amount money.Money) (*model.Payment, error) {
+	refund := &model.Payment{
+		OrderID:   capture.OrderID,
+		Operation: model.PaymentRefund,
+		Amount:    amount,
+		CardLast4: capture.CardLast4,
+	}
+
+	reference, refundErr := c.payments.Refund(ctx, capture.Reference, amount)
+	if err := c.recordPayment(ctx, refund, reference, refundErr); err != nil {
+		return nil, err
+	}
+	if refundErr != nil {
+		return refund, paymentError(refundErr)
+	}
+
+	return refund, nil
+}
+
+func (c *Controller) recordPayment(ctx context.Context, p *model.Payment, reference string, err error) error {
+	p.Reference = reference
+	p.Succeeded = err == nil
+	if err != nil {
+		p.Error = err.Error()
+	}
+
+	return c.repo.CreatePayment(ctx, p)
+}
+
+// refundable returns the successful capture of the order and how much of it
+// has not been refunded yet.
+func refundable(payments []*model.Payment) (*model.Payment, money.Money) {
+	var capture *model.Payment
+	var refunded money.Money
+
+	for _, p := range payments {
+		if !p.Succeeded {
+			continue
+		}
+
+		switch p.Operation {
+		case model.PaymentCapture:
+			capture = p
+		case model.PaymentRefund:
+			refunded = refunded.Add(p.Amount)
+		}
+	}
+
+	if capture == nil {
+		return nil, money.Money{}
+	}
+
+	return capture, capture.Amount.Sub(refunded)
+}
+
+func paymentError(err error) error {
+	if errors.Is(err, payment.ErrUnavailable) {
+		return ErrPaymentUnavailable
+	}
+	return ErrPaymentDeclined
+}
@@ -0,0 +1,69 @@
+package orders
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// SalesReport sums the sales of the range by interval. The range and the
+// interval are expected to be valid; a range without a currency is in the
+// settlement currency.
+func (c *Controller) SalesReport(ctx context.Context, rng model.ReportRange, interval string) (*model.SalesReport, error) {
+	rng = c.reportRange(rng)
+
+	sales, err := c.repo.SalesByPeriod(ctx, rng, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.SalesReport{
+		ReportRange: rng,
+		Interval:    interval,
+		Revenue:     money.New(0, rng.Currency),
+	}
+
+	// List every interval of the range, so charts show the days without
+	// sales as well.
+	for start := model.PeriodStart(rng.From, interval); start.Before(rng.To); start = model.NextPeriod(start, interval) {
+		period := &model.SalesPeriod{Start: start, Revenue: money.New(0, rng.Currency)}
+		if len(sales) > 0 && sales[0].Start.Equal(start) {
+			period, sales = sales[0], sales[1:]
+		}
+
+		period.AverageOrder = average(period.Revenue, period.Orders)
+		report.Orders += period.Orders
+		report.Revenue = report.Revenue.Add(period.Revenue)
+		report.Periods = append(report.Periods, period)
+	}
+	report.AverageOrder = average(report.Revenue, report.Orders)
+
+	return report, nil
+}
+
+// TopProducts returns the best selling products of the range, ranked by
+// quantity or revenue.
+func (c *Controller) TopProducts(ctx context.Context, rng model.ReportRange, by string, limit int) ([]*model.ProductSales, error) {
+	return c.repo.TopProducts(ctx, c.reportRange(rng), by, limit)
+}
+
+// SalesByStatus counts the orders of the range in every status.
+func (c *Controller) SalesByStatus(ctx context.Context, rng model.ReportRange) ([]*model.StatusSales, error) {
+	return c.repo.SalesByStatus(ctx, c.reportRange(rng))
+}
+
+func (c *Controller) reportRange(rng model.ReportRange) model.ReportRange {
+	if rng.Currency == "" {
+		rng.Currency = c.pricing.Currency
+	}
+	return rng
+}
+
+// average returns the average of n orders totalling total.
+func average(total money.Money, n int64) money.Money {
+	if n == 0 {
+		return money.New(0, total.Currency)
+	}
+	return total.Share(1, n)
+}
@@ -0,0 +1,193 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+var (
+	ErrReturnNotFound = errors.New("return not found")
+	ErrNotReturnable  = errors.New("only delivered orders can be returned")
+	ErrTooMany        = errors.New("return quantity exceeds the ordered quantity")
+	ErrEditConflict   = errors.New("unable to update the record due to an edit conflict, please try again")
+)
+
+// returnsListLimit caps the number of return requests returned by Returns.
+const returnsListLimit = 100
+
+// CreateReturn files a return request for units of one line of a delivered
+// order.
+func (c *Controller) CreateReturn(ctx context.Context, ret *model.Return) error {
+	order, err := c.OrderByID(ctx, ret.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != model.StatusDelivered {
+		return ErrNotReturnable
+	}
+
+	// Refunds are made in the currency the order was paid in.
+	ret.RefundAmount = money.New(0, order.Price.Currency)
+
+	err = c.repo.CreateReturn(ctx, ret)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrNotFound
+		case errors.Is(err, repository.ErrTooMany):
+			return ErrTooMany
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) Return(ctx context.Context, id int64) (*model.Return, error) {
+	ret, err := c.repo.ReturnByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (c *Controller) ReturnsByOrderID(ctx context.Context, orderID int64) ([]*model.Return, error) {
+	if _, err := c.OrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return c.repo.ReturnsByOrderID(ctx, orderID)
+}
+
+// Returns lists the most recent return requests of all orders. An empty
+// status lists requests in every status.
+func (c *Controller) Returns(ctx context.Context, status string) ([]*model.Return, error) {
+	return c.repo.Returns(ctx, status, returnsListLimit)
+}
+
+// ApproveReturn accepts the return and refunds the returned units to the
+// order's payment. The return is claimed before the refund is issued, so two
+// staff members approving at once cannot refund twice; if the refund fails
+// the return goes back to requested.
+func (c *Controller) ApproveReturn(ctx context.Context, id int64, note string) (*model.Return, error) {
+	ret, err := c.Return(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := c.OrderByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.transitionReturn(ctx, id, model.ReturnRequested, model.ReturnApproved, note)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := c.repo.PaymentsByOrderID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, c.reopenReturn(ctx, id, err)
+	}
+
+	amount := money.New(0, order.Price.Currency)
+	for _, item := range order.Items {
+		if item.ItemID == ret.ItemID {
+			paid := item.Total().Sub(item.Discount)
+			paid = paid.Add(paid.Percent(order.TaxRate))
+			amount = paid.Share(int64(ret.Quantity), int64(item.Quantity))
+		}
+	}
+
+	capture, remaining := refundable(payments)
+	amount = amount.Min(remaining)
+
+	var paymentID *int64
+	if amount.Amount > 0 {
+		refund, err := c.refund(ctx, capture, amount)
+		if err != nil {
+			return nil, c.reopenReturn(ctx, id, err)
+		}
+		paymentID = &refund.ID
+	}
+
+	err = c.repo.SetReturnRefund(ctx, id, amount, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Return(ctx, id)
+}
+
+func (c *Controller) RejectReturn(ctx context.Context, id int64, note string) (*model.Return, error) {
+	err := c.transitionReturn(ctx, id, model.ReturnRequested, model.ReturnRejected, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Return(ctx, id)
+}
+
+// MarkReturnRestocked records that the units of an approved return are put
+// back into the catalog stock. It is called before the stock is increased,
+// so that only one caller gets to increase it.
+func (c *Controller) MarkReturnRestocked(ctx context.Context, id int64) (*model.Return, error) {
+	return c.markReturnRestocked(ctx, id, c.repo.MarkReturnRestocked)
+}
+
+// UnmarkReturnRestocked undoes MarkReturnRestocked when increasing the stock
+// failed.
+func (c *Controller) UnmarkReturnRestocked(ctx context.Context, id int64) (*model.Return, error) {
+	return c.markReturnRestocked(ctx, id, c.repo.UnmarkReturnRestocked)
+}
+
+func (c *Controller) markReturnRestocked(ctx context.Context, id int64, mark func(ctx context.Context, id int64) error) (*model.Return, error) {
+	err := mark(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrReturnNotFound
+		case errors.Is(err, repository.ErrEditConflict):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	return c.Return(ctx, id)
+}
+
+func (c *Controller) transitionReturn(ctx context.Context, id int64, from, to, note string) error {
+	err := c.repo.TransitionReturn(ctx, id, from, to, note)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrReturnNotFound
+		case errors.Is(err, repository.ErrEditConflict):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reopenReturn puts an approved return back to requested after its refund
+// failed and returns the refund error.
+func (c *Controller) reopenReturn(ctx context.Context, id int64, cause error) error {
+	if err := c.repo.TransitionReturn(ctx, id, model.ReturnApproved, model.ReturnRequested, ""); err != nil {
+		return errors.Join(cause, err)
+	}
+	return cause
+}
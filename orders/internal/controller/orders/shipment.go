@@ -0,0 +1,44 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+var (
+	ErrNotShippable = errors.New("only paid orders that have not been shipped in full can be shipped")
+	ErrOverShipped  = errors.New("shipment quantity exceeds the quantity left to ship")
+)
+
+// CreateShipment records a shipment of some or all of the units left to ship
+// of the order, which moves it to partially shipped or shipped. The new
+// status is returned. The shipment is expected to be valid.
+func (c *Controller) CreateShipment(ctx context.Context, shipment *model.Shipment) (string, error) {
+	status, err := c.repo.CreateShipment(ctx, shipment)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return "", ErrNotFound
+		case errors.Is(err, repository.ErrNotShippable):
+			return "", ErrNotShippable
+		case errors.Is(err, repository.ErrTooMany):
+			return "", ErrOverShipped
+		default:
+			return "", err
+		}
+	}
+
+	return status, nil
+}
+
+// ShipmentsByOrderID returns the shipments of the order, oldest first.
+func (c *Controller) ShipmentsByOrderID(ctx context.Context, orderID int64) ([]*model.Shipment, error) {
+	if _, err := c.OrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return c.repo.ShipmentsByOrderID(ctx, orderID)
+}
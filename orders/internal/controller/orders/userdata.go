@@ -0,0 +1,64 @@
+package orders
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// UserData collects everything held about the user, oldest order first. It
+// is meant for data access requests, which are rare, so the payments,
+// returns and shipments are read order by order.
+func (c *Controller) UserData(ctx context.Context, userID int64) (*model.UserData, error) {
+	data := &model.UserData{
+		UserID:    userID,
+		Orders:    []*model.Order{},
+		Payments:  []*model.Payment{},
+		Returns:   []*model.Return{},
+		Shipments: []*model.Shipment{},
+	}
+
+	filter := model.OrderFilter{UserID: userID, Sort: model.SortOldest, Limit: model.MaxOrderPageSize}
+
+	for {
+		orders, err := c.repo.Orders(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		data.Orders = append(data.Orders, orders...)
+
+		if len(orders) < filter.Limit {
+			break
+		}
+		filter.After = model.CursorAfter(orders[len(orders)-1], filter.Sort)
+	}
+
+	for _, order := range data.Orders {
+		payments, err := c.repo.PaymentsByOrderID(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.Payments = append(data.Payments, payments...)
+
+		returns, err := c.repo.ReturnsByOrderID(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.Returns = append(data.Returns, returns...)
+
+		shipments, err := c.repo.ShipmentsByOrderID(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.Shipments = append(data.Shipments, shipments...)
+	}
+
+	return data, nil
+}
+
+// EraseUser anonymises the orders of the user and returns how many were
+// affected. The orders themselves are kept: their amounts are needed for
+// the books, and invoices must stay as they were issued.
+func (c *Controller) EraseUser(ctx context.Context, userID int64) (int64, error) {
+	return c.repo.EraseUser(ctx, userID)
+}
@@ -0,0 +1,165 @@
+// Package expiry cancels orders that are never paid for and gives the stock
+// they hold back to the catalog. Every instance of the service runs the
+// scheduler; a Postgres advisory lock makes sure only one of them works at a
+// time.
+package expiry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/config"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/gateway"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const (
+	// lockKey is the advisory lock the scheduler runs under, "EXPIRY" in
+	// ASCII.
+	lockKey int64 = 0x455850495259
+
+	batchSize = 100
+
+	defaultMaxAge   = 24 * time.Hour
+	defaultInterval = 5 * time.Minute
+)
+
+type repository interface {
+	TryLock(ctx context.Context, key int64) (release func() error, acquired bool, err error)
+	ExpireOrders(ctx context.Context, placedBefore time.Time, limit int) ([]int64, error)
+	PendingStockReleases(ctx context.Context, limit int) ([]*model.StockRelease, error)
+	MarkStockReleased(ctx context.Context, id int64) error
+}
+
+type catalog interface {
+	IncreaseProductQuantity(ctx context.Context, id int64, amount int32) error
+}
+
+type Scheduler struct {
+	repo     repository
+	catalog  catalog
+	maxAge   time.Duration
+	interval time.Duration
+}
+
+func New(repo repository, catalog catalog, cfg config.ExpiryConfig) (*Scheduler, error) {
+	maxAge, err := duration(cfg.MaxAge, defaultMaxAge)
+	if err != nil {
+		return nil, fmt.Errorf("expiry: max age: %w", err)
+	}
+
+	interval, err := duration(cfg.Interval, defaultInterval)
+	if err != nil {
+		return nil, fmt.Errorf("expiry: interval: %w", err)
+	}
+
+	return &Scheduler{
+		repo:     repo,
+		catalog:  catalog,
+		maxAge:   maxAge,
+		interval: interval,
+	}, nil
+}
+
+// Run runs the scheduler every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Printf("[expiry] cancelling orders unpaid for %s, every %s", s.maxAge, s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[expiry] %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce cancels the orders awaiting payment for longer than the maximum
+// age, then gives the stock of cancelled orders back to the catalog. It does
+// nothing while another instance holds the lock.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	release, acquired, err := s.repo.TryLock(ctx, lockKey)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := release(); err != nil {
+			log.Printf("[expiry] failed to release the lock: %v", err)
+		}
+	}()
+
+	placedBefore := time.Now().Add(-s.maxAge)
+
+	for {
+		ids, err := s.repo.ExpireOrders(ctx, placedBefore, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			log.Printf("[expiry] cancelled unpaid orders %v", ids)
+		}
+		if len(ids) < batchSize {
+			break
+		}
+	}
+
+	return s.releaseStock(ctx)
+}
+
+// releaseStock gives the queued stock back to the catalog. A release that
+// fails stays queued for the next run.
+func (s *Scheduler) releaseStock(ctx context.Context) error {
+	for {
+		releases, err := s.repo.PendingStockReleases(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, release := range releases {
+			err := s.catalog.IncreaseProductQuantity(ctx, release.ItemID, release.Quantity)
+			if err != nil {
+				if !errors.Is(err, gateway.ErrNotFound) {
+					return fmt.Errorf("releasing stock of order %d: %w", release.OrderID, err)
+				}
+				log.Printf("[expiry] product %d of order %d no longer exists, %d units not restocked", release.ItemID, release.OrderID, release.Quantity)
+			}
+
+			if err := s.repo.MarkStockReleased(ctx, release.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(releases) < batchSize {
+			return nil
+		}
+	}
+}
+
+func duration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive, got %s", value)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Maksim-Kot/Commons/discovery"
+	"github.com/Maksim-Kot/Commons/httputil"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/gateway"
+)
+
+const (
+	serviceName = "catalog"
+
+	baseURL            = "http://%s/v1"
+	increaseProductURL = baseURL + "/product/%d/increase/%d"
+)
+
+type Gateway struct {
+	balancer *httputil.Balancer
+	client   *http.Client
+}
+
+// New creates a gateway that spreads its requests over the instances of the
+// service as cfg says.
+func New(registry discovery.Registry, cfg httputil.BalancerConfig) (*Gateway, error) {
+	balancer, err := httputil.NewBalancer(serviceName, registry, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gateway{balancer: balancer, client: &http.Client{Transport: balancer}}, nil
+}
+
+// IncreaseProductQuantity puts amount units of the product back in stock.
+func (g *Gateway) IncreaseProductQuantity(ctx context.Context, id int64, amount int32) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf(increaseProductURL, addr, id, amount)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("[gateway] POST %s (catalog service)", url)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return gateway.ErrNotFound
+		default:
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+package gateway
+
+import "errors"
+
+var ErrNotFound = errors.New("not found")
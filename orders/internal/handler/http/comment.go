@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// CreateCommentHandler adds an internal comment to the order. The caller is
+// trusted to have checked that the author is staff.
+func (h *Handler) CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		AuthorID int64  `json:"author_id"`
+		Author   string `json:"author"`
+		Body     string `json:"body"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	comment := &model.Comment{
+		OrderID:  id,
+		AuthorID: input.AuthorID,
+		Author:   strings.TrimSpace(input.Author),
+		Body:     strings.TrimSpace(input.Body),
+	}
+
+	if errs := comment.Validate(); len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = h.ctrl.CreateComment(ctx, comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusCreated, envelope{"comment": comment}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) CommentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	comments, err := h.ctrl.CommentsByOrderID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"comments": comments}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
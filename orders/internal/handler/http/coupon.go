@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (h *Handler) CreateCouponHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Code         string     `json:"code"`
+		Kind         string     `json:"kind"`
+		Value        int64      `json:"value"`
+		MinBasket    int64      `json:"min_basket"`
+		CategoryIDs  []int64    `json:"category_ids"`
+		ProductIDs   []int64    `json:"product_ids"`
+		ExpiresAt    *time.Time `json:"expires_at"`
+		UsageLimit   int        `json:"usage_limit"`
+		PerUserLimit int        `json:"per_user_limit"`
+	}
+
+	err := h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	coupon := &model.Coupon{
+		Code:         input.Code,
+		Kind:         input.Kind,
+		Value:        input.Value,
+		MinBasket:    input.MinBasket,
+		CategoryIDs:  input.CategoryIDs,
+		ProductIDs:   input.ProductIDs,
+		ExpiresAt:    input.ExpiresAt,
+		UsageLimit:   input.UsageLimit,
+		PerUserLimit: input.PerUserLimit,
+		Active:       true,
+	}
+
+	coupon.Normalize()
+	if errs := coupon.Validate(); len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = h.ctrl.CreateCoupon(ctx, coupon)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrDuplicateCoupon):
+			h.editConflictResponse(w, r, err)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusCreated, envelope{"coupon": coupon}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) CouponsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	coupons, err := h.ctrl.Coupons(ctx)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"coupons": coupons}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) CouponHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	coupon, err := h.ctrl.Coupon(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrCouponNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"coupon": coupon}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) UpdateCouponActiveHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Active *bool `json:"active"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Active == nil {
+		h.failedValidationResponse(w, r, map[string]string{"active": "must be provided"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	coupon, err := h.ctrl.SetCouponActive(ctx, id, *input.Active)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrCouponNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"coupon": coupon}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
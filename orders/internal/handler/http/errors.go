@@ -3,6 +3,8 @@ package http
 import (
 	"log"
 	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
 )
 
 func (h *Handler) logError(r *http.Request, err error) {
@@ -33,3 +35,23 @@ func (h *Handler) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
 	h.errorResponse(w, r, http.StatusBadRequest, err.Error())
 }
+
+func (h *Handler) failedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
+	h.errorResponse(w, r, http.StatusUnprocessableEntity, errors)
+}
+
+func (h *Handler) editConflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	h.errorResponse(w, r, http.StatusConflict, err.Error())
+}
+
+// paymentFailedResponse reports a failed payment together with the recorded
+// attempt, which carries the reason given by the payment provider.
+func (h *Handler) paymentFailedResponse(w http.ResponseWriter, r *http.Request, status int, err error, payment *model.Payment) {
+	env := envelope{"error": err.Error(), "payment": payment}
+
+	err = h.writeJSON(w, status, env, nil)
+	if err != nil {
+		h.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
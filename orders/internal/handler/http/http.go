@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"errors"
+	"maps"
 	"net/http"
 	"time"
 
@@ -38,9 +39,12 @@ func (h *Handler) HealthcheckHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		UserID int64        `json:"user_id"`
-		Price  float64      `json:"price"`
-		Items  []model.Item `json:"items"`
+		UserID  int64          `json:"user_id"`
+		Items   []model.Item   `json:"items"`
+		Address *model.Address `json:"address"`
+		Coupon  string         `json:"coupon"`
+		Display model.Display  `json:"display"`
+		model.Notes
 	}
 
 	err := h.readJSON(w, r, &input)
@@ -49,14 +53,35 @@ func (h *Handler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if input.Address == nil {
+		h.failedValidationResponse(w, r, map[string]string{"address": "must be provided"})
+		return
+	}
+
+	input.Address.Normalize()
+	input.Notes.Normalize()
+
+	errs := input.Address.Validate()
+	maps.Copy(errs, input.Notes.Validate())
+	if len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	id, err := h.ctrl.CreateOrder(ctx, input.UserID, input.Price, input.Items)
+	id, err := h.ctrl.CreateOrder(ctx, input.UserID, input.Items, input.Address, input.Coupon, input.Display, input.Notes)
 	if err != nil {
 		switch {
 		case errors.Is(err, orders.ErrNotCreated):
 			h.badRequestResponse(w, r, err)
+		case errors.Is(err, orders.ErrInvalidDisplay):
+			h.failedValidationResponse(w, r, map[string]string{"display": err.Error()})
+		case errors.Is(err, orders.ErrItemCurrency):
+			h.failedValidationResponse(w, r, map[string]string{"items": err.Error()})
+		case orders.IsCouponError(err):
+			h.failedValidationResponse(w, r, map[string]string{"coupon": err.Error()})
 		default:
 			h.ServerErrorResponse(w, r, err)
 		}
@@ -73,6 +98,54 @@ func (h *Handler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// QuoteOrderHandler prices the items as if the order were placed now. The
+// address picks the shipping and tax rates; without one the defaults apply.
+// A coupon that cannot be used still gives 200 with an invalid coupon quote.
+func (h *Handler) QuoteOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		UserID  int64          `json:"user_id"`
+		Items   []model.Item   `json:"items"`
+		Address *model.Address `json:"address"`
+		Coupon  string         `json:"coupon"`
+	}
+
+	err := h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(input.Items) == 0 {
+		h.failedValidationResponse(w, r, map[string]string{"items": "must not be empty"})
+		return
+	}
+
+	// Only the country and region of the address matter here, so an address
+	// the shopper is still filling in can be priced.
+	if input.Address != nil {
+		input.Address.Normalize()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	quote, err := h.ctrl.Quote(ctx, input.UserID, input.Items, input.Address, input.Coupon)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrItemCurrency):
+			h.failedValidationResponse(w, r, map[string]string{"items": err.Error()})
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"quote": quote}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
 func (h *Handler) OrderByIDHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := h.getID(r)
 	if err != nil || id < 1 {
@@ -100,28 +173,43 @@ func (h *Handler) OrderByIDHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *Handler) OrdersByUserIDHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateOrderStatusHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := h.getID(r)
 	if err != nil || id < 1 {
 		h.notFoundResponse(w, r)
 		return
 	}
 
+	var input struct {
+		Status string `json:"status"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	purchases, err := h.ctrl.OrdersByUserID(ctx, id)
+	err = h.ctrl.UpdateOrderStatus(ctx, id, input.Status)
 	if err != nil {
 		switch {
 		case errors.Is(err, orders.ErrNotFound):
 			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrBadStatus):
+			h.badRequestResponse(w, r, err)
+		case errors.Is(err, orders.ErrStatusChange), errors.Is(err, orders.ErrEditConflict),
+			errors.Is(err, orders.ErrPaymentDeclined), errors.Is(err, orders.ErrPaymentUnavailable):
+			h.editConflictResponse(w, r, err)
 		default:
 			h.ServerErrorResponse(w, r, err)
 		}
 		return
 	}
 
-	err = h.writeJSON(w, http.StatusOK, envelope{"orders": purchases}, nil)
+	err = h.writeJSON(w, http.StatusOK, envelope{"order": map[string]any{"id": id, "status": input.Status}}, nil)
 	if err != nil {
 		h.ServerErrorResponse(w, r, err)
 	}
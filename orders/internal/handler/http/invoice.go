@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+)
+
+func (h *Handler) InvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	invoice, err := h.ctrl.Invoice(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrNotInvoiceable):
+			h.editConflictResponse(w, r, err)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"invoice": invoice}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (h *Handler) PayOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Card model.Card `json:"card"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Card.Normalize()
+	if errs := input.Card.Validate(); len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payment, err := h.ctrl.Pay(ctx, id, input.Card)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrNotPayable):
+			h.editConflictResponse(w, r, err)
+		case errors.Is(err, orders.ErrPaymentDeclined):
+			h.paymentFailedResponse(w, r, http.StatusPaymentRequired, err, payment)
+		case errors.Is(err, orders.ErrPaymentUnavailable):
+			h.paymentFailedResponse(w, r, http.StatusServiceUnavailable, err, payment)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := envelope{
+		"payment": payment,
+		"order":   map[string]any{"id": id, "status": model.StatusPaid},
+	}
+
+	err = h.writeJSON(w, http.StatusCreated, env, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) PaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	payments, err := h.ctrl.Payments(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"payments": payments}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
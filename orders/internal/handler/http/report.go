@@ -0,0 +1,202 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// reportDays is the length of the range of a report without a from date.
+const reportDays = 30
+
+// SalesReportHandler reports revenue, order count and average order value
+// by day, week or month.
+func (h *Handler) SalesReportHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	errs := map[string]string{}
+
+	rng := readReportRange(qs, errs)
+
+	interval := qs.Get("interval")
+	switch interval {
+	case "":
+		interval = model.IntervalDay
+	case model.IntervalDay, model.IntervalWeek, model.IntervalMonth:
+	default:
+		errs["interval"] = "must be day, week or month"
+	}
+
+	if len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	report, err := h.ctrl.SalesReport(ctx, rng, interval)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if qs.Get("format") == "csv" {
+		records := [][]string{{"period_start", "orders", "revenue", "average_order", "currency"}}
+		for _, p := range report.Periods {
+			records = append(records, []string{
+				p.Start.Format(time.DateOnly),
+				strconv.FormatInt(p.Orders, 10),
+				p.Revenue.Decimal(),
+				p.AverageOrder.Decimal(),
+				p.Revenue.Currency,
+			})
+		}
+		h.writeCSV(w, r, "sales", records)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"report": report}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// TopProductsHandler reports the best selling products by quantity or
+// revenue.
+func (h *Handler) TopProductsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	errs := map[string]string{}
+
+	rng := readReportRange(qs, errs)
+
+	by := qs.Get("by")
+	switch by {
+	case "":
+		by = model.RankByQuantity
+	case model.RankByQuantity, model.RankByRevenue:
+	default:
+		errs["by"] = "must be quantity or revenue"
+	}
+
+	limit := readInt(qs, "limit", 10, errs)
+	if limit < 1 || limit > 100 {
+		errs["limit"] = "must be between 1 and 100"
+	}
+
+	if len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	products, err := h.ctrl.TopProducts(ctx, rng, by, int(limit))
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if qs.Get("format") == "csv" {
+		records := [][]string{{"item_id", "name", "quantity", "revenue", "currency"}}
+		for _, p := range products {
+			records = append(records, []string{
+				strconv.FormatInt(p.ItemID, 10),
+				p.Name,
+				strconv.FormatInt(p.Quantity, 10),
+				p.Revenue.Decimal(),
+				p.Revenue.Currency,
+			})
+		}
+		h.writeCSV(w, r, "products", records)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"products": products}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// StatusReportHandler counts the orders in every status.
+func (h *Handler) StatusReportHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	errs := map[string]string{}
+
+	rng := readReportRange(qs, errs)
+	if len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	statuses, err := h.ctrl.SalesByStatus(ctx, rng)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if qs.Get("format") == "csv" {
+		records := [][]string{{"status", "orders", "total", "currency"}}
+		for _, s := range statuses {
+			records = append(records, []string{
+				s.Status,
+				strconv.FormatInt(s.Orders, 10),
+				s.Total.Decimal(),
+				s.Total.Currency,
+			})
+		}
+		h.writeCSV(w, r, "statuses", records)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"statuses": statuses}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// readReportRange reads the from, to and currency parameters of a report.
+// Without them the report covers the last 30 days, today included.
+func readReportRange(qs url.Values, errs map[string]string) model.ReportRange {
+	rng := model.ReportRange{Currency: strings.ToUpper(qs.Get("currency"))}
+
+	if to := readTime(qs, "to", true, errs); to != nil {
+		rng.To = *to
+	} else {
+		rng.To = model.PeriodStart(time.Now(), model.IntervalDay).AddDate(0, 0, 1)
+	}
+
+	if from := readTime(qs, "from", false, errs); from != nil {
+		rng.From = *from
+	} else {
+		rng.From = rng.To.AddDate(0, 0, -reportDays)
+	}
+
+	for key, msg := range rng.Validate() {
+		if _, exists := errs[key]; !exists {
+			errs[key] = msg
+		}
+	}
+
+	return rng
+}
+
+func (h *Handler) writeCSV(w http.ResponseWriter, r *http.Request, name string, records [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".csv"))
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		h.logError(r, err)
+	}
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const maxReturnTextLength = 500
+
+func (h *Handler) CreateReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ItemID   int64  `json:"item_id"`
+		Quantity int32  `json:"quantity"`
+		Reason   string `json:"reason"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Reason = strings.TrimSpace(input.Reason)
+
+	errs := map[string]string{}
+	if input.ItemID < 1 {
+		errs["item_id"] = "must be provided"
+	}
+	if input.Quantity < 1 {
+		errs["quantity"] = "must be greater than zero"
+	}
+	if input.Reason == "" {
+		errs["reason"] = "must be provided"
+	} else if utf8.RuneCountInString(input.Reason) > maxReturnTextLength {
+		errs["reason"] = "must not be more than 500 characters long"
+	}
+	if len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ret := &model.Return{
+		OrderID:  id,
+		ItemID:   input.ItemID,
+		Quantity: input.Quantity,
+		Reason:   input.Reason,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = h.ctrl.CreateReturn(ctx, ret)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrNotReturnable):
+			h.editConflictResponse(w, r, err)
+		case errors.Is(err, orders.ErrTooMany):
+			h.failedValidationResponse(w, r, map[string]string{"quantity": err.Error()})
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusCreated, envelope{"return": ret}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) OrderReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	returns, err := h.ctrl.ReturnsByOrderID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"returns": returns}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) ReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	switch status {
+	case "", model.ReturnRequested, model.ReturnApproved, model.ReturnRejected:
+	default:
+		h.failedValidationResponse(w, r, map[string]string{"status": "invalid return status"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	returns, err := h.ctrl.Returns(ctx, status)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"returns": returns}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) ReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ret, err := h.ctrl.Return(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrReturnNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"return": ret}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) ApproveReturnHandler(w http.ResponseWriter, r *http.Request) {
+	h.resolveReturn(w, r, h.ctrl.ApproveReturn)
+}
+
+func (h *Handler) RejectReturnHandler(w http.ResponseWriter, r *http.Request) {
+	h.resolveReturn(w, r, h.ctrl.RejectReturn)
+}
+
+func (h *Handler) resolveReturn(w http.ResponseWriter, r *http.Request, resolve func(context.Context, int64, string) (*model.Return, error)) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Note string `json:"note"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Note = strings.TrimSpace(input.Note)
+	if utf8.RuneCountInString(input.Note) > maxReturnTextLength {
+		h.failedValidationResponse(w, r, map[string]string{"note": "must not be more than 500 characters long"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret, err := resolve(ctx, id, input.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrReturnNotFound), errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrEditConflict),
+			errors.Is(err, orders.ErrPaymentDeclined),
+			errors.Is(err, orders.ErrPaymentUnavailable):
+			h.editConflictResponse(w, r, err)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"return": ret}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// RestockReturnHandler marks the return as restocked on PUT and takes the
+// mark back on DELETE.
+func (h *Handler) RestockReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	mark := h.ctrl.MarkReturnRestocked
+	if r.Method == http.MethodDelete {
+		mark = h.ctrl.UnmarkReturnRestocked
+	}
+
+	ret, err := mark(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrReturnNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrEditConflict):
+			h.editConflictResponse(w, r, err)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"return": ret}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// OrdersHandler lists the orders of all users for staff. The query selects
+// them by status, user, product, date range (from, to) and total (min_total,
+// max_total in currency), sorts them and pages through them with the
+// next_cursor of the previous page.
+func (h *Handler) OrdersHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	errs := map[string]string{}
+
+	filter := model.OrderFilter{
+		Status:        qs.Get("status"),
+		UserID:        readInt(qs, "user_id", 0, errs),
+		ProductID:     readInt(qs, "product_id", 0, errs),
+		CreatedAfter:  readTime(qs, "from", false, errs),
+		CreatedBefore: readTime(qs, "to", true, errs),
+		MinTotal:      readMoney(qs, "min_total", errs),
+		MaxTotal:      readMoney(qs, "max_total", errs),
+		Sort:          model.SortNewest,
+		After:         readCursor(qs, errs),
+		Limit:         int(readInt(qs, "limit", model.MaxOrderPageSize, errs)),
+	}
+
+	if sort := qs.Get("sort"); sort != "" {
+		filter.Sort = sort
+	}
+
+	if h.validate(w, r, &filter, errs) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	page, err := h.ctrl.Orders(ctx, filter)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"orders": page.Orders, "next_cursor": page.NextCursor}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// historyPageSize is the default number of orders in a page of a user's
+// order history.
+const historyPageSize = 20
+
+// OrdersByUserIDHandler pages through the orders of a user, newest first,
+// optionally only those in one status.
+func (h *Handler) OrdersByUserIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	qs := r.URL.Query()
+	errs := map[string]string{}
+
+	filter := model.OrderFilter{
+		Status: qs.Get("status"),
+		Sort:   model.SortNewest,
+		After:  readCursor(qs, errs),
+		Limit:  int(readInt(qs, "limit", historyPageSize, errs)),
+	}
+
+	if h.validate(w, r, &filter, errs) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	page, err := h.ctrl.OrdersByUserID(ctx, id, filter)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"orders": page.Orders, "next_cursor": page.NextCursor}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// validate adds the errors of the filter to errs and, if there are any,
+// writes them. It reports whether it wrote a response.
+func (h *Handler) validate(w http.ResponseWriter, r *http.Request, filter *model.OrderFilter, errs map[string]string) bool {
+	for key, msg := range filter.Validate() {
+		if _, exists := errs[key]; !exists {
+			errs[key] = msg
+		}
+	}
+	if len(errs) == 0 {
+		return false
+	}
+
+	h.failedValidationResponse(w, r, errs)
+	return true
+}
+
+// readCursor returns the cursor of the query, if it has one.
+func readCursor(qs url.Values, errs map[string]string) *model.OrderCursor {
+	s := qs.Get("cursor")
+	if s == "" {
+		return nil
+	}
+
+	cursor, err := model.DecodeCursor(s)
+	if err != nil {
+		errs["cursor"] = err.Error()
+	}
+	return cursor
+}
+
+// readInt returns the integer query parameter, or def if it is missing.
+func readInt(qs url.Values, key string, def int64, errs map[string]string) int64 {
+	s := qs.Get(key)
+	if s == "" {
+		return def
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		errs[key] = "must be an integer value"
+		return def
+	}
+	return n
+}
+
+// readTime returns the time of the query parameter, an RFC 3339 timestamp
+// or a date. A date given as an end is the end of that day, so the range
+// includes it.
+func readTime(qs url.Values, key string, end bool, errs map[string]string) *time.Time {
+	s := qs.Get(key)
+	if s == "" {
+		return nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		errs[key] = "must be a date (2006-01-02) or an RFC 3339 time"
+		return nil
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t
+}
+
+// readMoney returns the amount of the query parameter in the currency
+// parameter, which may be empty.
+func readMoney(qs url.Values, key string, errs map[string]string) *money.Money {
+	s := qs.Get(key)
+	if s == "" {
+		return nil
+	}
+
+	currency := strings.ToUpper(qs.Get("currency"))
+	if currency != "" && !money.ValidCurrency(currency) {
+		errs["currency"] = "must be a three-letter ISO 4217 code"
+		return nil
+	}
+
+	m, err := money.Parse(s, currency)
+	if err != nil {
+		errs[key] = err.Error()
+		return nil
+	}
+	return &m
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/controller/orders"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// CreateShipmentHandler records a shipment of the order and responds with it
+// and the status the order is in now.
+func (h *Handler) CreateShipmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Carrier        string               `json:"carrier"`
+		TrackingNumber string               `json:"tracking_number"`
+		Lines          []model.ShipmentLine `json:"lines"`
+	}
+
+	err = h.readJSON(w, r, &input)
+	if err != nil {
+		h.badRequestResponse(w, r, err)
+		return
+	}
+
+	shipment := &model.Shipment{
+		OrderID:        id,
+		Carrier:        input.Carrier,
+		TrackingNumber: input.TrackingNumber,
+		Lines:          input.Lines,
+	}
+
+	shipment.Normalize()
+	if errs := shipment.Validate(); len(errs) > 0 {
+		h.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	status, err := h.ctrl.CreateShipment(ctx, shipment)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		case errors.Is(err, orders.ErrNotShippable):
+			h.editConflictResponse(w, r, err)
+		case errors.Is(err, orders.ErrOverShipped):
+			h.failedValidationResponse(w, r, map[string]string{"lines": err.Error()})
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := envelope{"shipment": shipment, "order": map[string]any{"id": id, "status": status}}
+
+	err = h.writeJSON(w, http.StatusCreated, env, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *Handler) ShipmentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	shipments, err := h.ctrl.ShipmentsByOrderID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, orders.ErrNotFound):
+			h.notFoundResponse(w, r)
+		default:
+			h.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"shipments": shipments}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
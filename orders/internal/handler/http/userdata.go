@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// userDataTimeout bounds the collection of a user's data, which reads all of
+// their orders.
+const userDataTimeout = 10 * time.Second
+
+// UserDataHandler responds with everything held about the user, for data
+// access requests. A user without orders gets empty lists.
+func (h *Handler) UserDataHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), userDataTimeout)
+	defer cancel()
+
+	data, err := h.ctrl.UserData(ctx, id)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"data": data}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
+
+// EraseUserHandler anonymises the orders of the user and responds with how
+// many there were.
+func (h *Handler) EraseUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.notFoundResponse(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), userDataTimeout)
+	defer cancel()
+
+	orders, err := h.ctrl.EraseUser(ctx, id)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = h.writeJSON(w, http.StatusOK, envelope{"user": map[string]int64{"id": id, "orders": orders}}, nil)
+	if err != nil {
+		h.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package mock implements a payment provider that runs in-process and never
+// moves money. The outcome of a payment is selected by the card number:
+//
+//	4242 4242 4242 4242  approved
+//	4000 0000 0000 0002  declined
+//	4000 0000 0000 9995  declined, insufficient funds
+//	4000 0000 0000 0069  declined, expired card
+//	4000 0000 0000 0119  provider unavailable on authorization
+//	4000 0000 0000 0341  authorized, capture fails
+//	4000 0000 0000 5126  approved, refunds fail
+//
+// Any other number that passes the Luhn check is approved.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/payment"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const (
+	CardApproved          = "4242424242424242"
+	CardDeclined          = "4000000000000002"
+	CardInsufficientFunds = "4000000000009995"
+	CardExpired           = "4000000000000069"
+	CardUnavailable       = "4000000000000119"
+	CardCaptureFails      = "4000000000000341"
+	CardRefundFails       = "4000000000005126"
+)
+
+type authorization struct {
+	card     string
+	amount   money.Money
+	captured string
+}
+
+type capture struct {
+	card     string
+	amount   money.Money
+	refunded money.Money
+}
+
+type Provider struct {
+	sync.Mutex
+	seq            int64
+	authorizations map[string]*authorization
+	captures       map[string]*capture
+}
+
+func New() *Provider {
+	return &Provider{
+		authorizations: map[string]*authorization{},
+		captures:       map[string]*capture{},
+	}
+}
+
+func (p *Provider) Authorize(_ context.Context, amount money.Money, card model.Card) (string, error) {
+	switch card.Number {
+	case CardDeclined:
+		return "", payment.ErrDeclined
+	case CardInsufficientFunds:
+		return "", payment.ErrInsufficientFunds
+	case CardExpired:
+		return "", payment.ErrExpiredCard
+	case CardUnavailable:
+		return "", payment.ErrUnavailable
+	}
+
+	if !luhn(card.Number) {
+		return "", payment.ErrInvalidCard
+	}
+
+	now := time.Now()
+	if card.ExpYear < now.Year() || (card.ExpYear == now.Year() && card.ExpMonth < int(now.Month())) {
+		return "", payment.ErrExpiredCard
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	ref := p.reference("auth")
+	p.authorizations[ref] = &authorization{card: card.Number, amount: amount}
+
+	return ref, nil
+}
+
+func (p *Provider) Capture(_ context.Context, authorization string, amount money.Money) (string, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	auth, ok := p.authorizations[authorization]
+	if !ok {
+		return "", payment.ErrUnknownReference
+	}
+
+	if auth.card == CardCaptureFails {
+		return "", payment.ErrUnavailable
+	}
+
+	if auth.captured != "" {
+		return auth.captured, nil
+	}
+
+	if amount.Currency != auth.amount.Currency {
+		return "", fmt.Errorf("%w: capture currency differs from the authorization", payment.ErrDeclined)
+	}
+	if amount.Cmp(auth.amount) > 0 {
+		return "", fmt.Errorf("%w: capture exceeds the authorized amount", payment.ErrDeclined)
+	}
+
+	ref := p.reference("capt")
+	auth.captured = ref
+	p.captures[ref] = &capture{card: auth.card, amount: amount}
+
+	return ref, nil
+}
+
+func (p *Provider) Refund(_ context.Context, captureRef string, amount money.Money) (string, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	capt, ok := p.captures[captureRef]
+	if !ok {
+		return "", payment.ErrUnknownReference
+	}
+
+	if capt.card == CardRefundFails {
+		return "", payment.ErrUnavailable
+	}
+
+	if amount.Currency != capt.amount.Currency {
+		return "", fmt.Errorf("%w: refund currency differs from the capture", payment.ErrDeclined)
+	}
+	if capt.refunded.Add(amount).Cmp(capt.amount) > 0 {
+		return "", fmt.Errorf("%w: refund exceeds the captured amount", payment.ErrDeclined)
+	}
+
+	capt.refunded = capt.refunded.Add(amount)
+
+	return p.reference("rfnd"), nil
+}
+
+// reference must be called with the lock held.
+func (p *Provider) reference(prefix string) string {
+	p.seq++
+	return fmt.Sprintf("mock_%s_%06d", prefix, p.seq)
+}
+
+func luhn(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
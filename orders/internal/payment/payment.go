@@ -0,0 +1,15 @@
+package payment
+
+import "errors"
+
+// Errors returned by payment providers. A declined payment is a normal
+// outcome and is reported to the customer; ErrUnavailable means the attempt
+// can be retried later.
+var (
+	ErrDeclined          = errors.New("payment declined")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrExpiredCard       = errors.New("card expired")
+	ErrInvalidCard       = errors.New("invalid card number")
+	ErrUnknownReference  = errors.New("unknown payment reference")
+	ErrUnavailable       = errors.New("payment provider unavailable")
+)
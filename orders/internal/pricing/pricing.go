@@ -0,0 +1,223 @@
+// Package pricing works out what an order costs: the subtotal of its lines,
+// the coupon discount, shipping and tax. The shipping and tax rules are
+// declared in a YAML file; see pricing.yaml next to base.yaml.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Shipping rate types.
+const (
+	// ShippingFlat charges Rate for every order.
+	ShippingFlat = "flat"
+	// ShippingWeight charges Rate plus PerKg for every kilogram shipped.
+	ShippingWeight = "weight"
+	// ShippingFreeOver charges Rate unless the discounted subtotal reaches
+	// Threshold.
+	ShippingFreeOver = "free_over"
+)
+
+// Rules are the shipping and tax rules. Currency is the settlement currency:
+// orders are priced and paid in it, and the amounts of the rules are in its
+// minor units. Countries are ISO codes as stored on delivery addresses;
+// countries without their own rule use the default.
+type Rules struct {
+	Currency string        `yaml:"currency"`
+	Shipping ShippingRules `yaml:"shipping"`
+	Tax      TaxRules      `yaml:"tax"`
+}
+
+type ShippingRules struct {
+	Default   ShippingRate            `yaml:"default"`
+	Countries map[string]ShippingRate `yaml:"countries"`
+}
+
+type ShippingRate struct {
+	Type      string `yaml:"type"`
+	Rate      int64  `yaml:"rate"`
+	PerKg     int64  `yaml:"per_kg"`
+	Threshold int64  `yaml:"threshold"`
+}
+
+// TaxRules hold tax rates in percent. A country rate can be overridden for
+// some of its regions.
+type TaxRules struct {
+	Default         float64            `yaml:"default"`
+	ShippingTaxable bool               `yaml:"shipping_taxable"`
+	Countries       map[string]TaxRate `yaml:"countries"`
+}
+
+type TaxRate struct {
+	Rate    float64            `yaml:"rate"`
+	Regions map[string]float64 `yaml:"regions"`
+}
+
+// Load reads the rules from a YAML file.
+func Load(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads the rules from YAML. Unknown keys are rejected so a typo does
+// not silently fall back to a default.
+func Parse(r io.Reader) (*Rules, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	var rules Rules
+	if err := dec.Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// Validate reports the first problem with the rules.
+func (r *Rules) Validate() error {
+	if !money.ValidCurrency(r.Currency) {
+		return errors.New("currency: must be a three-letter ISO 4217 code")
+	}
+
+	if err := r.Shipping.Default.validate(); err != nil {
+		return fmt.Errorf("shipping.default: %w", err)
+	}
+	for country, rate := range r.Shipping.Countries {
+		if err := rate.validate(); err != nil {
+			return fmt.Errorf("shipping.countries.%s: %w", country, err)
+		}
+	}
+
+	if !validPercent(r.Tax.Default) {
+		return errors.New("tax.default: must be between 0 and 100")
+	}
+	for country, rate := range r.Tax.Countries {
+		if !validPercent(rate.Rate) {
+			return fmt.Errorf("tax.countries.%s.rate: must be between 0 and 100", country)
+		}
+		for region, regionRate := range rate.Regions {
+			if region != strings.ToUpper(region) {
+				return fmt.Errorf("tax.countries.%s.regions.%s: must be an upper-case region code", country, region)
+			}
+			if !validPercent(regionRate) {
+				return fmt.Errorf("tax.countries.%s.regions.%s: must be between 0 and 100", country, region)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s ShippingRate) validate() error {
+	switch s.Type {
+	case ShippingFlat, ShippingWeight, ShippingFreeOver:
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+
+	if s.Rate < 0 || s.PerKg < 0 || s.Threshold < 0 {
+		return errors.New("amounts must not be negative")
+	}
+
+	return nil
+}
+
+func validPercent(rate float64) bool {
+	return rate >= 0 && rate <= 100
+}
+
+// Cost is the shipping cost of an order with the given discounted subtotal
+// and weight in kilograms. The rate's amounts are in the subtotal's currency.
+func (s ShippingRate) Cost(subtotal money.Money, weight float64) money.Money {
+	rate := money.New(s.Rate, subtotal.Currency)
+
+	switch s.Type {
+	case ShippingWeight:
+		return rate.Add(money.New(int64(math.Round(float64(s.PerKg)*weight)), subtotal.Currency))
+	case ShippingFreeOver:
+		if subtotal.Amount >= s.Threshold {
+			return money.New(0, subtotal.Currency)
+		}
+		return rate
+	default:
+		return rate
+	}
+}
+
+// ShippingRate returns the shipping rate for the destination. A nil address
+// gets the default rate.
+func (r *Rules) ShippingRate(address *model.Address) ShippingRate {
+	if address != nil {
+		if rate, ok := r.Shipping.Countries[address.Country]; ok {
+			return rate
+		}
+	}
+	return r.Shipping.Default
+}
+
+// TaxRate returns the tax rate in percent for the destination. A nil address
+// gets the default rate. Regions are matched by code, which is what
+// model.Address.Normalize stores for countries with a list of regions.
+func (r *Rules) TaxRate(address *model.Address) float64 {
+	if address == nil {
+		return r.Tax.Default
+	}
+
+	country, ok := r.Tax.Countries[address.Country]
+	if !ok {
+		return r.Tax.Default
+	}
+
+	if rate, ok := country.Regions[strings.ToUpper(address.Region)]; ok {
+		return rate
+	}
+
+	return country.Rate
+}
+
+// Price runs the pricing pipeline for the order lines: subtotal, coupon
+// discount, shipping to the address and tax on top. The lines must be priced
+// in the settlement currency.
+func (r *Rules) Price(items []model.Item, discount money.Money, address *model.Address) model.Breakdown {
+	var b model.Breakdown
+	var weight float64
+
+	b.Subtotal = money.New(0, r.Currency)
+	for _, item := range items {
+		b.Subtotal = b.Subtotal.Add(item.Total())
+		weight += item.Weight * float64(item.Quantity)
+	}
+
+	b.Discount = discount.Min(b.Subtotal)
+	discounted := b.Subtotal.Sub(b.Discount)
+
+	b.Shipping = r.ShippingRate(address).Cost(discounted, weight)
+
+	b.TaxRate = r.TaxRate(address)
+	taxable := discounted
+	if r.Tax.ShippingTaxable {
+		taxable = taxable.Add(b.Shipping)
+	}
+	b.Tax = taxable.Percent(b.TaxRate)
+
+	return b
+}
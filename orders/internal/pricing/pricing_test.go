@@ -0,0 +1,222 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const testRules = `
+currency: BYN
+
+shipping:
+  default:
+    type: flat
+    rate: 3000
+  countries:
+    BY:
+      type: free_over
+      rate: 990
+      threshold: 15000
+    RU:
+      type: weight
+      rate: 1500
+      per_kg: 250
+
+tax:
+  default: 5
+  shipping_taxable: true
+  countries:
+    BY:
+      rate: 20
+    US:
+      rate: 0
+      regions:
+        CA: 7.25
+        NY: 4
+`
+
+func parseRules(t *testing.T, yaml string) *Rules {
+	t.Helper()
+
+	rules, err := Parse(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return rules
+}
+
+func byn(amount int64) money.Money {
+	return money.New(amount, "BYN")
+}
+
+func item(price int64, quantity int32, weight float64) model.Item {
+	return model.Item{Price: byn(price), Quantity: quantity, Weight: weight}
+}
+
+func TestShippingCost(t *testing.T) {
+	rules := parseRules(t, testRules)
+
+	tests := []struct {
+		name     string
+		country  string
+		subtotal int64
+		weight   float64
+		want     int64
+	}{
+		{"flat", "DE", 5000, 10, 3000},
+		{"flat without an address", "", 5000, 10, 3000},
+		{"weight", "RU", 5000, 2, 2000},
+		{"weight rounds to the minor unit", "RU", 5000, 0.333, 1583},
+		{"weight of nothing", "RU", 5000, 0, 1500},
+		{"free over, below the threshold", "BY", 14999, 1, 990},
+		{"free over, at the threshold", "BY", 15000, 1, 0},
+		{"free over, above the threshold", "BY", 20000, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var address *model.Address
+			if tt.country != "" {
+				address = &model.Address{Country: tt.country}
+			}
+
+			got := rules.ShippingRate(address).Cost(byn(tt.subtotal), tt.weight)
+			if got != byn(tt.want) {
+				t.Errorf("shipping = %v, want %v", got, byn(tt.want))
+			}
+		})
+	}
+}
+
+func TestTaxRate(t *testing.T) {
+	rules := parseRules(t, testRules)
+
+	tests := []struct {
+		name    string
+		address *model.Address
+		want    float64
+	}{
+		{"no address", nil, 5},
+		{"country without a rule", &model.Address{Country: "DE"}, 5},
+		{"country rate", &model.Address{Country: "BY", Region: "Minsk"}, 20},
+		{"region rate", &model.Address{Country: "US", Region: "CA"}, 7.25},
+		{"region given by name", &model.Address{Country: "us", Region: " new york "}, 4},
+		{"region without a rate", &model.Address{Country: "US", Region: "TX"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.address != nil {
+				tt.address.Normalize()
+			}
+
+			if got := rules.TaxRate(tt.address); got != tt.want {
+				t.Errorf("tax rate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrice(t *testing.T) {
+	const untaxedShipping = `
+currency: BYN
+shipping:
+  default:
+    type: flat
+    rate: 1000
+tax:
+  default: 20
+  shipping_taxable: false
+`
+
+	tests := []struct {
+		name     string
+		rules    string
+		items    []model.Item
+		discount int64
+		address  *model.Address
+		want     model.Breakdown
+	}{
+		{
+			name:    "taxable shipping",
+			rules:   testRules,
+			items:   []model.Item{item(2500, 2, 1), item(1000, 1, 0.5)},
+			address: &model.Address{Country: "BY"},
+			want:    model.Breakdown{Subtotal: byn(6000), Discount: byn(0), Shipping: byn(990), Tax: byn(1398), TaxRate: 20},
+		},
+		{
+			name:    "untaxed shipping",
+			rules:   untaxedShipping,
+			items:   []model.Item{item(2500, 2, 1), item(1000, 1, 0.5)},
+			address: &model.Address{Country: "BY"},
+			want:    model.Breakdown{Subtotal: byn(6000), Discount: byn(0), Shipping: byn(1000), Tax: byn(1200), TaxRate: 20},
+		},
+		{
+			name:     "discount counts towards the free shipping threshold",
+			rules:    testRules,
+			items:    []model.Item{item(16000, 1, 1)},
+			discount: 2000,
+			address:  &model.Address{Country: "BY"},
+			want:     model.Breakdown{Subtotal: byn(16000), Discount: byn(2000), Shipping: byn(990), Tax: byn(2998), TaxRate: 20},
+		},
+		{
+			name:     "discount capped at the subtotal",
+			rules:    testRules,
+			items:    []model.Item{item(500, 2, 1)},
+			discount: 5000,
+			address:  &model.Address{Country: "BY"},
+			want:     model.Breakdown{Subtotal: byn(1000), Discount: byn(1000), Shipping: byn(990), Tax: byn(198), TaxRate: 20},
+		},
+		{
+			name:    "region rate",
+			rules:   testRules,
+			items:   []model.Item{item(10000, 1, 1)},
+			address: &model.Address{Country: "US", Region: "CA"},
+			want:    model.Breakdown{Subtotal: byn(10000), Discount: byn(0), Shipping: byn(3000), Tax: byn(943), TaxRate: 7.25},
+		},
+		{
+			name:  "no address",
+			rules: testRules,
+			items: []model.Item{item(1000, 3, 0)},
+			want:  model.Breakdown{Subtotal: byn(3000), Discount: byn(0), Shipping: byn(3000), Tax: byn(300), TaxRate: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRules(t, tt.rules)
+
+			got := rules.Price(tt.items, byn(tt.discount), tt.address)
+			if got != tt.want {
+				t.Errorf("Price =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"unknown top-level field", testRules + "\nrounding: up\n"},
+		{"unknown shipping field", strings.Replace(testRules, "rate: 3000", "rate: 3000\n    perkg: 10", 1)},
+		{"misspelt tax field", strings.Replace(testRules, "shipping_taxable", "shiping_taxable", 1)},
+		{"unknown shipping type", strings.Replace(testRules, "type: flat", "type: free", 1)},
+		{"negative rate", strings.Replace(testRules, "rate: 3000", "rate: -1", 1)},
+		{"tax rate over 100", strings.Replace(testRules, "default: 5", "default: 120", 1)},
+		{"lower-case region code", strings.Replace(testRules, "CA: 7.25", "ca: 7.25", 1)},
+		{"invalid currency", strings.Replace(testRules, "currency: BYN", "currency: rubles", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.yaml)); err == nil {
+				t.Error("Parse accepted the rules")
+			}
+		})
+	}
+}
@@ -3,6 +3,14 @@ package repository
 import "errors"
 
 var (
-	ErrNotFound   = errors.New("order not found")
-	ErrNotCreated = errors.New("order not created")
+	ErrNotFound       = errors.New("order not found")
+	ErrNotCreated     = errors.New("order not created")
+	ErrBadStatus      = errors.New("invalid order status")
+	ErrEditConflict   = errors.New("edit conflict")
+	ErrTooMany        = errors.New("quantity exceeds the ordered quantity")
+	ErrDuplicateCode  = errors.New("duplicate coupon code")
+	ErrCouponUsedUp   = errors.New("coupon usage limit reached")
+	ErrCouponUserUsed = errors.New("coupon per-user limit reached")
+	ErrInvoiceExists  = errors.New("order already invoiced")
+	ErrNotShippable   = errors.New("order cannot be shipped")
 )
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreateComment(_ context.Context, comment *model.Comment) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, exists := r.orders[comment.OrderID]; !exists {
+		return repository.ErrNotFound
+	}
+
+	comment.ID = int64(len(r.comments) + 1)
+	comment.CreatedAt = time.Now()
+
+	stored := *comment
+	r.comments = append(r.comments, &stored)
+
+	return nil
+}
+
+func (r *Repository) CommentsByOrderID(_ context.Context, orderID int64) ([]*model.Comment, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	comments := []*model.Comment{}
+	for _, comment := range r.comments {
+		if comment.OrderID == orderID {
+			c := *comment
+			comments = append(comments, &c)
+		}
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreateCoupon(_ context.Context, coupon *model.Coupon) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, stored := range r.coupons {
+		if stored.Code == coupon.Code {
+			return repository.ErrDuplicateCode
+		}
+	}
+
+	coupon.ID = int64(len(r.coupons) + 1)
+	coupon.CreatedAt = time.Now()
+
+	stored := *coupon
+	r.coupons = append(r.coupons, &stored)
+
+	return nil
+}
+
+func (r *Repository) CouponByID(_ context.Context, id int64) (*model.Coupon, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.coupon(func(c *model.Coupon) bool { return c.ID == id })
+}
+
+func (r *Repository) CouponByCode(_ context.Context, code string) (*model.Coupon, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.coupon(func(c *model.Coupon) bool { return c.Code == code })
+}
+
+func (r *Repository) Coupons(_ context.Context, limit int) ([]*model.Coupon, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	coupons := []*model.Coupon{}
+	for i := len(r.coupons) - 1; i >= 0 && len(coupons) < limit; i-- {
+		coupon := *r.coupons[i]
+		coupon.Uses = r.couponUses(coupon.ID, 0)
+		coupons = append(coupons, &coupon)
+	}
+
+	return coupons, nil
+}
+
+func (r *Repository) SetCouponActive(_ context.Context, id int64, active bool) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, coupon := range r.coupons {
+		if coupon.ID == id {
+			coupon.Active = active
+			return nil
+		}
+	}
+
+	return repository.ErrNotFound
+}
+
+func (r *Repository) CouponUserUses(_ context.Context, couponID, userID int64) (int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.couponUses(couponID, userID), nil
+}
+
+func (r *Repository) coupon(match func(*model.Coupon) bool) (*model.Coupon, error) {
+	i := slices.IndexFunc(r.coupons, match)
+	if i < 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	coupon := *r.coupons[i]
+	coupon.Uses = r.couponUses(coupon.ID, 0)
+
+	return &coupon, nil
+}
+
+// couponUses counts the orders that were not cancelled and were placed with
+// the coupon; a non-zero userID only counts the orders of that user.
+func (r *Repository) couponUses(couponID, userID int64) int {
+	var uses int
+	for _, order := range r.orders {
+		if order.Promotion == nil || order.Promotion.CouponID != couponID || order.Status == model.StatusCancelled {
+			continue
+		}
+		if userID == 0 || order.UserID == userID {
+			uses++
+		}
+	}
+	return uses
+}
+
+func (r *Repository) checkCouponLimits(couponID, userID int64) error {
+	coupon, err := r.coupon(func(c *model.Coupon) bool { return c.ID == couponID })
+	if err != nil {
+		return err
+	}
+
+	if coupon.UsageLimit > 0 && coupon.Uses >= coupon.UsageLimit {
+		return repository.ErrCouponUsedUp
+	}
+
+	if coupon.PerUserLimit > 0 && r.couponUses(couponID, userID) >= coupon.PerUserLimit {
+		return repository.ErrCouponUserUsed
+	}
+
+	return nil
+}
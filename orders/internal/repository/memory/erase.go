@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) EraseUser(_ context.Context, userID int64) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	erased := map[int64]bool{}
+
+	for _, order := range r.orders {
+		if order.UserID != userID {
+			continue
+		}
+
+		if order.Address != nil {
+			address := model.ErasedAddress(*order.Address)
+			order.Address = &address
+		}
+		order.Notes = model.Notes{}
+		erased[order.ID] = true
+	}
+
+	for _, payment := range r.payments {
+		if erased[payment.OrderID] {
+			payment.CardLast4 = ""
+		}
+	}
+
+	for _, ret := range r.returns {
+		if erased[ret.OrderID] {
+			ret.Reason = ""
+		}
+	}
+
+	for _, comment := range r.comments {
+		if erased[comment.OrderID] {
+			comment.Body = model.ErasedComment
+		}
+	}
+
+	return int64(len(erased)), nil
+}
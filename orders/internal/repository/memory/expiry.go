@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) TryLock(_ context.Context, key int64) (func() error, bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.locks[key] {
+		return nil, false, nil
+	}
+	r.locks[key] = true
+
+	release := func() error {
+		r.Lock()
+		defer r.Unlock()
+
+		delete(r.locks, key)
+		return nil
+	}
+
+	return release, true, nil
+}
+
+func (r *Repository) ExpireOrders(_ context.Context, placedBefore time.Time, limit int) ([]int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var ids []int64
+	for id, order := range r.orders {
+		if order.Status == model.StatusCreated && order.CreatedAt.Before(placedBefore) {
+			ids = append(ids, id)
+		}
+	}
+
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		order := r.orders[id]
+		order.Status = model.StatusCancelled
+		r.queueRelease(order, now)
+	}
+
+	return ids, nil
+}
+
+// queueRelease queues the stock of the lines of the order for release. It
+// must be called with the lock held.
+func (r *Repository) queueRelease(order *model.Order, now time.Time) {
+	for _, item := range order.Items {
+		r.releases = append(r.releases, &model.StockRelease{
+			ID:        int64(len(r.releases) + 1),
+			OrderID:   order.ID,
+			ItemID:    item.ItemID,
+			Quantity:  item.Quantity,
+			CreatedAt: now,
+		})
+	}
+}
+
+func (r *Repository) CancelOrder(_ context.Context, id int64, from string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	order, exists := r.orders[id]
+	if !exists {
+		return repository.ErrNotFound
+	}
+
+	if order.Status != from {
+		return repository.ErrEditConflict
+	}
+
+	order.Status = model.StatusCancelled
+	r.queueRelease(order, time.Now())
+
+	return nil
+}
+
+func (r *Repository) PendingStockReleases(_ context.Context, limit int) ([]*model.StockRelease, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var releases []*model.StockRelease
+	for _, release := range r.releases {
+		if release.ReleasedAt == nil && len(releases) < limit {
+			c := *release
+			releases = append(releases, &c)
+		}
+	}
+
+	return releases, nil
+}
+
+func (r *Repository) MarkStockReleased(_ context.Context, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, release := range r.releases {
+		if release.ID == id {
+			now := time.Now()
+			release.ReleasedAt = &now
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreateInvoice(_ context.Context, invoice *model.Invoice) error {
+	r.Lock()
+	defer r.Unlock()
+
+	var last int
+	for _, existing := range r.invoices {
+		if existing.OrderID == invoice.OrderID {
+			return repository.ErrInvoiceExists
+		}
+		if existing.Year == invoice.Year {
+			last = max(last, existing.Sequence)
+		}
+	}
+
+	invoice.ID = int64(len(r.invoices) + 1)
+	invoice.Sequence = last + 1
+	invoice.Number = model.InvoiceNumber(invoice.Year, invoice.Sequence)
+
+	r.invoices = append(r.invoices, copyInvoice(invoice))
+
+	return nil
+}
+
+func (r *Repository) InvoiceByOrderID(_ context.Context, orderID int64) (*model.Invoice, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	i := slices.IndexFunc(r.invoices, func(invoice *model.Invoice) bool {
+		return invoice.OrderID == orderID
+	})
+	if i < 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return copyInvoice(r.invoices[i]), nil
+}
+
+// copyInvoice copies the invoice deep enough that changes to the stored
+// order or to the copy never reach one another.
+func copyInvoice(invoice *model.Invoice) *model.Invoice {
+	c := *invoice
+	c.Order.Items = slices.Clone(invoice.Order.Items)
+	if invoice.Order.Address != nil {
+		address := *invoice.Order.Address
+		c.Order.Address = &address
+	}
+	if invoice.Order.Promotion != nil {
+		promotion := *invoice.Order.Promotion
+		c.Order.Promotion = &promotion
+	}
+	return &c
+}
@@ -2,7 +2,6 @@ package memory
 
 import (
 	"context"
-	"slices"
 	"sync"
 	"time"
 
@@ -16,35 +15,46 @@ const (
 
 type Repository struct {
 	sync.RWMutex
-	orders map[int64]*model.Order
+	orders    map[int64]*model.Order
+	payments  []*model.Payment
+	returns   []*model.Return
+	coupons   []*model.Coupon
+	invoices  []*model.Invoice
+	releases  []*model.StockRelease
+	comments  []*model.Comment
+	shipments []*model.Shipment
+	locks     map[int64]bool
 }
 
 func New() (*Repository, error) {
 	return &Repository{
 		orders: map[int64]*model.Order{},
+		locks:  map[int64]bool{},
 	}, nil
 }
 
-func (r *Repository) CreateOrder(_ context.Context, userID int64, price float64, items []model.Item) (int64, error) {
-	if len(items) == 0 {
+func (r *Repository) CreateOrder(_ context.Context, order *model.Order) (int64, error) {
+	if len(order.Items) == 0 {
 		return 0, repository.ErrNotCreated
 	}
 
 	r.Lock()
 	defer r.Unlock()
 
+	if order.Promotion != nil {
+		if err := r.checkCouponLimits(order.Promotion.CouponID, order.UserID); err != nil {
+			return 0, err
+		}
+	}
+
 	id := int64(len(r.orders) + 1)
 
-	order := model.Order{
-		ID:        id,
-		UserID:    userID,
-		Price:     price,
-		Status:    StatusNew,
-		Items:     items,
-		CreatedAt: time.Now(),
-	}
+	stored := *order
+	stored.ID = id
+	stored.Status = StatusNew
+	stored.CreatedAt = time.Now()
 
-	r.orders[id] = &order
+	r.orders[id] = &stored
 
 	return id, nil
 }
@@ -64,29 +74,3 @@ func (r *Repository) OrderByID(ctx context.Context, id int64) (*model.Order, err
 
 	return order, nil
 }
-
-func (r *Repository) OrdersByUserID(ctx context.Context, id int64) ([]*model.Order, error) {
-	if id < 1 {
-		return nil, repository.ErrNotFound
-	}
-
-	r.RLock()
-	defer r.RUnlock()
-
-	var orders []*model.Order
-	for _, order := range r.orders {
-		if order.UserID == id {
-			orders = append(orders, order)
-		}
-	}
-
-	if len(orders) == 0 {
-		return nil, repository.ErrNotFound
-	}
-
-	slices.SortFunc(orders, func(a, b *model.Order) int {
-		return b.CreatedAt.Compare(a.CreatedAt)
-	})
-
-	return orders, nil
-}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreatePayment(_ context.Context, payment *model.Payment) error {
+	r.Lock()
+	defer r.Unlock()
+
+	payment.ID = int64(len(r.payments) + 1)
+	payment.CreatedAt = time.Now()
+
+	stored := *payment
+	r.payments = append(r.payments, &stored)
+
+	return nil
+}
+
+func (r *Repository) PaymentsByOrderID(_ context.Context, orderID int64) ([]*model.Payment, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	payments := []*model.Payment{}
+	for _, payment := range r.payments {
+		if payment.OrderID == orderID {
+			p := *payment
+			payments = append(payments, &p)
+		}
+	}
+
+	return payments, nil
+}
+
+func (r *Repository) TransitionOrderStatus(_ context.Context, id int64, from, to string) error {
+	if !model.ValidStatus(to) {
+		return repository.ErrBadStatus
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	order, exists := r.orders[id]
+	if !exists {
+		return repository.ErrNotFound
+	}
+
+	if order.Status != from {
+		return repository.ErrEditConflict
+	}
+
+	order.Status = to
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) SalesByPeriod(_ context.Context, rng model.ReportRange, interval string) ([]*model.SalesPeriod, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	byStart := map[int64]*model.SalesPeriod{}
+	var periods []*model.SalesPeriod
+
+	for _, order := range r.sold(rng) {
+		start := model.PeriodStart(order.CreatedAt, interval)
+
+		period, exists := byStart[start.Unix()]
+		if !exists {
+			period = &model.SalesPeriod{Start: start, Revenue: money.New(0, rng.Currency)}
+			byStart[start.Unix()] = period
+			periods = append(periods, period)
+		}
+
+		period.Orders++
+		period.Revenue.Amount += order.Price.Amount
+	}
+
+	slices.SortFunc(periods, func(a, b *model.SalesPeriod) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return periods, nil
+}
+
+func (r *Repository) TopProducts(_ context.Context, rng model.ReportRange, by string, limit int) ([]*model.ProductSales, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	byID := map[int64]*model.ProductSales{}
+	var products []*model.ProductSales
+
+	for _, order := range r.sold(rng) {
+		for _, item := range order.Items {
+			product, exists := byID[item.ItemID]
+			if !exists {
+				product = &model.ProductSales{ItemID: item.ItemID, Revenue: money.New(0, rng.Currency)}
+				byID[item.ItemID] = product
+				products = append(products, product)
+			}
+
+			product.Name = max(product.Name, item.Name)
+			product.Quantity += int64(item.Quantity)
+			product.Revenue.Amount += item.Total().Amount - item.Discount.Amount
+		}
+	}
+
+	slices.SortFunc(products, func(a, b *model.ProductSales) int {
+		c := cmp.Compare(b.Quantity, a.Quantity)
+		if by == model.RankByRevenue {
+			c = cmp.Compare(b.Revenue.Amount, a.Revenue.Amount)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ItemID, b.ItemID)
+		}
+		return c
+	})
+
+	if len(products) > limit {
+		products = products[:limit]
+	}
+
+	return products, nil
+}
+
+func (r *Repository) SalesByStatus(_ context.Context, rng model.ReportRange) ([]*model.StatusSales, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	statuses := make([]*model.StatusSales, len(model.Statuses))
+	for i, status := range model.Statuses {
+		statuses[i] = &model.StatusSales{Status: status, Total: money.New(0, rng.Currency)}
+	}
+
+	for _, order := range r.orders {
+		if !inRange(order, rng) {
+			continue
+		}
+
+		i := slices.Index(model.Statuses, order.Status)
+		statuses[i].Orders++
+		statuses[i].Total.Amount += order.Price.Amount
+	}
+
+	return statuses, nil
+}
+
+// sold returns the orders of the range that count as sales. The caller must
+// hold the lock.
+func (r *Repository) sold(rng model.ReportRange) []*model.Order {
+	var orders []*model.Order
+	for _, order := range r.orders {
+		if model.Sold(order.Status) && inRange(order, rng) {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+func inRange(order *model.Order, rng model.ReportRange) bool {
+	return order.Price.Currency == rng.Currency &&
+		!order.CreatedAt.Before(rng.From) && order.CreatedAt.Before(rng.To)
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreateReturn(_ context.Context, ret *model.Return) error {
+	r.Lock()
+	defer r.Unlock()
+
+	order, exists := r.orders[ret.OrderID]
+	if !exists {
+		return repository.ErrNotFound
+	}
+
+	i := slices.IndexFunc(order.Items, func(item model.Item) bool {
+		return item.ItemID == ret.ItemID
+	})
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+
+	var requested int32
+	for _, existing := range r.returns {
+		if existing.OrderID == ret.OrderID && existing.ItemID == ret.ItemID && existing.Status != model.ReturnRejected {
+			requested += existing.Quantity
+		}
+	}
+
+	if requested+ret.Quantity > order.Items[i].Quantity {
+		return repository.ErrTooMany
+	}
+
+	ret.ID = int64(len(r.returns) + 1)
+	ret.Status = model.ReturnRequested
+	ret.CreatedAt = time.Now()
+
+	stored := *ret
+	r.returns = append(r.returns, &stored)
+
+	return nil
+}
+
+func (r *Repository) ReturnByID(_ context.Context, id int64) (*model.Return, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	ret, err := r.returnByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *ret
+	return &c, nil
+}
+
+func (r *Repository) ReturnsByOrderID(_ context.Context, orderID int64) ([]*model.Return, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	returns := []*model.Return{}
+	for _, ret := range r.returns {
+		if ret.OrderID == orderID {
+			c := *ret
+			returns = append(returns, &c)
+		}
+	}
+
+	return returns, nil
+}
+
+func (r *Repository) Returns(_ context.Context, status string, limit int) ([]*model.Return, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	returns := []*model.Return{}
+	for i := len(r.returns) - 1; i >= 0 && len(returns) < limit; i-- {
+		if status == "" || r.returns[i].Status == status {
+			c := *r.returns[i]
+			returns = append(returns, &c)
+		}
+	}
+
+	return returns, nil
+}
+
+func (r *Repository) TransitionReturn(_ context.Context, id int64, from, to, note string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	ret, err := r.returnByID(id)
+	if err != nil {
+		return err
+	}
+
+	if ret.Status != from {
+		return repository.ErrEditConflict
+	}
+
+	ret.Status = to
+	ret.StaffNote = note
+	ret.ResolvedAt = nil
+	if to != model.ReturnRequested {
+		now := time.Now()
+		ret.ResolvedAt = &now
+	}
+
+	return nil
+}
+
+func (r *Repository) SetReturnRefund(_ context.Context, id int64, amount money.Money, paymentID *int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	ret, err := r.returnByID(id)
+	if err != nil {
+		return err
+	}
+
+	ret.RefundAmount = amount
+	ret.RefundPaymentID = paymentID
+
+	return nil
+}
+
+func (r *Repository) MarkReturnRestocked(_ context.Context, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	ret, err := r.returnByID(id)
+	if err != nil {
+		return err
+	}
+
+	if ret.Status != model.ReturnApproved || ret.Restocked {
+		return repository.ErrEditConflict
+	}
+
+	ret.Restocked = true
+
+	return nil
+}
+
+func (r *Repository) UnmarkReturnRestocked(_ context.Context, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	ret, err := r.returnByID(id)
+	if err != nil {
+		return err
+	}
+
+	if !ret.Restocked {
+		return repository.ErrEditConflict
+	}
+
+	ret.Restocked = false
+
+	return nil
+}
+
+// returnByID must be called with the lock held.
+func (r *Repository) returnByID(id int64) (*model.Return, error) {
+	for _, ret := range r.returns {
+		if ret.ID == id {
+			return ret, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) Orders(_ context.Context, filter model.OrderFilter) ([]*model.Order, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	// compare puts the positions of two orders in the sort order: by the sort
+	// key, then by ID.
+	compare := func(a, b *model.OrderCursor) int {
+		var c int
+		if filter.Sort == model.SortHighest || filter.Sort == model.SortLowest {
+			c = cmp.Compare(a.Total, b.Total)
+		} else {
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if filter.Sort == model.SortNewest || filter.Sort == model.SortHighest {
+			c = -c
+		}
+		return c
+	}
+
+	orders := []*model.Order{}
+	for _, order := range r.orders {
+		if matches(order, &filter) && (filter.After == nil || compare(model.CursorAfter(order, filter.Sort), filter.After) > 0) {
+			orders = append(orders, order)
+		}
+	}
+
+	slices.SortFunc(orders, func(a, b *model.Order) int {
+		return compare(model.CursorAfter(a, filter.Sort), model.CursorAfter(b, filter.Sort))
+	})
+
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+	}
+
+	return orders, nil
+}
+
+func matches(order *model.Order, f *model.OrderFilter) bool {
+	switch {
+	case f.Status != "" && order.Status != f.Status:
+		return false
+	case f.UserID != 0 && order.UserID != f.UserID:
+		return false
+	case f.CreatedAfter != nil && order.CreatedAt.Before(*f.CreatedAfter):
+		return false
+	case f.CreatedBefore != nil && !order.CreatedAt.Before(*f.CreatedBefore):
+		return false
+	case f.MinTotal != nil && (order.Price.Currency != f.MinTotal.Currency || order.Price.Amount < f.MinTotal.Amount):
+		return false
+	case f.MaxTotal != nil && (order.Price.Currency != f.MaxTotal.Currency || order.Price.Amount > f.MaxTotal.Amount):
+		return false
+	case f.ProductID != 0:
+		return slices.ContainsFunc(order.Items, func(item model.Item) bool {
+			return item.ItemID == f.ProductID
+		})
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreateShipment(_ context.Context, shipment *model.Shipment) (string, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	order, exists := r.orders[shipment.OrderID]
+	if !exists {
+		return "", repository.ErrNotFound
+	}
+
+	if !slices.Contains(model.ShippableStatuses, order.Status) {
+		return "", repository.ErrNotShippable
+	}
+
+	ordered := map[int64]int32{}
+	for _, item := range order.Items {
+		ordered[item.ItemID] = item.Quantity
+	}
+
+	shipped := map[int64]int32{}
+	for _, existing := range r.shipments {
+		if existing.OrderID == shipment.OrderID {
+			for _, line := range existing.Lines {
+				shipped[line.ItemID] += line.Quantity
+			}
+		}
+	}
+
+	for _, line := range shipment.Lines {
+		if shipped[line.ItemID]+line.Quantity > ordered[line.ItemID] {
+			return "", repository.ErrTooMany
+		}
+		shipped[line.ItemID] += line.Quantity
+	}
+
+	shipment.ID = int64(len(r.shipments) + 1)
+	shipment.CreatedAt = time.Now()
+
+	stored := *shipment
+	stored.Lines = slices.Clone(shipment.Lines)
+	r.shipments = append(r.shipments, &stored)
+
+	order.Status = model.ShippedStatus(order.Items, shipped)
+
+	return order.Status, nil
+}
+
+func (r *Repository) ShipmentsByOrderID(_ context.Context, orderID int64) ([]*model.Shipment, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	shipments := []*model.Shipment{}
+	for _, shipment := range r.shipments {
+		if shipment.OrderID == orderID {
+			s := *shipment
+			s.Lines = slices.Clone(shipment.Lines)
+			shipments = append(shipments, &s)
+		}
+	}
+
+	return shipments, nil
+}
@@ -0,0 +1,58 @@
+package postgre
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	query := `
+		INSERT INTO order_comments (order_id, author_id, author, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	args := []any{comment.OrderID, comment.AuthorID, comment.Author, comment.Body}
+
+	return r.DB.QueryRowContext(ctx, query, args...).Scan(&comment.ID, &comment.CreatedAt)
+}
+
+// CommentsByOrderID returns the comments on the order, oldest first.
+func (r *Repository) CommentsByOrderID(ctx context.Context, orderID int64) ([]*model.Comment, error) {
+	query := `
+		SELECT id, order_id, author_id, author, body, created_at
+		FROM order_comments
+		WHERE order_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*model.Comment{}
+
+	for rows.Next() {
+		var comment model.Comment
+		err := rows.Scan(
+			&comment.ID,
+			&comment.OrderID,
+			&comment.AuthorID,
+			&comment.Author,
+			&comment.Body,
+			&comment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, &comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
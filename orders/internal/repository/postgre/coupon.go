@@ -0,0 +1,260 @@
+package postgre
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// couponUses counts the orders placed with a coupon. Cancelled orders give
+// their use back.
+const couponUses = `
+	SELECT COUNT(*)
+	FROM order_promotions p
+	JOIN orders o ON o.id = p.order_id
+	JOIN statuses s ON s.id = o.status_id
+	WHERE p.coupon_id = c.id AND s.name <> 'cancelled'`
+
+const couponColumns = `
+	c.id, c.code, c.kind, c.value, c.min_basket, c.category_ids, c.product_ids,
+	c.expires_at, c.usage_limit, c.per_user_limit, c.active, c.created_at,
+	(` + couponUses + `)`
+
+func scanCoupon(row scanner) (*model.Coupon, error) {
+	var coupon model.Coupon
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Kind,
+		&coupon.Value,
+		&coupon.MinBasket,
+		pq.Array(&coupon.CategoryIDs),
+		pq.Array(&coupon.ProductIDs),
+		&expiresAt,
+		&coupon.UsageLimit,
+		&coupon.PerUserLimit,
+		&coupon.Active,
+		&coupon.CreatedAt,
+		&coupon.Uses,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		coupon.ExpiresAt = &expiresAt.Time
+	}
+
+	return &coupon, nil
+}
+
+func (r *Repository) CreateCoupon(ctx context.Context, coupon *model.Coupon) error {
+	query := `
+		INSERT INTO coupons (code, kind, value, min_basket, category_ids, product_ids, expires_at, usage_limit, per_user_limit, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	args := []any{
+		coupon.Code,
+		coupon.Kind,
+		coupon.Value,
+		coupon.MinBasket,
+		pq.Array(nonNil(coupon.CategoryIDs)),
+		pq.Array(nonNil(coupon.ProductIDs)),
+		coupon.ExpiresAt,
+		coupon.UsageLimit,
+		coupon.PerUserLimit,
+		coupon.Active,
+	}
+
+	err := r.DB.QueryRowContext(ctx, query, args...).Scan(&coupon.ID, &coupon.CreatedAt)
+	if err != nil {
+		var pqError *pq.Error
+		if errors.As(err, &pqError) && pqError.Code == "23505" && pqError.Constraint == "coupons_code_key" {
+			return repository.ErrDuplicateCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) CouponByID(ctx context.Context, id int64) (*model.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.id = $1`
+
+	coupon, err := scanCoupon(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return coupon, nil
+}
+
+func (r *Repository) CouponByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.code = $1`
+
+	coupon, err := scanCoupon(r.DB.QueryRowContext(ctx, query, code))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return coupon, nil
+}
+
+func (r *Repository) Coupons(ctx context.Context, limit int) ([]*model.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c ORDER BY c.created_at DESC, c.id DESC LIMIT $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons := []*model.Coupon{}
+
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, coupon)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+func (r *Repository) SetCouponActive(ctx context.Context, id int64, active bool) error {
+	res, err := r.DB.ExecContext(ctx, `UPDATE coupons SET active = $2 WHERE id = $1`, id, active)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// CouponUserUses returns how many orders that were not cancelled the user
+// placed with the coupon.
+func (r *Repository) CouponUserUses(ctx context.Context, couponID, userID int64) (int, error) {
+	return couponUserUses(ctx, r.DB, couponID, userID)
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func couponUserUses(ctx context.Context, db querier, couponID, userID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM order_promotions p
+		JOIN orders o ON o.id = p.order_id
+		JOIN statuses s ON s.id = o.status_id
+		WHERE p.coupon_id = $1 AND o.user_id = $2 AND s.name <> 'cancelled'`
+
+	var uses int
+	err := db.QueryRowContext(ctx, query, couponID, userID).Scan(&uses)
+	return uses, err
+}
+
+// insertPromotion records the coupon applied to a new order. The coupon row
+// is locked while its uses are counted, so concurrent orders cannot go over
+// the usage limits.
+func insertPromotion(ctx context.Context, tx *sql.Tx, orderID, userID int64, promotion *model.Promotion) error {
+	_, err := tx.ExecContext(ctx, `SELECT id FROM coupons WHERE id = $1 FOR UPDATE`, promotion.CouponID)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.id = $1`
+
+	coupon, err := scanCoupon(tx.QueryRowContext(ctx, query, promotion.CouponID))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return repository.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	if coupon.UsageLimit > 0 && coupon.Uses >= coupon.UsageLimit {
+		return repository.ErrCouponUsedUp
+	}
+
+	if coupon.PerUserLimit > 0 {
+		uses, err := couponUserUses(ctx, tx, coupon.ID, userID)
+		if err != nil {
+			return err
+		}
+		if uses >= coupon.PerUserLimit {
+			return repository.ErrCouponUserUsed
+		}
+	}
+
+	promotionQuery := `
+		INSERT INTO order_promotions (order_id, coupon_id, code, discount)
+		VALUES ($1, $2, $3, $4)`
+
+	_, err = tx.ExecContext(ctx, promotionQuery, orderID, promotion.CouponID, promotion.Code, promotion.Discount)
+	return err
+}
+
+// promotionByID returns the promotion applied to the order, or nil when the
+// order was placed without a coupon. The discount is in the order currency.
+func (r *Repository) promotionByID(ctx context.Context, id int64, currency string) (*model.Promotion, error) {
+	query := `
+		SELECT coupon_id, code, discount
+		FROM order_promotions
+		WHERE order_id = $1`
+
+	var promotion model.Promotion
+
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&promotion.CouponID, &promotion.Code, &promotion.Discount)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	promotion.Discount.Currency = currency
+
+	return &promotion, nil
+}
+
+func nonNil(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
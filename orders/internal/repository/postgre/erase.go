@@ -0,0 +1,66 @@
+package postgre
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// EraseUser anonymises the orders of the user and returns how many there
+// are. Delivery addresses keep only their country and region; notes, gift
+// messages, return reasons and card digits are cleared, and the bodies of
+// staff comments are replaced with model.ErasedComment. The orders and their
+// amounts stay for the books, and so do invoices, which must be kept as
+// issued.
+func (r *Repository) EraseUser(ctx context.Context, userID int64) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	queries := []string{
+		`UPDATE order_addresses
+		SET name = '', line1 = '', line2 = '', city = '', postal_code = '', phone = ''
+		WHERE order_id IN (SELECT id FROM orders WHERE user_id = $1)`,
+
+		`UPDATE payments
+		SET card_last4 = ''
+		WHERE order_id IN (SELECT id FROM orders WHERE user_id = $1)`,
+
+		`UPDATE returns
+		SET reason = ''
+		WHERE order_id IN (SELECT id FROM orders WHERE user_id = $1)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	query := `
+		UPDATE order_comments
+		SET body = $2
+		WHERE order_id IN (SELECT id FROM orders WHERE user_id = $1)`
+
+	if _, err := tx.ExecContext(ctx, query, userID, model.ErasedComment); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE orders SET note = '', gift_message = '' WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	orders, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,204 @@
+package postgre
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// TryLock takes the session-level advisory lock key without waiting and
+// reports whether it got it. The lock lives on a connection of its own, which
+// goes back to the pool when release is called.
+func (r *Repository) TryLock(ctx context.Context, key int64) (release func() error, acquired bool, err error) {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		return nil, false, err
+	}
+
+	release = func() error {
+		defer conn.Close()
+
+		// The context the lock was taken with may be done by now.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key)
+		if err != nil {
+			// Drop the connection instead of pooling it: ending the session
+			// releases the lock.
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		return err
+	}
+
+	return release, true, nil
+}
+
+// ExpireOrders cancels at most limit orders still awaiting payment that were
+// placed before the time and returns their IDs. The stock of their lines is
+// queued for release in the same transaction, so it is never lost. Orders
+// another transaction holds are left for the next run.
+func (r *Repository) ExpireOrders(ctx context.Context, placedBefore time.Time, limit int) ([]int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	expireQuery := `
+		UPDATE orders
+		SET status_id = (SELECT id FROM statuses WHERE name = $1)
+		WHERE id IN (
+			SELECT id
+			FROM orders
+			WHERE status_id = (SELECT id FROM statuses WHERE name = $2) AND created_at < $3
+			ORDER BY id
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`
+
+	rows, err := tx.QueryContext(ctx, expireQuery, model.StatusCancelled, model.StatusCreated, placedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	releaseQuery := `
+		INSERT INTO stock_releases (order_id, item_id, quantity)
+		SELECT order_id, item_id, quantity
+		FROM order_items
+		WHERE order_id = ANY($1)`
+
+	if _, err := tx.ExecContext(ctx, releaseQuery, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CancelOrder cancels the order if it is still in the status from and queues
+// the stock of its lines for release in the same transaction. It fails with
+// ErrEditConflict if the order is no longer in that status.
+func (r *Repository) CancelOrder(ctx context.Context, id int64, from string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cancelQuery := `
+		UPDATE orders
+		SET status_id = (SELECT id FROM statuses WHERE name = $2)
+		WHERE id = $1 AND status_id = (SELECT id FROM statuses WHERE name = $3)`
+
+	res, err := tx.ExecContext(ctx, cancelQuery, id, model.StatusCancelled, from)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		var exists bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1)`, id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrNotFound
+		}
+		return repository.ErrEditConflict
+	}
+
+	releaseQuery := `
+		INSERT INTO stock_releases (order_id, item_id, quantity)
+		SELECT order_id, item_id, quantity
+		FROM order_items
+		WHERE order_id = $1`
+
+	if _, err := tx.ExecContext(ctx, releaseQuery, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PendingStockReleases returns at most limit releases the catalog has not
+// taken back yet, oldest first.
+func (r *Repository) PendingStockReleases(ctx context.Context, limit int) ([]*model.StockRelease, error) {
+	query := `
+		SELECT id, order_id, item_id, quantity, created_at
+		FROM stock_releases
+		WHERE released_at IS NULL
+		ORDER BY id
+		LIMIT $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []*model.StockRelease
+
+	for rows.Next() {
+		var release model.StockRelease
+		err := rows.Scan(&release.ID, &release.OrderID, &release.ItemID, &release.Quantity, &release.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, &release)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+func (r *Repository) MarkStockReleased(ctx context.Context, id int64) error {
+	query := `
+		UPDATE stock_releases
+		SET released_at = NOW()
+		WHERE id = $1`
+
+	_, err := r.DB.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,105 @@
+package postgre
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// CreateInvoice numbers the invoice and stores it with the snapshot of its
+// order. The counter row of the year stays locked until the transaction ends
+// and a failed insert rolls the increment back, so numbers have no gaps.
+func (r *Repository) CreateInvoice(ctx context.Context, invoice *model.Invoice) error {
+	snapshot, err := json.Marshal(invoice.Order)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	counterQuery := `
+		INSERT INTO invoice_counters (year, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number`
+
+	err = tx.QueryRowContext(ctx, counterQuery, invoice.Year).Scan(&invoice.Sequence)
+	if err != nil {
+		return err
+	}
+
+	invoice.Number = model.InvoiceNumber(invoice.Year, invoice.Sequence)
+
+	insertQuery := `
+		INSERT INTO invoices (order_id, number, year, sequence, snapshot, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	args := []any{
+		invoice.OrderID,
+		invoice.Number,
+		invoice.Year,
+		invoice.Sequence,
+		snapshot,
+		invoice.IssuedAt,
+	}
+
+	err = tx.QueryRowContext(ctx, insertQuery, args...).Scan(&invoice.ID)
+	if err != nil {
+		var pqError *pq.Error
+		if errors.As(err, &pqError) && pqError.Code == "23505" && pqError.Constraint == "invoices_order_id_key" {
+			return repository.ErrInvoiceExists
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) InvoiceByOrderID(ctx context.Context, orderID int64) (*model.Invoice, error) {
+	if orderID < 1 {
+		return nil, repository.ErrNotFound
+	}
+
+	query := `
+		SELECT id, number, year, sequence, order_id, issued_at, snapshot
+		FROM invoices
+		WHERE order_id = $1`
+
+	var invoice model.Invoice
+	var snapshot []byte
+
+	err := r.DB.QueryRowContext(ctx, query, orderID).Scan(
+		&invoice.ID,
+		&invoice.Number,
+		&invoice.Year,
+		&invoice.Sequence,
+		&invoice.OrderID,
+		&invoice.IssuedAt,
+		&snapshot,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if err := json.Unmarshal(snapshot, &invoice.Order); err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
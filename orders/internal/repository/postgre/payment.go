@@ -0,0 +1,106 @@
+package postgre
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+func (r *Repository) CreatePayment(ctx context.Context, payment *model.Payment) error {
+	query := `
+		INSERT INTO payments (order_id, operation, amount, currency, succeeded, reference, card_last4, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	args := []any{
+		payment.OrderID,
+		payment.Operation,
+		payment.Amount,
+		payment.Amount.Currency,
+		payment.Succeeded,
+		payment.Reference,
+		payment.CardLast4,
+		payment.Error,
+	}
+
+	return r.DB.QueryRowContext(ctx, query, args...).Scan(&payment.ID, &payment.CreatedAt)
+}
+
+func (r *Repository) PaymentsByOrderID(ctx context.Context, orderID int64) ([]*model.Payment, error) {
+	query := `
+		SELECT id, order_id, operation, amount, currency, succeeded, reference, card_last4, error, created_at
+		FROM payments
+		WHERE order_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []*model.Payment{}
+
+	for rows.Next() {
+		var payment model.Payment
+		err := rows.Scan(
+			&payment.ID,
+			&payment.OrderID,
+			&payment.Operation,
+			&payment.Amount,
+			&payment.Amount.Currency,
+			&payment.Succeeded,
+			&payment.Reference,
+			&payment.CardLast4,
+			&payment.Error,
+			&payment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		payments = append(payments, &payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// TransitionOrderStatus moves the order from one status to another. It fails
+// with ErrEditConflict if the order is no longer in the expected status.
+func (r *Repository) TransitionOrderStatus(ctx context.Context, id int64, from, to string) error {
+	query := `
+		UPDATE orders
+		SET status_id = (SELECT id FROM statuses WHERE name = $3)
+		WHERE id = $1 AND status_id = (SELECT id FROM statuses WHERE name = $2)`
+
+	res, err := r.DB.ExecContext(ctx, query, id, from, to)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.DB.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1)`, id).Scan(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+
+	return repository.ErrEditConflict
+}
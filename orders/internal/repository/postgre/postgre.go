@@ -50,7 +50,11 @@ func (r *Repository) Close() error {
 	return r.DB.Close()
 }
 
-func (r *Repository) CreateOrder(ctx context.Context, userID int64, price float64, items []model.Item) (int64, error) {
+// CreateOrder stores the order with its price breakdown, notes, lines,
+// delivery address and the promotion applied to it, if any.
+func (r *Repository) CreateOrder(ctx context.Context, order *model.Order) (int64, error) {
+	userID, items, address, promotion := order.UserID, order.Items, order.Address, order.Promotion
+
 	if len(items) == 0 {
 		return 0, repository.ErrNotCreated
 	}
@@ -64,18 +68,34 @@ func (r *Repository) CreateOrder(ctx context.Context, userID int64, price float6
 	var id int64
 
 	orderQuery := `
-		INSERT INTO orders (user_id, total_price, status_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO orders (user_id, total_price, currency, subtotal, discount, shipping, tax, tax_rate, display_currency, display_rate, note, gift_message, status_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id`
 
-	err = tx.QueryRowContext(ctx, orderQuery, userID, price, StatusNew).Scan(&id)
+	orderArgs := []any{
+		userID,
+		order.Price,
+		order.Price.Currency,
+		order.Subtotal,
+		order.Discount,
+		order.Shipping,
+		order.Tax,
+		order.TaxRate,
+		order.Display.Currency,
+		order.Display.Rate,
+		order.Note,
+		order.GiftMessage,
+		StatusNew,
+	}
+
+	err = tx.QueryRowContext(ctx, orderQuery, orderArgs...).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 
 	itemsQuery := `
-		INSERT INTO order_items (order_id, item_id, quantity)
-		VALUES ($1, $2, $3)`
+		INSERT INTO order_items (order_id, item_id, name, quantity, price, category_id, discount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	stmt, err := tx.PrepareContext(ctx, itemsQuery)
 	if err != nil {
@@ -87,12 +107,40 @@ func (r *Repository) CreateOrder(ctx context.Context, userID int64, price float6
 		if item.Quantity < 1 {
 			return 0, repository.ErrNotCreated
 		}
-		_, err := stmt.ExecContext(ctx, id, item.ItemID, item.Quantity)
+		_, err := stmt.ExecContext(ctx, id, item.ItemID, item.Name, item.Quantity, item.Price, item.CategoryID, item.Discount)
 		if err != nil {
 			return 0, err
 		}
 	}
 
+	if address != nil {
+		addressQuery := `
+			INSERT INTO order_addresses (order_id, name, line1, line2, city, region, postal_code, country, phone)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+		args := []any{
+			id,
+			address.Name,
+			address.Line1,
+			address.Line2,
+			address.City,
+			address.Region,
+			address.PostalCode,
+			address.Country,
+			address.Phone,
+		}
+
+		if _, err := tx.ExecContext(ctx, addressQuery, args...); err != nil {
+			return 0, err
+		}
+	}
+
+	if promotion != nil {
+		if err := insertPromotion(ctx, tx, id, userID, promotion); err != nil {
+			return 0, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return 0, err
 	}
@@ -106,7 +154,7 @@ func (r *Repository) OrderByID(ctx context.Context, id int64) (*model.Order, err
 	}
 
 	query := `
-		SELECT o.id, o.user_id, o.total_price, s.name AS status, o.created_at
+		SELECT o.id, o.user_id, o.total_price, o.currency, o.subtotal, o.discount, o.shipping, o.tax, o.tax_rate, o.display_currency, o.display_rate, o.note, o.gift_message, s.name AS status, o.created_at
 		FROM orders o
 		JOIN statuses s ON o.status_id = s.id
 		WHERE o.id = $1`
@@ -117,6 +165,16 @@ func (r *Repository) OrderByID(ctx context.Context, id int64) (*model.Order, err
 		&order.ID,
 		&order.UserID,
 		&order.Price,
+		&order.Price.Currency,
+		&order.Subtotal,
+		&order.Discount,
+		&order.Shipping,
+		&order.Tax,
+		&order.TaxRate,
+		&order.Display.Currency,
+		&order.Display.Rate,
+		&order.Note,
+		&order.GiftMessage,
 		&order.Status,
 		&order.CreatedAt,
 	)
@@ -130,70 +188,70 @@ func (r *Repository) OrderByID(ctx context.Context, id int64) (*model.Order, err
 		}
 	}
 
-	items, err := r.itemsByID(ctx, id)
+	inCurrency(&order.Breakdown, order.Price.Currency)
+
+	items, err := r.itemsByID(ctx, id, order.Price.Currency)
 	if err != nil {
 		return nil, err
 	}
 
 	order.Items = items
 
-	return &order, nil
-}
+	address, err := r.addressByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-func (r *Repository) OrdersByUserID(ctx context.Context, id int64) ([]*model.Order, error) {
-	query := `
-		SELECT o.id, o.user_id, o.total_price, s.name, o.created_at
-		FROM orders o
-		JOIN statuses s ON o.status_id = s.id
-		WHERE o.user_id = $1
-		ORDER BY o.created_at DESC`
+	order.Address = address
 
-	rows, err := r.DB.QueryContext(ctx, query, id)
+	promotion, err := r.promotionByID(ctx, id, order.Price.Currency)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var orders []*model.Order
+	order.Promotion = promotion
 
-	for rows.Next() {
-		var order model.Order
-		err := rows.Scan(
-			&order.ID,
-			&order.UserID,
-			&order.Price,
-			&order.Status,
-			&order.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return &order, nil
+}
 
-		orders = append(orders, &order)
-	}
+// addressByID returns the delivery address stored with the order, or nil for
+// orders placed before addresses were recorded.
+func (r *Repository) addressByID(ctx context.Context, id int64) (*model.Address, error) {
+	query := `
+		SELECT name, line1, line2, city, region, postal_code, country, phone
+		FROM order_addresses
+		WHERE order_id = $1`
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+	var address model.Address
 
-	if len(orders) == 0 {
-		return nil, repository.ErrNotFound
-	}
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&address.Name,
+		&address.Line1,
+		&address.Line2,
+		&address.City,
+		&address.Region,
+		&address.PostalCode,
+		&address.Country,
+		&address.Phone,
+	)
 
-	for _, order := range orders {
-		items, err := r.itemsByID(ctx, order.ID)
-		if err != nil {
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil
+		default:
 			return nil, err
 		}
-		order.Items = items
 	}
 
-	return orders, nil
+	return &address, nil
 }
 
-func (r *Repository) itemsByID(ctx context.Context, id int64) ([]model.Item, error) {
+// itemsByID returns the lines of the order. Their amounts are in the order
+// currency.
+func (r *Repository) itemsByID(ctx context.Context, id int64, currency string) ([]model.Item, error) {
 	query := `
-		SELECT item_id, quantity
+		SELECT item_id, name, quantity, price, category_id, discount
 		FROM order_items
 		WHERE order_id = $1`
 
@@ -207,9 +265,11 @@ func (r *Repository) itemsByID(ctx context.Context, id int64) ([]model.Item, err
 
 	for rows.Next() {
 		var item model.Item
-		if err := rows.Scan(&item.ItemID, &item.Quantity); err != nil {
+		if err := rows.Scan(&item.ItemID, &item.Name, &item.Quantity, &item.Price, &item.CategoryID, &item.Discount); err != nil {
 			return nil, err
 		}
+		item.Price.Currency = currency
+		item.Discount.Currency = currency
 		items = append(items, item)
 	}
 
@@ -219,3 +279,12 @@ func (r *Repository) itemsByID(ctx context.Context, id int64) ([]model.Item, err
 
 	return items, nil
 }
+
+// inCurrency sets the currency of the breakdown amounts, which are stored
+// without one of their own.
+func inCurrency(b *model.Breakdown, currency string) {
+	b.Subtotal.Currency = currency
+	b.Discount.Currency = currency
+	b.Shipping.Currency = currency
+	b.Tax.Currency = currency
+}
@@ -0,0 +1,125 @@
+package postgre
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// SalesByPeriod sums the sales of the range by interval, oldest first.
+// Intervals without sales are left out.
+func (r *Repository) SalesByPeriod(ctx context.Context, rng model.ReportRange, interval string) ([]*model.SalesPeriod, error) {
+	query := `
+		SELECT date_trunc($1, o.created_at AT TIME ZONE 'UTC') AS period, COUNT(*), SUM(o.total_price)::BIGINT
+		FROM orders o
+		JOIN statuses s ON o.status_id = s.id
+		WHERE s.name = ANY($2) AND o.currency = $3 AND o.created_at >= $4 AND o.created_at < $5
+		GROUP BY period
+		ORDER BY period`
+
+	rows, err := r.DB.QueryContext(ctx, query, interval, pq.Array(model.SoldStatuses), rng.Currency, rng.From, rng.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []*model.SalesPeriod
+
+	for rows.Next() {
+		period := model.SalesPeriod{}
+		if err := rows.Scan(&period.Start, &period.Orders, &period.Revenue); err != nil {
+			return nil, err
+		}
+
+		// The period is a UTC timestamp without a time zone.
+		period.Start = period.Start.UTC()
+		period.Revenue.Currency = rng.Currency
+		periods = append(periods, &period)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+// TopProducts returns at most limit of the products sold in the range,
+// ranked by quantity or revenue.
+func (r *Repository) TopProducts(ctx context.Context, rng model.ReportRange, by string, limit int) ([]*model.ProductSales, error) {
+	rank := "quantity"
+	if by == model.RankByRevenue {
+		rank = "revenue"
+	}
+
+	query := `
+		SELECT i.item_id, MAX(i.name), SUM(i.quantity)::BIGINT AS quantity, SUM(i.price * i.quantity - i.discount)::BIGINT AS revenue
+		FROM order_items i
+		JOIN orders o ON i.order_id = o.id
+		JOIN statuses s ON o.status_id = s.id
+		WHERE s.name = ANY($1) AND o.currency = $2 AND o.created_at >= $3 AND o.created_at < $4
+		GROUP BY i.item_id
+		ORDER BY ` + rank + ` DESC, i.item_id
+		LIMIT $5`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(model.SoldStatuses), rng.Currency, rng.From, rng.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*model.ProductSales
+
+	for rows.Next() {
+		product := model.ProductSales{}
+		if err := rows.Scan(&product.ItemID, &product.Name, &product.Quantity, &product.Revenue); err != nil {
+			return nil, err
+		}
+
+		product.Revenue.Currency = rng.Currency
+		products = append(products, &product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// SalesByStatus counts the orders of the range in every status, in the order
+// of model.Statuses.
+func (r *Repository) SalesByStatus(ctx context.Context, rng model.ReportRange) ([]*model.StatusSales, error) {
+	query := `
+		SELECT s.name, COUNT(o.id), COALESCE(SUM(o.total_price), 0)::BIGINT
+		FROM statuses s
+		LEFT JOIN orders o ON o.status_id = s.id AND o.currency = $1 AND o.created_at >= $2 AND o.created_at < $3
+		GROUP BY s.id, s.name
+		ORDER BY array_position($4::TEXT[], s.name)`
+
+	rows, err := r.DB.QueryContext(ctx, query, rng.Currency, rng.From, rng.To, pq.Array(model.Statuses))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []*model.StatusSales
+
+	for rows.Next() {
+		status := model.StatusSales{}
+		if err := rows.Scan(&status.Status, &status.Orders, &status.Total); err != nil {
+			return nil, err
+		}
+
+		status.Total.Currency = rng.Currency
+		statuses = append(statuses, &status)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
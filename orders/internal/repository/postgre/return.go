@@ -0,0 +1,256 @@
+package postgre
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Maksim-Kot/Commons/money"
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const returnColumns = `
+	id, order_id, item_id, quantity, reason, status, staff_note,
+	refund_amount, refund_currency, refund_payment_id, restocked, created_at, resolved_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReturn(row scanner) (*model.Return, error) {
+	var ret model.Return
+	var refundPaymentID sql.NullInt64
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(
+		&ret.ID,
+		&ret.OrderID,
+		&ret.ItemID,
+		&ret.Quantity,
+		&ret.Reason,
+		&ret.Status,
+		&ret.StaffNote,
+		&ret.RefundAmount,
+		&ret.RefundAmount.Currency,
+		&refundPaymentID,
+		&ret.Restocked,
+		&ret.CreatedAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if refundPaymentID.Valid {
+		ret.RefundPaymentID = &refundPaymentID.Int64
+	}
+	if resolvedAt.Valid {
+		ret.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &ret, nil
+}
+
+// CreateReturn stores a new return request. The order line is locked while
+// the quantities of earlier requests that were not rejected are added up, so
+// more units than were ordered can never be requested.
+func (r *Repository) CreateReturn(ctx context.Context, ret *model.Return) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ordered int32
+
+	lineQuery := `
+		SELECT quantity
+		FROM order_items
+		WHERE order_id = $1 AND item_id = $2
+		FOR UPDATE`
+
+	err = tx.QueryRowContext(ctx, lineQuery, ret.OrderID, ret.ItemID).Scan(&ordered)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return repository.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	var requested int32
+
+	requestedQuery := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM returns
+		WHERE order_id = $1 AND item_id = $2 AND status <> $3`
+
+	err = tx.QueryRowContext(ctx, requestedQuery, ret.OrderID, ret.ItemID, model.ReturnRejected).Scan(&requested)
+	if err != nil {
+		return err
+	}
+
+	if requested+ret.Quantity > ordered {
+		return repository.ErrTooMany
+	}
+
+	insertQuery := `
+		INSERT INTO returns (order_id, item_id, quantity, reason, status, refund_currency)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	ret.Status = model.ReturnRequested
+
+	args := []any{ret.OrderID, ret.ItemID, ret.Quantity, ret.Reason, ret.Status, ret.RefundAmount.Currency}
+
+	err = tx.QueryRowContext(ctx, insertQuery, args...).Scan(&ret.ID, &ret.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) ReturnByID(ctx context.Context, id int64) (*model.Return, error) {
+	query := `SELECT ` + returnColumns + ` FROM returns WHERE id = $1`
+
+	ret, err := scanReturn(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func (r *Repository) ReturnsByOrderID(ctx context.Context, orderID int64) ([]*model.Return, error) {
+	query := `SELECT ` + returnColumns + ` FROM returns WHERE order_id = $1 ORDER BY id`
+
+	return r.queryReturns(ctx, query, orderID)
+}
+
+// Returns lists the most recent return requests, optionally only those in
+// the given status.
+func (r *Repository) Returns(ctx context.Context, status string, limit int) ([]*model.Return, error) {
+	query := `
+		SELECT ` + returnColumns + `
+		FROM returns
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+
+	return r.queryReturns(ctx, query, status, limit)
+}
+
+func (r *Repository) queryReturns(ctx context.Context, query string, args ...any) ([]*model.Return, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []*model.Return{}
+
+	for rows.Next() {
+		ret, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, ret)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+// TransitionReturn moves the return from one status to another and stores
+// the staff note. It fails with ErrEditConflict if the return is no longer in
+// the expected status.
+func (r *Repository) TransitionReturn(ctx context.Context, id int64, from, to, note string) error {
+	query := `
+		UPDATE returns
+		SET status = $3,
+			staff_note = $4,
+			resolved_at = CASE WHEN $3 = 'requested' THEN NULL ELSE NOW() END
+		WHERE id = $1 AND status = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, id, from, to, note)
+	if err != nil {
+		return err
+	}
+
+	return r.returnUpdated(ctx, res, id)
+}
+
+func (r *Repository) SetReturnRefund(ctx context.Context, id int64, amount money.Money, paymentID *int64) error {
+	query := `
+		UPDATE returns
+		SET refund_amount = $2, refund_currency = $3, refund_payment_id = $4
+		WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id, amount, amount.Currency, paymentID)
+	if err != nil {
+		return err
+	}
+
+	return r.returnUpdated(ctx, res, id)
+}
+
+// MarkReturnRestocked records that the returned units went back into stock.
+// Only approved returns that have not been restocked yet can be marked.
+func (r *Repository) MarkReturnRestocked(ctx context.Context, id int64) error {
+	query := `
+		UPDATE returns
+		SET restocked = TRUE
+		WHERE id = $1 AND status = $2 AND NOT restocked`
+
+	res, err := r.DB.ExecContext(ctx, query, id, model.ReturnApproved)
+	if err != nil {
+		return err
+	}
+
+	return r.returnUpdated(ctx, res, id)
+}
+
+// UnmarkReturnRestocked takes back the record that the returned units went
+// back into stock, for when putting them back failed after all.
+func (r *Repository) UnmarkReturnRestocked(ctx context.Context, id int64) error {
+	query := `
+		UPDATE returns
+		SET restocked = FALSE
+		WHERE id = $1 AND restocked`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return r.returnUpdated(ctx, res, id)
+}
+
+// returnUpdated turns an update that matched no rows into ErrNotFound or
+// ErrEditConflict, depending on whether the return exists.
+func (r *Repository) returnUpdated(ctx context.Context, res sql.Result, id int64) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	if _, err := r.ReturnByID(ctx, id); err != nil {
+		return err
+	}
+
+	return repository.ErrEditConflict
+}
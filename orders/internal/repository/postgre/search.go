@@ -0,0 +1,169 @@
+package postgre
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+
+	"github.com/lib/pq"
+)
+
+// Orders returns the orders matching the filter in its sort order, at most
+// filter.Limit of them. Pages are read with keyset pagination: the cursor is
+// compared with the sort key and the ID, which the indexes cover.
+func (r *Repository) Orders(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		where = append(where, "s.name = "+arg(filter.Status))
+	}
+	if filter.UserID != 0 {
+		where = append(where, "o.user_id = "+arg(filter.UserID))
+	}
+	if filter.ProductID != 0 {
+		where = append(where, "EXISTS (SELECT 1 FROM order_items i WHERE i.order_id = o.id AND i.item_id = "+arg(filter.ProductID)+")")
+	}
+	if filter.CreatedAfter != nil {
+		where = append(where, "o.created_at >= "+arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		where = append(where, "o.created_at < "+arg(*filter.CreatedBefore))
+	}
+	if filter.MinTotal != nil {
+		where = append(where, "o.currency = "+arg(filter.MinTotal.Currency), "o.total_price >= "+arg(filter.MinTotal.Amount))
+	}
+	if filter.MaxTotal != nil {
+		where = append(where, "o.currency = "+arg(filter.MaxTotal.Currency), "o.total_price <= "+arg(filter.MaxTotal.Amount))
+	}
+
+	column, direction := "o.created_at", "DESC"
+	switch filter.Sort {
+	case model.SortOldest:
+		direction = "ASC"
+	case model.SortHighest:
+		column = "o.total_price"
+	case model.SortLowest:
+		column, direction = "o.total_price", "ASC"
+	}
+
+	if c := filter.After; c != nil {
+		var key any = c.CreatedAt
+		if column == "o.total_price" {
+			key = c.Total
+		}
+
+		op := "<"
+		if direction == "ASC" {
+			op = ">"
+		}
+		where = append(where, fmt.Sprintf("(%s, o.id) %s (%s, %s)", column, op, arg(key), arg(c.ID)))
+	}
+
+	query := `
+		SELECT o.id, o.user_id, o.total_price, o.currency, o.subtotal, o.discount, o.shipping, o.tax, o.tax_rate, o.display_currency, o.display_rate, o.note, o.gift_message, s.name, o.created_at
+		FROM orders o
+		JOIN statuses s ON o.status_id = s.id`
+
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf("\n\t\tORDER BY %s %s, o.id %s\n\t\tLIMIT %s", column, direction, direction, arg(filter.Limit))
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*model.Order{}
+
+	for rows.Next() {
+		var order model.Order
+		err := rows.Scan(
+			&order.ID,
+			&order.UserID,
+			&order.Price,
+			&order.Price.Currency,
+			&order.Subtotal,
+			&order.Discount,
+			&order.Shipping,
+			&order.Tax,
+			&order.TaxRate,
+			&order.Display.Currency,
+			&order.Display.Rate,
+			&order.Note,
+			&order.GiftMessage,
+			&order.Status,
+			&order.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		inCurrency(&order.Breakdown, order.Price.Currency)
+		orders = append(orders, &order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadItems(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// loadItems fills in the lines of the orders with a single query. Their
+// amounts are in the currency of their order.
+func (r *Repository) loadItems(ctx context.Context, orders []*model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Order, len(orders))
+	ids := make([]int64, len(orders))
+	for i, order := range orders {
+		byID[order.ID] = order
+		ids[i] = order.ID
+	}
+
+	query := `
+		SELECT order_id, item_id, name, quantity, price, category_id, discount
+		FROM order_items
+		WHERE order_id = ANY($1)`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orderID int64
+			item    model.Item
+		)
+		if err := rows.Scan(&orderID, &item.ItemID, &item.Name, &item.Quantity, &item.Price, &item.CategoryID, &item.Discount); err != nil {
+			return err
+		}
+
+		order := byID[orderID]
+		item.Price.Currency = order.Price.Currency
+		item.Discount.Currency = order.Price.Currency
+		order.Items = append(order.Items, item)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,231 @@
+package postgre
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/Maksim-Kot/Tech-store-orders/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// CreateShipment stores the shipment and moves the order to shipped or
+// partially shipped, depending on what is left to ship, and returns the new
+// status. The order is locked while the quantities of earlier shipments are
+// added up, so no unit can be shipped twice.
+func (r *Repository) CreateShipment(ctx context.Context, shipment *model.Shipment) (string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var status string
+
+	orderQuery := `
+		SELECT s.name
+		FROM orders o
+		JOIN statuses s ON o.status_id = s.id
+		WHERE o.id = $1
+		FOR UPDATE OF o`
+
+	err = tx.QueryRowContext(ctx, orderQuery, shipment.OrderID).Scan(&status)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", repository.ErrNotFound
+		default:
+			return "", err
+		}
+	}
+
+	if !slices.Contains(model.ShippableStatuses, status) {
+		return "", repository.ErrNotShippable
+	}
+
+	items, err := txItems(ctx, tx, shipment.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	shipped, err := shippedQuantities(ctx, tx, shipment.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	ordered := make(map[int64]int32, len(items))
+	for _, item := range items {
+		ordered[item.ItemID] = item.Quantity
+	}
+
+	for _, line := range shipment.Lines {
+		if shipped[line.ItemID]+line.Quantity > ordered[line.ItemID] {
+			return "", repository.ErrTooMany
+		}
+		shipped[line.ItemID] += line.Quantity
+	}
+
+	shipmentQuery := `
+		INSERT INTO shipments (order_id, carrier, tracking_number)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, shipmentQuery, shipment.OrderID, shipment.Carrier, shipment.TrackingNumber).Scan(&shipment.ID, &shipment.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	lineQuery := `
+		INSERT INTO shipment_lines (shipment_id, order_id, item_id, quantity)
+		VALUES ($1, $2, $3, $4)`
+
+	for _, line := range shipment.Lines {
+		if _, err := tx.ExecContext(ctx, lineQuery, shipment.ID, shipment.OrderID, line.ItemID, line.Quantity); err != nil {
+			return "", err
+		}
+	}
+
+	status = model.ShippedStatus(items, shipped)
+
+	statusQuery := `
+		UPDATE orders
+		SET status_id = (SELECT id FROM statuses WHERE name = $2)
+		WHERE id = $1`
+
+	if _, err := tx.ExecContext(ctx, statusQuery, shipment.OrderID, status); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// ShipmentsByOrderID returns the shipments of the order with their lines,
+// oldest first.
+func (r *Repository) ShipmentsByOrderID(ctx context.Context, orderID int64) ([]*model.Shipment, error) {
+	query := `
+		SELECT id, order_id, carrier, tracking_number, created_at
+		FROM shipments
+		WHERE order_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []*model.Shipment{}
+	byID := map[int64]*model.Shipment{}
+
+	for rows.Next() {
+		var shipment model.Shipment
+		err := rows.Scan(&shipment.ID, &shipment.OrderID, &shipment.Carrier, &shipment.TrackingNumber, &shipment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		shipments = append(shipments, &shipment)
+		byID[shipment.ID] = &shipment
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	linesQuery := `
+		SELECT shipment_id, item_id, quantity
+		FROM shipment_lines
+		WHERE order_id = $1
+		ORDER BY shipment_id, item_id`
+
+	lines, err := r.DB.QueryContext(ctx, linesQuery, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer lines.Close()
+
+	for lines.Next() {
+		var (
+			shipmentID int64
+			line       model.ShipmentLine
+		)
+		if err := lines.Scan(&shipmentID, &line.ItemID, &line.Quantity); err != nil {
+			return nil, err
+		}
+
+		if shipment, ok := byID[shipmentID]; ok {
+			shipment.Lines = append(shipment.Lines, line)
+		}
+	}
+
+	if err = lines.Err(); err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
+
+// txItems returns the item IDs and quantities of the lines of the order.
+func txItems(ctx context.Context, tx *sql.Tx, orderID int64) ([]model.Item, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT item_id, quantity FROM order_items WHERE order_id = $1`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.Item
+
+	for rows.Next() {
+		var item model.Item
+		if err := rows.Scan(&item.ItemID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// shippedQuantities returns how many units of every line of the order have
+// been shipped so far.
+func shippedQuantities(ctx context.Context, tx *sql.Tx, orderID int64) (map[int64]int32, error) {
+	query := `
+		SELECT item_id, SUM(quantity)::INTEGER
+		FROM shipment_lines
+		WHERE order_id = $1
+		GROUP BY item_id`
+
+	rows, err := tx.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipped := map[int64]int32{}
+
+	for rows.Next() {
+		var (
+			itemID   int64
+			quantity int32
+		)
+		if err := rows.Scan(&itemID, &quantity); err != nil {
+			return nil, err
+		}
+		shipped[itemID] = quantity
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shipped, nil
+}
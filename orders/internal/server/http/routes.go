@@ -11,8 +11,35 @@ func (s *Server) routes() http.Handler {
 
 	router.HandleFunc("GET /healthcheck", s.handler.HealthcheckHandler)
 	router.HandleFunc("POST /order", s.handler.CreateOrderHandler)
+	router.HandleFunc("POST /orders/quote", s.handler.QuoteOrderHandler)
 	router.HandleFunc("GET /order/{id}", s.handler.OrderByIDHandler)
 	router.HandleFunc("GET /orders/user/{id}", s.handler.OrdersByUserIDHandler)
+	router.HandleFunc("GET /user/{id}/data", s.handler.UserDataHandler)
+	router.HandleFunc("POST /user/{id}/erase", s.handler.EraseUserHandler)
+	router.HandleFunc("GET /orders", s.handler.OrdersHandler)
+	router.HandleFunc("PUT /order/{id}/status", s.handler.UpdateOrderStatusHandler)
+	router.HandleFunc("POST /order/{id}/payment", s.handler.PayOrderHandler)
+	router.HandleFunc("GET /order/{id}/payments", s.handler.PaymentsHandler)
+	router.HandleFunc("GET /order/{id}/invoice", s.handler.InvoiceHandler)
+	router.HandleFunc("POST /order/{id}/returns", s.handler.CreateReturnHandler)
+	router.HandleFunc("GET /order/{id}/returns", s.handler.OrderReturnsHandler)
+	router.HandleFunc("POST /order/{id}/comments", s.handler.CreateCommentHandler)
+	router.HandleFunc("GET /order/{id}/comments", s.handler.CommentsHandler)
+	router.HandleFunc("POST /order/{id}/shipments", s.handler.CreateShipmentHandler)
+	router.HandleFunc("GET /order/{id}/shipments", s.handler.ShipmentsHandler)
+	router.HandleFunc("GET /returns", s.handler.ReturnsHandler)
+	router.HandleFunc("GET /return/{id}", s.handler.ReturnHandler)
+	router.HandleFunc("PUT /return/{id}/approve", s.handler.ApproveReturnHandler)
+	router.HandleFunc("PUT /return/{id}/reject", s.handler.RejectReturnHandler)
+	router.HandleFunc("PUT /return/{id}/restocked", s.handler.RestockReturnHandler)
+	router.HandleFunc("DELETE /return/{id}/restocked", s.handler.RestockReturnHandler)
+	router.HandleFunc("POST /coupons", s.handler.CreateCouponHandler)
+	router.HandleFunc("GET /coupons", s.handler.CouponsHandler)
+	router.HandleFunc("GET /coupon/{id}", s.handler.CouponHandler)
+	router.HandleFunc("PUT /coupon/{id}/active", s.handler.UpdateCouponActiveHandler)
+	router.HandleFunc("GET /reports/sales", s.handler.SalesReportHandler)
+	router.HandleFunc("GET /reports/products", s.handler.TopProductsHandler)
+	router.HandleFunc("GET /reports/statuses", s.handler.StatusReportHandler)
 
 	v1 := http.NewServeMux()
 	v1.Handle("/v1/", http.StripPrefix("/v1", router))
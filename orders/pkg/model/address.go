@@ -0,0 +1,165 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Address is a delivery address. Orders keep a copy of the address they were
+// placed with, so later changes to a user's address book do not affect them.
+type Address struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+// countryRules describes what an address in a country must contain. When
+// Regions is set, the region must be one of them, given by code or by name,
+// and is stored as the code; tax rates per region are looked up by it.
+type countryRules struct {
+	Name           string
+	PostalCode     *regexp.Regexp
+	RegionRequired bool
+	Regions        map[string]string
+}
+
+var countries = map[string]countryRules{
+	"BY": {Name: "Belarus", PostalCode: regexp.MustCompile(`^\d{6}$`)},
+	"RU": {Name: "Russia", PostalCode: regexp.MustCompile(`^\d{6}$`), RegionRequired: true},
+	"KZ": {Name: "Kazakhstan", PostalCode: regexp.MustCompile(`^(\d{6}|[A-Z]\d{2}[A-Z]\d[A-Z]\d)$`)},
+	"UA": {Name: "Ukraine", PostalCode: regexp.MustCompile(`^\d{5}$`)},
+	"PL": {Name: "Poland", PostalCode: regexp.MustCompile(`^\d{2}-\d{3}$`)},
+	"LT": {Name: "Lithuania", PostalCode: regexp.MustCompile(`^(LT-)?\d{5}$`)},
+	"LV": {Name: "Latvia", PostalCode: regexp.MustCompile(`^(LV-)?\d{4}$`)},
+	"DE": {Name: "Germany", PostalCode: regexp.MustCompile(`^\d{5}$`)},
+	"GB": {Name: "United Kingdom", PostalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+	"US": {Name: "United States", PostalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`), RegionRequired: true, Regions: usStates},
+}
+
+var usStates = map[string]string{
+	"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas",
+	"CA": "California", "CO": "Colorado", "CT": "Connecticut", "DE": "Delaware",
+	"DC": "District of Columbia", "FL": "Florida", "GA": "Georgia", "HI": "Hawaii",
+	"ID": "Idaho", "IL": "Illinois", "IN": "Indiana", "IA": "Iowa",
+	"KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana", "ME": "Maine",
+	"MD": "Maryland", "MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota",
+	"MS": "Mississippi", "MO": "Missouri", "MT": "Montana", "NE": "Nebraska",
+	"NV": "Nevada", "NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico",
+	"NY": "New York", "NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio",
+	"OK": "Oklahoma", "OR": "Oregon", "PA": "Pennsylvania", "RI": "Rhode Island",
+	"SC": "South Carolina", "SD": "South Dakota", "TN": "Tennessee", "TX": "Texas",
+	"UT": "Utah", "VT": "Vermont", "VA": "Virginia", "WA": "Washington",
+	"WV": "West Virginia", "WI": "Wisconsin", "WY": "Wyoming",
+}
+
+// Countries returns the ISO 3166-1 alpha-2 codes of the countries orders can
+// be shipped to, mapped to their names.
+func Countries() map[string]string {
+	names := make(map[string]string, len(countries))
+	for code, rules := range countries {
+		names[code] = rules.Name
+	}
+	return names
+}
+
+// Normalize trims the fields of the address and upper-cases the country and
+// postal code. In countries with a list of regions, a region given by name is
+// replaced with its code.
+func (a *Address) Normalize() {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.TrimSpace(a.Region)
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Phone = strings.TrimSpace(a.Phone)
+
+	if regions := countries[a.Country].Regions; regions != nil {
+		a.Region = regionCode(regions, a.Region)
+	}
+}
+
+// regionCode returns the code of the region given by code or name, or the
+// region as it is when there is no such region.
+func regionCode(regions map[string]string, region string) string {
+	if _, ok := regions[strings.ToUpper(region)]; ok {
+		return strings.ToUpper(region)
+	}
+	for code, name := range regions {
+		if strings.EqualFold(name, region) {
+			return code
+		}
+	}
+	return region
+}
+
+// Validate checks the address against the rules of its country and returns
+// the problems keyed by the JSON field name. An empty map means the address
+// is valid.
+func (a *Address) Validate() map[string]string {
+	errs := map[string]string{}
+
+	required := map[string]string{
+		"name":    a.Name,
+		"line1":   a.Line1,
+		"city":    a.City,
+		"country": a.Country,
+	}
+	for field, value := range required {
+		if value == "" {
+			errs[field] = "must be provided"
+		}
+	}
+
+	limits := map[string]string{
+		"name":   a.Name,
+		"line1":  a.Line1,
+		"line2":  a.Line2,
+		"city":   a.City,
+		"region": a.Region,
+	}
+	for field, value := range limits {
+		if utf8.RuneCountInString(value) > 100 {
+			errs[field] = "must not be more than 100 characters long"
+		}
+	}
+
+	if utf8.RuneCountInString(a.Phone) > 20 {
+		errs["phone"] = "must not be more than 20 characters long"
+	}
+
+	if a.Country == "" {
+		return errs
+	}
+
+	rules, ok := countries[a.Country]
+	if !ok {
+		errs["country"] = "is not supported"
+		return errs
+	}
+
+	switch {
+	case rules.RegionRequired && a.Region == "":
+		errs["region"] = "must be provided"
+	case a.Region != "" && rules.Regions != nil:
+		if _, ok := rules.Regions[a.Region]; !ok {
+			errs["region"] = "is not a region of " + rules.Name
+		}
+	}
+
+	switch {
+	case a.PostalCode == "":
+		errs["postal_code"] = "must be provided"
+	case !rules.PostalCode.MatchString(a.PostalCode):
+		errs["postal_code"] = "is not valid for " + rules.Name
+	}
+
+	return errs
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+// Coupon kinds: a percentage off the eligible lines or a fixed amount off
+// them.
+const (
+	CouponPercent = "percent"
+	CouponFixed   = "fixed"
+)
+
+// Coupon is a discount code. Value is a whole percentage for percent coupons
+// and an amount in minor units of the settlement currency for fixed ones, as
+// is MinBasket. A coupon with CategoryIDs or ProductIDs only discounts the
+// order lines in that scope; MinBasket is checked against the whole order.
+// Zero limits mean unlimited use.
+type Coupon struct {
+	ID           int64      `json:"id"`
+	Code         string     `json:"code"`
+	Kind         string     `json:"kind"`
+	Value        int64      `json:"value"`
+	MinBasket    int64      `json:"min_basket"`
+	CategoryIDs  []int64    `json:"category_ids"`
+	ProductIDs   []int64    `json:"product_ids"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	UsageLimit   int        `json:"usage_limit"`
+	PerUserLimit int        `json:"per_user_limit"`
+	Active       bool       `json:"active"`
+	Uses         int        `json:"uses"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Promotion is the coupon applied to an order and the discount it gave.
+type Promotion struct {
+	CouponID int64       `json:"coupon_id"`
+	Code     string      `json:"code"`
+	Discount money.Money `json:"discount"`
+}
+
+// CouponQuote is the result of checking a coupon against a basket before the
+// order is placed. Reason explains why an invalid coupon cannot be used.
+type CouponQuote struct {
+	Code     string      `json:"code"`
+	Valid    bool        `json:"valid"`
+	Reason   string      `json:"reason,omitempty"`
+	Discount money.Money `json:"discount"`
+}
+
+// NormalizeCouponCode makes coupon codes case-insensitive.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (c *Coupon) Normalize() {
+	c.Code = NormalizeCouponCode(c.Code)
+	c.Kind = strings.ToLower(strings.TrimSpace(c.Kind))
+}
+
+// Validate reports the problems with the coupon, keyed by JSON field name.
+func (c *Coupon) Validate() map[string]string {
+	errs := map[string]string{}
+
+	switch {
+	case c.Code == "":
+		errs["code"] = "must be provided"
+	case len(c.Code) > 32:
+		errs["code"] = "must not be more than 32 characters long"
+	case strings.ContainsFunc(c.Code, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}):
+		errs["code"] = "must only contain letters, digits, dashes and underscores"
+	}
+
+	switch c.Kind {
+	case CouponPercent:
+		if c.Value < 1 || c.Value > 100 {
+			errs["value"] = "must be between 1 and 100"
+		}
+	case CouponFixed:
+		if c.Value <= 0 {
+			errs["value"] = "must be greater than zero"
+		}
+	default:
+		errs["kind"] = "must be percent or fixed"
+	}
+
+	if c.MinBasket < 0 {
+		errs["min_basket"] = "must not be negative"
+	}
+	if c.UsageLimit < 0 {
+		errs["usage_limit"] = "must not be negative"
+	}
+	if c.PerUserLimit < 0 {
+		errs["per_user_limit"] = "must not be negative"
+	}
+
+	return errs
+}
+
+// Expired reports whether the coupon can no longer be used at the given time.
+func (c *Coupon) Expired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// Applies reports whether the order line is in the coupon's scope. A coupon
+// without categories and products applies to every line.
+func (c *Coupon) Applies(item Item) bool {
+	if len(c.CategoryIDs) == 0 && len(c.ProductIDs) == 0 {
+		return true
+	}
+	return slices.Contains(c.ProductIDs, item.ItemID) ||
+		(item.CategoryID != 0 && slices.Contains(c.CategoryIDs, item.CategoryID))
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Invoice is the invoice of a paid order. Invoices are numbered without gaps
+// within each calendar year. Order is a copy of the order taken when the
+// invoice was issued; the invoice is always rendered from it, so later
+// changes to the order never alter an issued invoice.
+type Invoice struct {
+	ID       int64     `json:"id"`
+	Number   string    `json:"number"`
+	Year     int       `json:"year"`
+	Sequence int       `json:"sequence"`
+	OrderID  int64     `json:"order_id"`
+	IssuedAt time.Time `json:"issued_at"`
+	Order    Order     `json:"order"`
+}
+
+// InvoiceNumber formats the number of the sequence-th invoice of the year,
+// e.g. "INV-2026-000042".
+func InvoiceNumber(year, sequence int) string {
+	return fmt.Sprintf("INV-%d-%06d", year, sequence)
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Length limits of the free text stored with an order, in characters.
+const (
+	MaxNoteLength        = 1000
+	MaxGiftMessageLength = 300
+	MaxCommentLength     = 2000
+)
+
+// Notes is what the customer wrote at checkout: a note for the shop and a
+// message to print on the gift slip. Both are optional.
+type Notes struct {
+	Note        string `json:"note,omitempty"`
+	GiftMessage string `json:"gift_message,omitempty"`
+}
+
+func (n *Notes) Normalize() {
+	n.Note = strings.TrimSpace(n.Note)
+	n.GiftMessage = strings.TrimSpace(n.GiftMessage)
+}
+
+func (n *Notes) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if utf8.RuneCountInString(n.Note) > MaxNoteLength {
+		errs["note"] = "must not be more than 1000 characters long"
+	}
+	if utf8.RuneCountInString(n.GiftMessage) > MaxGiftMessageLength {
+		errs["gift_message"] = "must not be more than 300 characters long"
+	}
+
+	return errs
+}
+
+// Comment is an internal remark staff left on an order. Customers never see
+// comments. Author is the name of the staff member when the comment was
+// written.
+type Comment struct {
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"order_id"`
+	AuthorID  int64     `json:"author_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *Comment) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if c.AuthorID < 1 {
+		errs["author_id"] = "must be provided"
+	}
+	if c.Author == "" {
+		errs["author"] = "must be provided"
+	}
+
+	switch {
+	case c.Body == "":
+		errs["body"] = "must be provided"
+	case utf8.RuneCountInString(c.Body) > MaxCommentLength:
+		errs["body"] = "must not be more than 2000 characters long"
+	}
+
+	return errs
+}
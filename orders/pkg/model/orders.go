@@ -1,19 +1,123 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
 
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+const (
+	StatusCreated    = "created"
+	StatusPaid       = "paid"
+	StatusProcessing = "processing"
+	StatusShipped    = "shipped"
+	StatusDelivered  = "delivered"
+	StatusCancelled  = "cancelled"
+
+	// StatusPartiallyShipped is set when some but not all of the units of
+	// an order have been shipped.
+	StatusPartiallyShipped = "partially_shipped"
+)
+
+// Statuses lists every order status in the order an order moves through them.
+var Statuses = []string{
+	StatusCreated,
+	StatusPaid,
+	StatusProcessing,
+	StatusPartiallyShipped,
+	StatusShipped,
+	StatusDelivered,
+	StatusCancelled,
+}
+
+func ValidStatus(status string) bool {
+	return slices.Contains(Statuses, status)
+}
+
+// StatusChanges lists the statuses staff can move an order to from each
+// status. Paid, partially shipped and shipped are left out as targets: they
+// follow from payments and shipments and are only set by those.
+var StatusChanges = map[string][]string{
+	StatusCreated:          {StatusCancelled},
+	StatusPaid:             {StatusProcessing, StatusCancelled},
+	StatusProcessing:       {StatusCancelled},
+	StatusPartiallyShipped: {},
+	StatusShipped:          {StatusDelivered},
+	StatusDelivered:        {},
+	StatusCancelled:        {},
+}
+
+// CanChangeStatus reports whether staff can move an order from one status to
+// the other.
+func CanChangeStatus(from, to string) bool {
+	return slices.Contains(StatusChanges[from], to)
+}
+
+// Item is an order line. Name is the product name when the order was placed,
+// Price is the unit price and Weight the unit weight in kilograms. Discount
+// is the part of a coupon's discount that falls on the whole line.
 type Item struct {
-	ItemID   int64 `json:"item_id"`
-	Quantity int32 `json:"quantity"`
+	ItemID     int64       `json:"item_id"`
+	Name       string      `json:"name,omitempty"`
+	CategoryID int64       `json:"category_id,omitempty"`
+	Quantity   int32       `json:"quantity"`
+	Price      money.Money `json:"price"`
+	Weight     float64     `json:"weight,omitempty"`
+	Discount   money.Money `json:"discount"`
+}
+
+// Total is the price of the whole line before its discount.
+func (i Item) Total() money.Money {
+	return i.Price.Mul(int64(i.Quantity))
+}
+
+// Breakdown shows how the price of an order is made up. Tax is charged at
+// TaxRate percent on the discounted subtotal, and on shipping where the
+// destination taxes it. All amounts are in the order currency.
+type Breakdown struct {
+	Subtotal money.Money `json:"subtotal"`
+	Discount money.Money `json:"discount"`
+	Shipping money.Money `json:"shipping"`
+	Tax      money.Money `json:"tax"`
+	TaxRate  float64     `json:"tax_rate"`
+}
+
+// Total is what the customer pays.
+func (b Breakdown) Total() money.Money {
+	return b.Subtotal.Sub(b.Discount).Add(b.Shipping).Add(b.Tax)
+}
+
+// Display is the currency the shopper saw prices in and the rate used to
+// convert from the settlement currency: one settlement unit is Rate display
+// units.
+type Display struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+}
+
+// Quote is what an order would cost if it were placed now. Coupon is set when
+// a coupon code was given.
+type Quote struct {
+	Breakdown
+	Coupon *CouponQuote `json:"coupon,omitempty"`
 }
 
+// Order is a placed order. Price is the total of the breakdown, in the
+// settlement currency the order is priced and paid in; Display records the
+// currency the shopper chose and Notes what they wrote at checkout.
 type Order struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Price     float64   `json:"price"`
-	Status    string    `json:"status"`
-	Items     []Item    `json:"items"`
-	CreatedAt time.Time `json:"created_at"`
+	ID     int64       `json:"id"`
+	UserID int64       `json:"user_id"`
+	Price  money.Money `json:"price"`
+	Breakdown
+	Notes
+	Display   Display    `json:"display"`
+	Status    string     `json:"status"`
+	Items     []Item     `json:"items"`
+	Address   *Address   `json:"address,omitempty"`
+	Promotion *Promotion `json:"promotion,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type Cart struct {
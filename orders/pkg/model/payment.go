@@ -0,0 +1,90 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+// Payment operations recorded for every call to the payment provider.
+const (
+	PaymentAuthorize = "authorize"
+	PaymentCapture   = "capture"
+	PaymentRefund    = "refund"
+)
+
+// Payment is a single attempt to authorize, capture or refund money for an
+// order. Failed attempts are stored too, so the history can be audited.
+type Payment struct {
+	ID        int64       `json:"id"`
+	OrderID   int64       `json:"order_id"`
+	Operation string      `json:"operation"`
+	Amount    money.Money `json:"amount"`
+	Succeeded bool        `json:"succeeded"`
+	Reference string      `json:"reference,omitempty"`
+	CardLast4 string      `json:"card_last4,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Card holds the card details of a payment request. They are passed on to the
+// payment provider and never stored.
+type Card struct {
+	Holder   string `json:"holder"`
+	Number   string `json:"number"`
+	ExpMonth int    `json:"exp_month"`
+	ExpYear  int    `json:"exp_year"`
+	CVC      string `json:"cvc"`
+}
+
+var (
+	cardNumberRX = regexp.MustCompile(`^\d{12,19}$`)
+	cvcRX        = regexp.MustCompile(`^\d{3,4}$`)
+)
+
+// Normalize trims the holder name and strips spaces and dashes from the card
+// number.
+func (c *Card) Normalize() {
+	c.Holder = strings.TrimSpace(c.Holder)
+	c.Number = strings.NewReplacer(" ", "", "-", "").Replace(c.Number)
+	c.CVC = strings.TrimSpace(c.CVC)
+}
+
+// Last4 returns the last four digits of the card number.
+func (c *Card) Last4() string {
+	if len(c.Number) < 4 {
+		return c.Number
+	}
+	return c.Number[len(c.Number)-4:]
+}
+
+// Validate checks the format of the card details and returns the problems
+// keyed by the JSON field name. Whether the card is accepted is up to the
+// payment provider.
+func (c *Card) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if c.Holder == "" {
+		errs["holder"] = "must be provided"
+	}
+
+	if !cardNumberRX.MatchString(c.Number) {
+		errs["number"] = "must be 12 to 19 digits"
+	}
+
+	if c.ExpMonth < 1 || c.ExpMonth > 12 {
+		errs["exp_month"] = "must be between 1 and 12"
+	}
+
+	if c.ExpYear < 2000 || c.ExpYear > 2100 {
+		errs["exp_year"] = "must be a four-digit year"
+	}
+
+	if !cvcRX.MatchString(c.CVC) {
+		errs["cvc"] = "must be 3 or 4 digits"
+	}
+
+	return errs
+}
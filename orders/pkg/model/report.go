@@ -0,0 +1,121 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+// Report intervals. Weeks start on Monday; all periods are in UTC.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Top product rankings.
+const (
+	RankByQuantity = "quantity"
+	RankByRevenue  = "revenue"
+)
+
+// SoldStatuses are the statuses of orders that count as sales: paid and not
+// cancelled.
+var SoldStatuses = []string{StatusPaid, StatusProcessing, StatusPartiallyShipped, StatusShipped, StatusDelivered}
+
+func Sold(status string) bool {
+	return slices.Contains(SoldStatuses, status)
+}
+
+// ReportRange selects the orders placed in [From, To) in one currency.
+// Orders in other currencies are left out of the report.
+type ReportRange struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Currency string    `json:"currency"`
+}
+
+// MaxReportDays caps the length of a report range.
+const MaxReportDays = 731
+
+func (r *ReportRange) Validate() map[string]string {
+	errs := map[string]string{}
+
+	switch {
+	case !r.From.Before(r.To):
+		errs["to"] = "must be later than from"
+	case r.To.Sub(r.From) > MaxReportDays*24*time.Hour:
+		errs["to"] = "must be at most 731 days after from"
+	}
+
+	if r.Currency != "" && !money.ValidCurrency(r.Currency) {
+		errs["currency"] = "must be a three-letter ISO 4217 code"
+	}
+
+	return errs
+}
+
+// PeriodStart returns the start of the interval t falls in.
+func PeriodStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	y, m, d := t.Date()
+
+	switch interval {
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case IntervalWeek:
+		// Monday is the first day of the week.
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextPeriod returns the start of the interval after the one starting at t.
+func NextPeriod(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// SalesPeriod sums the sales of one interval.
+type SalesPeriod struct {
+	Start        time.Time   `json:"start"`
+	Orders       int64       `json:"orders"`
+	Revenue      money.Money `json:"revenue"`
+	AverageOrder money.Money `json:"average_order"`
+}
+
+// SalesReport is the sales of a range, in total and by interval. Every
+// interval of the range is listed, including those without sales.
+type SalesReport struct {
+	ReportRange
+	Interval     string         `json:"interval"`
+	Orders       int64          `json:"orders"`
+	Revenue      money.Money    `json:"revenue"`
+	AverageOrder money.Money    `json:"average_order"`
+	Periods      []*SalesPeriod `json:"periods"`
+}
+
+// ProductSales sums the sales of one product. Revenue is net of line
+// discounts.
+type ProductSales struct {
+	ItemID   int64       `json:"item_id"`
+	Name     string      `json:"name"`
+	Quantity int64       `json:"quantity"`
+	Revenue  money.Money `json:"revenue"`
+}
+
+// StatusSales counts the orders in one status and sums their totals.
+type StatusSales struct {
+	Status string      `json:"status"`
+	Orders int64       `json:"orders"`
+	Total  money.Money `json:"total"`
+}
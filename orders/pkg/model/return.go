@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+const (
+	ReturnRequested = "requested"
+	ReturnApproved  = "approved"
+	ReturnRejected  = "rejected"
+)
+
+// Return is a customer's request to send back some units of one order line.
+// An approved return refunds the units to the order's payment; the refund is
+// linked through RefundPaymentID.
+type Return struct {
+	ID              int64       `json:"id"`
+	OrderID         int64       `json:"order_id"`
+	ItemID          int64       `json:"item_id"`
+	Quantity        int32       `json:"quantity"`
+	Reason          string      `json:"reason"`
+	Status          string      `json:"status"`
+	StaffNote       string      `json:"staff_note,omitempty"`
+	RefundAmount    money.Money `json:"refund_amount"`
+	RefundPaymentID *int64      `json:"refund_payment_id,omitempty"`
+	Restocked       bool        `json:"restocked"`
+	CreatedAt       time.Time   `json:"created_at"`
+	ResolvedAt      *time.Time  `json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+// Sort orders of an order search. A leading dash sorts in descending order.
+const (
+	SortNewest  = "-created_at"
+	SortOldest  = "created_at"
+	SortHighest = "-total"
+	SortLowest  = "total"
+)
+
+// MaxOrderPageSize caps the number of orders in one page of a search.
+const MaxOrderPageSize = 100
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OrderFilter selects orders for staff. Zero fields do not filter. Totals
+// are compared only with orders in the currency of the bounds. Orders are
+// returned in Sort order, starting after the After cursor.
+type OrderFilter struct {
+	Status        string
+	UserID        int64
+	ProductID     int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	MinTotal      *money.Money
+	MaxTotal      *money.Money
+	Sort          string
+	After         *OrderCursor
+	Limit         int
+}
+
+func (f *OrderFilter) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if f.Status != "" && !ValidStatus(f.Status) {
+		errs["status"] = "invalid order status"
+	}
+	if f.UserID < 0 {
+		errs["user_id"] = "must be a positive integer"
+	}
+	if f.ProductID < 0 {
+		errs["product_id"] = "must be a positive integer"
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		errs["to"] = "must be later than from"
+	}
+	if f.MinTotal != nil && f.MaxTotal != nil && f.MinTotal.Amount > f.MaxTotal.Amount {
+		errs["max_total"] = "must not be less than min_total"
+	}
+
+	switch f.Sort {
+	case SortNewest, SortOldest, SortHighest, SortLowest:
+	default:
+		errs["sort"] = "must be one of created_at, -created_at, total, -total"
+	}
+
+	if f.Limit < 1 || f.Limit > MaxOrderPageSize {
+		errs["limit"] = "must be between 1 and 100"
+	}
+
+	if f.After != nil && f.After.Sort != f.Sort {
+		errs["cursor"] = "does not match the sort order"
+	}
+
+	return errs
+}
+
+// OrderCursor is the position of the last order of a page: its sort key and
+// its ID, which breaks ties.
+type OrderCursor struct {
+	Sort      string    `json:"s"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	Total     int64     `json:"t,omitempty"`
+	ID        int64     `json:"i"`
+}
+
+// CursorAfter returns the cursor positioned at the order.
+func CursorAfter(order *Order, sort string) *OrderCursor {
+	return &OrderCursor{Sort: sort, CreatedAt: order.CreatedAt, Total: order.Price.Amount, ID: order.ID}
+}
+
+// Encode returns the cursor as an opaque string.
+func (c *OrderCursor) Encode() string {
+	js, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// DecodeCursor reads a cursor returned by Encode.
+func DecodeCursor(s string) (*OrderCursor, error) {
+	js, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c OrderCursor
+	if err := json.Unmarshal(js, &c); err != nil || c.ID < 1 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// OrderPage is one page of an order search. NextCursor is empty on the last
+// page.
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
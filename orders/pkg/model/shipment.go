@@ -0,0 +1,132 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ShippableStatuses are the statuses an order can be shipped from: it has
+// been paid for and not everything has gone out yet.
+var ShippableStatuses = []string{StatusPaid, StatusProcessing, StatusPartiallyShipped}
+
+// Shipment is a parcel sent for an order. An order can go out in several
+// shipments, each carrying some units of some of its lines.
+type Shipment struct {
+	ID             int64          `json:"id"`
+	OrderID        int64          `json:"order_id"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number"`
+	Lines          []ShipmentLine `json:"lines"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// ShipmentLine is the number of units of one order line in a shipment.
+type ShipmentLine struct {
+	ItemID   int64 `json:"item_id"`
+	Quantity int32 `json:"quantity"`
+}
+
+// carrier describes a carrier orders are shipped with. Tracking is the
+// address of its tracking page, with %s for the tracking number.
+type carrier struct {
+	Name     string
+	Tracking string
+}
+
+var carriers = map[string]carrier{
+	"belpost": {Name: "Belpost", Tracking: "https://belpost.by/Otsleditotpravleniye?number=%s"},
+	"cdek":    {Name: "CDEK", Tracking: "https://www.cdek.ru/tracking?order_id=%s"},
+	"dhl":     {Name: "DHL", Tracking: "https://www.dhl.com/en/express/tracking.html?AWB=%s"},
+	"dpd":     {Name: "DPD", Tracking: "https://tracking.dpd.de/status/en_US/parcel/%s"},
+	"fedex":   {Name: "FedEx", Tracking: "https://www.fedex.com/fedextrack/?trknbr=%s"},
+	"ups":     {Name: "UPS", Tracking: "https://www.ups.com/track?tracknum=%s"},
+}
+
+// Carriers returns the codes of the carriers orders can be shipped with,
+// mapped to their names.
+func Carriers() map[string]string {
+	names := make(map[string]string, len(carriers))
+	for code, c := range carriers {
+		names[code] = c.Name
+	}
+	return names
+}
+
+// CarrierName returns the name of the carrier of the shipment.
+func (s *Shipment) CarrierName() string {
+	if c, ok := carriers[s.Carrier]; ok {
+		return c.Name
+	}
+	return s.Carrier
+}
+
+// TrackingURL returns the address of the carrier's tracking page for the
+// shipment.
+func (s *Shipment) TrackingURL() string {
+	c, ok := carriers[s.Carrier]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(c.Tracking, url.QueryEscape(s.TrackingNumber))
+}
+
+// Normalize lower-cases the carrier and upper-cases the tracking number,
+// and merges lines of the same item.
+func (s *Shipment) Normalize() {
+	s.Carrier = strings.ToLower(strings.TrimSpace(s.Carrier))
+	s.TrackingNumber = strings.ToUpper(strings.TrimSpace(s.TrackingNumber))
+
+	var lines []ShipmentLine
+	index := map[int64]int{}
+	for _, line := range s.Lines {
+		if i, ok := index[line.ItemID]; ok {
+			lines[i].Quantity += line.Quantity
+			continue
+		}
+		index[line.ItemID] = len(lines)
+		lines = append(lines, line)
+	}
+	s.Lines = lines
+}
+
+func (s *Shipment) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if _, ok := carriers[s.Carrier]; !ok {
+		errs["carrier"] = "is not supported"
+	}
+
+	switch {
+	case s.TrackingNumber == "":
+		errs["tracking_number"] = "must be provided"
+	case utf8.RuneCountInString(s.TrackingNumber) > 50:
+		errs["tracking_number"] = "must not be more than 50 characters long"
+	}
+
+	if len(s.Lines) == 0 {
+		errs["lines"] = "must not be empty"
+	}
+	for _, line := range s.Lines {
+		if line.ItemID < 1 || line.Quantity < 1 {
+			errs["lines"] = "must have an item and a quantity greater than zero"
+			break
+		}
+	}
+
+	return errs
+}
+
+// ShippedStatus returns the status of an order once the quantities have been
+// shipped of its lines: shipped when every unit has gone out, partially
+// shipped otherwise.
+func ShippedStatus(items []Item, shipped map[int64]int32) string {
+	for _, item := range items {
+		if shipped[item.ItemID] < item.Quantity {
+			return StatusPartiallyShipped
+		}
+	}
+	return StatusShipped
+}
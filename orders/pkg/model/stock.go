@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// StockRelease is stock held by a cancelled order that is to be given back
+// to the catalog. ReleasedAt is set once the catalog has taken it back.
+type StockRelease struct {
+	ID         int64      `json:"id"`
+	OrderID    int64      `json:"order_id"`
+	ItemID     int64      `json:"item_id"`
+	Quantity   int32      `json:"quantity"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
@@ -0,0 +1,24 @@
+package model
+
+// UserData is everything the orders service holds about a user, for data
+// access requests: their orders with the payments, returns and shipments of
+// each.
+type UserData struct {
+	UserID    int64       `json:"user_id"`
+	Orders    []*Order    `json:"orders"`
+	Payments  []*Payment  `json:"payments"`
+	Returns   []*Return   `json:"returns"`
+	Shipments []*Shipment `json:"shipments"`
+}
+
+// ErasedAddress is what the delivery address of an order is replaced with
+// when its user is erased. The country and region are kept, as the tax
+// charged depends on them.
+func ErasedAddress(address Address) Address {
+	return Address{Country: address.Country, Region: address.Region}
+}
+
+// ErasedComment replaces the body of the staff comments on the orders of an
+// erased user, which may mention the user by name or quote their messages.
+// Who wrote each comment and when stays.
+const ErasedComment = "[removed when the customer was erased]"
@@ -3,10 +3,29 @@ CREATE TABLE statuses (
     name TEXT NOT NULL UNIQUE
 );
 
+INSERT INTO statuses (name) VALUES
+    ('created'),
+    ('paid'),
+    ('processing'),
+    ('partially_shipped'),
+    ('shipped'),
+    ('delivered'),
+    ('cancelled');
+
 CREATE TABLE orders (
     id BIGSERIAL PRIMARY KEY,
     user_id BIGINT NOT NULL,
-    total_price NUMERIC(10, 2) NOT NULL,
+    total_price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'BYN',
+    subtotal BIGINT NOT NULL DEFAULT 0,
+    discount BIGINT NOT NULL DEFAULT 0,
+    shipping BIGINT NOT NULL DEFAULT 0,
+    tax BIGINT NOT NULL DEFAULT 0,
+    tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    display_currency CHAR(3) NOT NULL DEFAULT 'BYN',
+    display_rate NUMERIC(18, 8) NOT NULL DEFAULT 1,
+    note TEXT NOT NULL DEFAULT '',
+    gift_message TEXT NOT NULL DEFAULT '',
     status_id INTEGER NOT NULL REFERENCES statuses(id) ON DELETE RESTRICT,
     created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW()
 );
@@ -14,6 +33,146 @@ CREATE TABLE orders (
 CREATE TABLE order_items (
     order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
     item_id BIGINT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
     quantity INTEGER NOT NULL CHECK (quantity > 0),
+    price BIGINT NOT NULL DEFAULT 0,
+    category_id BIGINT NOT NULL DEFAULT 0,
+    discount BIGINT NOT NULL DEFAULT 0,
     PRIMARY KEY (order_id, item_id)
-);
\ No newline at end of file
+);
+CREATE TABLE order_addresses (
+    order_id BIGINT PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    line1 TEXT NOT NULL,
+    line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country CHAR(2) NOT NULL,
+    phone TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE payments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    operation TEXT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'BYN',
+    succeeded BOOLEAN NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    card_last4 TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX payments_order_id_idx ON payments(order_id);
+
+CREATE TABLE returns (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    item_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'requested',
+    staff_note TEXT NOT NULL DEFAULT '',
+    refund_amount BIGINT NOT NULL DEFAULT 0,
+    refund_currency CHAR(3) NOT NULL DEFAULT 'BYN',
+    refund_payment_id BIGINT REFERENCES payments(id) ON DELETE SET NULL,
+    restocked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP(0) with time zone,
+    FOREIGN KEY (order_id, item_id) REFERENCES order_items(order_id, item_id) ON DELETE CASCADE
+);
+
+CREATE INDEX returns_order_id_idx ON returns(order_id);
+CREATE INDEX returns_status_idx ON returns(status);
+
+CREATE TABLE coupons (
+    id BIGSERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value BIGINT NOT NULL CHECK (value > 0),
+    min_basket BIGINT NOT NULL DEFAULT 0,
+    category_ids BIGINT[] NOT NULL DEFAULT '{}',
+    product_ids BIGINT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP(0) with time zone,
+    usage_limit INTEGER NOT NULL DEFAULT 0,
+    per_user_limit INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE order_promotions (
+    order_id BIGINT PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    coupon_id BIGINT NOT NULL REFERENCES coupons(id) ON DELETE RESTRICT,
+    code TEXT NOT NULL,
+    discount BIGINT NOT NULL
+);
+
+CREATE INDEX order_promotions_coupon_id_idx ON order_promotions(coupon_id);
+
+CREATE TABLE invoice_counters (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+CREATE TABLE invoices (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE RESTRICT,
+    number TEXT NOT NULL UNIQUE,
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    issued_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (year, sequence)
+);
+
+CREATE INDEX orders_status_id_created_at_idx ON orders(status_id, created_at);
+
+CREATE TABLE stock_releases (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    item_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW(),
+    released_at TIMESTAMP(0) with time zone
+);
+
+CREATE INDEX stock_releases_pending_idx ON stock_releases(id) WHERE released_at IS NULL;
+
+CREATE INDEX orders_created_at_id_idx ON orders(created_at, id);
+CREATE INDEX orders_currency_total_price_id_idx ON orders(currency, total_price, id);
+CREATE INDEX orders_user_id_created_at_idx ON orders(user_id, created_at, id);
+CREATE INDEX order_items_item_id_idx ON order_items(item_id);
+
+CREATE TABLE order_comments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL,
+    author TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX order_comments_order_id_idx ON order_comments(order_id, id);
+
+CREATE TABLE shipments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    carrier TEXT NOT NULL,
+    tracking_number TEXT NOT NULL,
+    created_at TIMESTAMP(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX shipments_order_id_idx ON shipments(order_id);
+
+CREATE TABLE shipment_lines (
+    shipment_id BIGINT NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    order_id BIGINT NOT NULL,
+    item_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (shipment_id, item_id),
+    FOREIGN KEY (order_id, item_id) REFERENCES order_items(order_id, item_id) ON DELETE CASCADE
+);
+
+CREATE INDEX shipment_lines_order_id_idx ON shipment_lines(order_id);
@@ -3,15 +3,22 @@ package main
 import (
 	"log"
 
+	"github.com/Maksim-Kot/Commons/discovery/cached"
 	"github.com/Maksim-Kot/Commons/discovery/consul"
+	catalogmodel "github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
 	"github.com/Maksim-Kot/Tech-store-web/config"
+	addresscontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/address"
+	cartcontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/cart"
 	catalogcontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/catalog"
 	orderscontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/orders"
 	usercontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/user"
 	controller "github.com/Maksim-Kot/Tech-store-web/internal/controller/web"
+	wishlistcontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/wishlist"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
 	cataloggateway "github.com/Maksim-Kot/Tech-store-web/internal/gateway/catalog/http"
 	ordersgateway "github.com/Maksim-Kot/Tech-store-web/internal/gateway/orders/http"
 	httphandler "github.com/Maksim-Kot/Tech-store-web/internal/handler/http"
+	"github.com/Maksim-Kot/Tech-store-web/internal/notify"
 	"github.com/Maksim-Kot/Tech-store-web/internal/repository/mysql"
 	httpserver "github.com/Maksim-Kot/Tech-store-web/internal/server/http"
 	"github.com/Maksim-Kot/Tech-store-web/internal/session"
@@ -28,8 +35,20 @@ func main() {
 		log.Fatal(err)
 	}
 
-	cataloggateway := cataloggateway.New(registry)
-	ordersgateway := ordersgateway.New(registry)
+	// The gateways look services up on every request, so they get the
+	// addresses from memory instead of asking Consul each time.
+	addresses := cached.New(registry)
+	defer addresses.Close()
+
+	cataloggateway, err := cataloggateway.New(addresses, cfg.Gateways.Catalog)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ordersgateway, err := ordersgateway.New(addresses, cfg.Gateways.Orders)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	repo, err := mysql.New(cfg.Database)
 	if err != nil {
@@ -43,13 +62,38 @@ func main() {
 		log.Fatal(err)
 	}
 
+	base := cfg.Currency.Base
+	if base == "" {
+		base = catalogmodel.DefaultCurrency
+	}
+
+	currencies, err := currency.New(base, cfg.Currency.Rates)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mailer, err := notify.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	catalogController := catalogcontroller.New(cataloggateway)
-	ordersController := orderscontroller.New(ordersgateway)
 	userController := usercontroller.New(repo)
 
-	ctrl := controller.New(catalogController, ordersController, userController)
+	notifier, err := notify.New(mailer, ordersgateway, userController, cfg.Mail.BaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer notifier.Close()
+
+	ordersController := orderscontroller.New(ordersgateway, notifier)
+	cartController := cartcontroller.New(repo, catalogController, currencies)
+	wishlistController := wishlistcontroller.New(repo, catalogController, currencies)
+	addressController := addresscontroller.New(repo)
+
+	ctrl := controller.New(catalogController, ordersController, userController, cartController, wishlistController, addressController)
 
-	h, err := httphandler.New(ctrl, sessionManager)
+	h, err := httphandler.New(ctrl, sessionManager, currencies)
 	if err != nil {
 		log.Fatal(err)
 	}
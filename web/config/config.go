@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 
+	"github.com/Maksim-Kot/Commons/httputil"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -10,6 +12,9 @@ type Config struct {
 	Api      APIConfig      `yaml:"api"`
 	Database DatabaseConfig `yaml:"database"`
 	Session  SessionConfig  `yaml:"session"`
+	Currency CurrencyConfig `yaml:"currency"`
+	Mail     MailConfig     `yaml:"mail"`
+	Gateways GatewaysConfig `yaml:"gateways"`
 }
 
 type APIConfig struct {
@@ -30,6 +35,39 @@ type SessionConfig struct {
 	Lifetime string `yaml:"lifetime"`
 }
 
+// CurrencyConfig lists the currencies shoppers can see prices in. Base is
+// the currency of the catalog and orders; every rate is how many units of a
+// currency one base unit buys.
+type CurrencyConfig struct {
+	Base  string             `yaml:"base"`
+	Rates map[string]float64 `yaml:"rates"`
+}
+
+// MailConfig says how emails are sent. Sink is "smtp", "file", which writes
+// every email to an .eml file in Dir for development, or "log", the default,
+// which only logs them. BaseURL is the address of the site used in links.
+type MailConfig struct {
+	Sink    string     `yaml:"sink"`
+	From    string     `yaml:"from"`
+	Dir     string     `yaml:"dir"`
+	BaseURL string     `yaml:"baseURL"`
+	SMTP    SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// GatewaysConfig says how requests to each service are balanced over its
+// instances.
+type GatewaysConfig struct {
+	Catalog httputil.BalancerConfig `yaml:"catalog"`
+	Orders  httputil.BalancerConfig `yaml:"orders"`
+}
+
 func New(path string) (*Config, error) {
 	f, err := os.Open(path)
 	if err != nil {
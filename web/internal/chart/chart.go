@@ -0,0 +1,141 @@
+// Package chart draws simple bar charts as inline SVG, so pages can show
+// them without any script. Charts scale to the width of their container.
+package chart
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Bar is one bar of a chart. Text is the value as it is shown to the user.
+type Bar struct {
+	Label string
+	Value float64
+	Text  string
+}
+
+// Chart dimensions, in SVG units.
+const (
+	width     = 720.0
+	height    = 240.0
+	axisWidth = 64.0
+	labelRoom = 28.0
+	topRoom   = 12.0
+	rowHeight = 24.0
+	nameWidth = 200.0
+	textRoom  = 96.0
+	maxLabels = 12
+	gridLines = 4
+)
+
+// Columns draws the bars as vertical columns over an axis, for a series over
+// time. Only some of the labels are shown when there are many bars; axis
+// formats the values of the grid lines.
+func Columns(bars []Bar, axis func(float64) string) template.HTML {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %g %g" role="img">`, width, height)
+
+	top, step := scale(bars)
+	plot := height - labelRoom - topRoom
+	base := height - labelRoom
+
+	for i := 0; i <= gridLines; i++ {
+		value := step * float64(i)
+		y := base - plot*value/top
+		fmt.Fprintf(&b, `<line class="grid" x1="%g" y1="%.1f" x2="%g" y2="%.1f"/>`, axisWidth, y, width, y)
+		fmt.Fprintf(&b, `<text class="axis" x="%g" y="%.1f" text-anchor="end">%s</text>`, axisWidth-6, y+4, esc(axis(value)))
+	}
+
+	if len(bars) > 0 {
+		slot := (width - axisWidth) / float64(len(bars))
+		every := (len(bars) + maxLabels - 1) / maxLabels
+
+		for i, bar := range bars {
+			x := axisWidth + slot*float64(i)
+			h := plot * bar.Value / top
+
+			fmt.Fprintf(&b, `<rect class="bar" x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%s: %s</title></rect>`,
+				x+slot*0.1, base-h, slot*0.8, h, esc(bar.Label), esc(bar.Text))
+
+			if i%every == 0 {
+				fmt.Fprintf(&b, `<text class="label" x="%.1f" y="%g" text-anchor="middle">%s</text>`, x+slot/2, height-8, esc(bar.Label))
+			}
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Rows draws the bars as horizontal rows, with the label on the left and
+// the value on the right, for a ranking.
+func Rows(bars []Bar) template.HTML {
+	var b strings.Builder
+
+	h := rowHeight * float64(max(len(bars), 1))
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %g %g" role="img">`, width, h)
+
+	top := 0.0
+	for _, bar := range bars {
+		top = max(top, bar.Value)
+	}
+
+	room := width - nameWidth - textRoom
+	for i, bar := range bars {
+		y := rowHeight * float64(i)
+
+		w := 0.0
+		if top > 0 {
+			w = room * bar.Value / top
+		}
+
+		fmt.Fprintf(&b, `<text class="label" x="%g" y="%.1f" text-anchor="end">%s</text>`, nameWidth-8, y+16, esc(truncate(bar.Label, 28)))
+		fmt.Fprintf(&b, `<rect class="bar" x="%g" y="%.1f" width="%.1f" height="%g"><title>%s: %s</title></rect>`,
+			nameWidth, y+4, w, rowHeight-8, esc(bar.Label), esc(bar.Text))
+		fmt.Fprintf(&b, `<text class="value" x="%.1f" y="%.1f">%s</text>`, nameWidth+w+6, y+16, esc(bar.Text))
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// scale returns the top of the value axis and the step between its grid
+// lines, both round numbers.
+func scale(bars []Bar) (top, step float64) {
+	peak := 0.0
+	for _, bar := range bars {
+		peak = max(peak, bar.Value)
+	}
+	if peak <= 0 {
+		return gridLines, 1
+	}
+
+	// The step is 1, 2 or 5 times a power of ten.
+	raw := peak / gridLines
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	step = 10 * magnitude
+	for _, m := range []float64{1, 2, 5} {
+		if m*magnitude >= raw {
+			step = m * magnitude
+			break
+		}
+	}
+
+	return step * gridLines, step
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
@@ -2,4 +2,7 @@ package contexkeys
 
 type contextKey string
 
-const IsAuthenticatedContextKey = contextKey("isAuthenticated")
+const (
+	IsAuthenticatedContextKey = contextKey("isAuthenticated")
+	UserRoleContextKey        = contextKey("userRole")
+)
@@ -0,0 +1,118 @@
+package address
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+type addressRepo interface {
+	Addresses(ctx context.Context, userID int64) ([]*model.Address, error)
+	Address(ctx context.Context, userID, id int64) (*model.Address, error)
+	InsertAddress(ctx context.Context, address *model.Address) error
+	UpdateAddress(ctx context.Context, address *model.Address) error
+	DeleteAddress(ctx context.Context, userID, id int64) error
+	SetDefaultAddress(ctx context.Context, userID, id int64) error
+}
+
+type AddressController struct {
+	addressRepo addressRepo
+}
+
+func New(addressRepo addressRepo) *AddressController {
+	return &AddressController{addressRepo: addressRepo}
+}
+
+// Addresses returns the address book of the user with the default address
+// first.
+func (c *AddressController) Addresses(ctx context.Context, userID int64) ([]*model.Address, error) {
+	return c.addressRepo.Addresses(ctx, userID)
+}
+
+func (c *AddressController) Address(ctx context.Context, userID, id int64) (*model.Address, error) {
+	address, err := c.addressRepo.Address(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, controller.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// Create adds the address to the user's address book. The first address a
+// user saves becomes the default one.
+func (c *AddressController) Create(ctx context.Context, address *model.Address) error {
+	if !address.IsDefault {
+		addresses, err := c.addressRepo.Addresses(ctx, address.UserID)
+		if err != nil {
+			return err
+		}
+		address.IsDefault = len(addresses) == 0
+	}
+
+	return c.addressRepo.InsertAddress(ctx, address)
+}
+
+func (c *AddressController) Update(ctx context.Context, address *model.Address) error {
+	err := c.addressRepo.UpdateAddress(ctx, address)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	if address.IsDefault {
+		return c.SetDefault(ctx, address.UserID, address.ID)
+	}
+
+	return nil
+}
+
+// Delete removes the address. When the default address is removed, the most
+// recently added remaining address becomes the default.
+func (c *AddressController) Delete(ctx context.Context, userID, id int64) error {
+	address, err := c.Address(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.addressRepo.DeleteAddress(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	if !address.IsDefault {
+		return nil
+	}
+
+	addresses, err := c.addressRepo.Addresses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	return c.addressRepo.SetDefaultAddress(ctx, userID, addresses[0].ID)
+}
+
+func (c *AddressController) SetDefault(ctx context.Context, userID, id int64) error {
+	err := c.addressRepo.SetDefaultAddress(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
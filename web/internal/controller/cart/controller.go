@@ -0,0 +1,152 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	catalogmodel "github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+type cartRepo interface {
+	Cart(ctx context.Context, userID int64) (*model.Cart, error)
+	AddCartItem(ctx context.Context, userID int64, item model.Item) error
+	SetCartItemQuantity(ctx context.Context, userID, productID int64, quantity int32) error
+	RemoveCartItem(ctx context.Context, userID, productID int64) error
+	ClearCart(ctx context.Context, userID int64) error
+	ReplaceCart(ctx context.Context, cart *model.Cart) error
+}
+
+type catalog interface {
+	ProductsByIDs(ctx context.Context, ids []int64) (map[int64]*catalogmodel.Product, []int64, error)
+}
+
+type CartController struct {
+	cartRepo   cartRepo
+	catalog    catalog
+	currencies *currency.Table
+}
+
+func New(cartRepo cartRepo, catalog catalog, currencies *currency.Table) *CartController {
+	return &CartController{cartRepo: cartRepo, catalog: catalog, currencies: currencies}
+}
+
+func (c *CartController) Cart(ctx context.Context, userID int64) (*model.Cart, error) {
+	return c.cartRepo.Cart(ctx, userID)
+}
+
+func (c *CartController) AddItem(ctx context.Context, userID int64, item model.Item) error {
+	return c.cartRepo.AddCartItem(ctx, userID, item)
+}
+
+// SetQuantity changes the quantity of an item already in the cart. A
+// quantity below one removes the item.
+func (c *CartController) SetQuantity(ctx context.Context, userID, productID int64, quantity int32) error {
+	var err error
+	if quantity < 1 {
+		err = c.cartRepo.RemoveCartItem(ctx, userID, productID)
+	} else {
+		err = c.cartRepo.SetCartItemQuantity(ctx, userID, productID, quantity)
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (c *CartController) RemoveItem(ctx context.Context, userID, productID int64) error {
+	err := c.cartRepo.RemoveCartItem(ctx, userID, productID)
+
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (c *CartController) Clear(ctx context.Context, userID int64) error {
+	return c.cartRepo.ClearCart(ctx, userID)
+}
+
+// Merge adds the items of an anonymous session cart to the user's stored
+// cart, summing quantities of products that are in both.
+func (c *CartController) Merge(ctx context.Context, userID int64, cart *model.Cart) error {
+	for _, item := range cart.Items {
+		if err := c.cartRepo.AddCartItem(ctx, userID, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check compares every line of the cart with the current product data from
+// the catalog, which is looked up in one round trip. Lines of products the
+// catalog reports missing are marked as such. Current prices are converted
+// to the base currency the cart is kept in; a product priced in a currency
+// without a rate cannot be sold and is treated as missing too.
+func (c *CartController) Check(ctx context.Context, cart *model.Cart) (*model.CheckedCart, error) {
+	lines := make([]*model.CartLine, 0, len(cart.Items))
+
+	ids := make([]int64, 0, len(cart.Items))
+	for id := range cart.Items {
+		ids = append(ids, id)
+	}
+
+	products, missing, err := c.catalog.ProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range cart.Items {
+		line := &model.CartLine{Item: item}
+
+		product, ok := products[item.ID]
+		switch {
+		case slices.Contains(missing, item.ID):
+			line.Missing = true
+		case !ok:
+			return nil, fmt.Errorf("product %d: not returned by the catalog", item.ID)
+		default:
+			price, err := c.currencies.Convert(product.Price, c.currencies.Base())
+			if errors.Is(err, currency.ErrUnknownCurrency) {
+				log.Printf("[cart] product %d is priced in %s, which has no rate", product.ID, product.Price.Currency)
+				line.Missing = true
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("product %d: %w", product.ID, err)
+			}
+
+			line.CurrentPrice = price
+			line.CategoryID = product.CategoryID
+			line.Weight = product.Weight
+			line.Available = product.Quantity
+			line.PriceChanged = item.Price != price
+			line.Capped = product.Quantity > 0 && item.Quantity > product.Quantity
+		}
+
+		lines = append(lines, line)
+	}
+
+	return model.NewCheckedCart(cart.UserID, lines), nil
+}
+
+// Accept stores the cart with every change of the checked cart applied.
+func (c *CartController) Accept(ctx context.Context, checked *model.CheckedCart) error {
+	return c.cartRepo.ReplaceCart(ctx, checked.Accepted())
+}
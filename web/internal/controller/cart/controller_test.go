@@ -0,0 +1,99 @@
+package cart
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Maksim-Kot/Commons/money"
+	catalogmodel "github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// fakeCatalog answers lookups from a fixed set of products and reports the
+// other IDs as missing, as the catalog service does.
+type fakeCatalog map[int64]*catalogmodel.Product
+
+func (f fakeCatalog) ProductsByIDs(_ context.Context, ids []int64) (map[int64]*catalogmodel.Product, []int64, error) {
+	products := map[int64]*catalogmodel.Product{}
+	missing := []int64{}
+	for _, id := range ids {
+		if product, ok := f[id]; ok {
+			products[id] = product
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return products, missing, nil
+}
+
+func byn(amount int64) money.Money {
+	return money.New(amount, "BYN")
+}
+
+func TestCheck(t *testing.T) {
+	currencies, err := currency.New("BYN", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The product IDs are far apart and larger than the number of lines, as
+	// in a real catalog. There is no rate for USD, so product 13 cannot be
+	// sold.
+	catalog := fakeCatalog{
+		5:    {ID: 5, Price: byn(1000), Quantity: 10},
+		13:   {ID: 13, Price: money.New(500, "USD"), Quantity: 4},
+		42:   {ID: 42, Price: byn(2500), Quantity: 3},
+		9001: {ID: 9001, Price: byn(700), Quantity: 0},
+	}
+
+	c := New(nil, catalog, currencies)
+
+	cart := &model.Cart{UserID: 1, Items: map[int64]model.Item{
+		5:    {ID: 5, Quantity: 2, Price: byn(1000)},
+		13:   {ID: 13, Quantity: 1, Price: byn(1500)},
+		42:   {ID: 42, Quantity: 5, Price: byn(2000)},
+		777:  {ID: 777, Quantity: 1, Price: byn(300)},
+		9001: {ID: 9001, Quantity: 1, Price: byn(700)},
+	}}
+
+	checked, err := c.Check(context.Background(), cart)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	want := []model.CartLine{
+		{Item: model.Item{ID: 5, Quantity: 2, Price: byn(1000)}, CurrentPrice: byn(1000), Available: 10},
+		{Item: model.Item{ID: 13, Quantity: 1, Price: byn(1500)}, Missing: true},
+		{Item: model.Item{ID: 42, Quantity: 5, Price: byn(2000)}, CurrentPrice: byn(2500), Available: 3, PriceChanged: true, Capped: true},
+		{Item: model.Item{ID: 777, Quantity: 1, Price: byn(300)}, Missing: true},
+		{Item: model.Item{ID: 9001, Quantity: 1, Price: byn(700)}, CurrentPrice: byn(700)},
+	}
+
+	got := make([]model.CartLine, len(checked.Lines))
+	for i, line := range checked.Lines {
+		got[i] = *line
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Check lines =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCheckEmpty(t *testing.T) {
+	currencies, err := currency.New("BYN", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(nil, fakeCatalog{}, currencies)
+
+	checked, err := c.Check(context.Background(), &model.Cart{UserID: 1, Items: map[int64]model.Item{}})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(checked.Lines) != 0 {
+		t.Errorf("Check of an empty cart has %d lines", len(checked.Lines))
+	}
+}
@@ -0,0 +1,63 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// Reorder adds the lines of a past order to the user's cart at current
+// prices. Quantities are capped to the stock not already in the cart;
+// products that are gone or sold out are left out and reported.
+func (c *CartController) Reorder(ctx context.Context, userID int64, lines []model.Item) (*model.Reorder, error) {
+	cart, err := c.cartRepo.Cart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(lines))
+	for i, line := range lines {
+		ids[i] = line.ID
+	}
+
+	products, _, err := c.catalog.ProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Reorder{}
+
+	for _, line := range lines {
+		product, ok := products[line.ID]
+		if !ok {
+			result.Gone = append(result.Gone, line.Name)
+			continue
+		}
+
+		available := product.Quantity - cart.Items[line.ID].Quantity
+		if available <= 0 {
+			result.SoldOut = append(result.SoldOut, product.Name)
+			continue
+		}
+
+		quantity := line.Quantity
+		if quantity > available {
+			quantity = available
+			result.Capped = append(result.Capped, product.Name)
+		}
+
+		price, err := c.currencies.Convert(product.Price, c.currencies.Base())
+		if err != nil {
+			return nil, fmt.Errorf("product %d: %w", product.ID, err)
+		}
+
+		item := model.Item{ID: product.ID, Name: product.Name, Quantity: quantity, Price: price}
+		if err := c.cartRepo.AddCartItem(ctx, userID, item); err != nil {
+			return nil, err
+		}
+		result.Added++
+	}
+
+	return result, nil
+}
@@ -13,8 +13,13 @@ type catalogGateway interface {
 	Catalog(ctx context.Context) ([]*model.Category, error)
 	ProductsByCategoryID(ctx context.Context, id int64) ([]*model.Product, error)
 	ProductByID(ctx context.Context, id int64) (*model.Product, error)
+	ProductsByIDs(ctx context.Context, ids []int64) ([]*model.Product, []int64, error)
 	DecreaseProductQuantity(ctx context.Context, id int64, amount int32) error
 	IncreaseProductQuantity(ctx context.Context, id int64, amount int32) error
+	PutCategory(ctx context.Context, category *model.Category) error
+	UpdateCategory(ctx context.Context, category *model.Category) error
+	PutProduct(ctx context.Context, product *model.Product) error
+	UpdateProduct(ctx context.Context, product *model.Product, originalQuantity int32) error
 }
 
 type CatalogController struct {
@@ -60,6 +65,23 @@ func (c *CatalogController) ProductByID(ctx context.Context, id int64) (*model.P
 	return product, nil
 }
 
+// ProductsByIDs looks up the products with the IDs in one round trip. It
+// returns the products by ID and the IDs of the products that do not exist,
+// as the catalog reported them.
+func (c *CatalogController) ProductsByIDs(ctx context.Context, ids []int64) (map[int64]*model.Product, []int64, error) {
+	found, missing, err := c.catalogGateway.ProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products := make(map[int64]*model.Product, len(found))
+	for _, product := range found {
+		products[product.ID] = product
+	}
+
+	return products, missing, nil
+}
+
 func (c *CatalogController) DecreaseProductQuantity(ctx context.Context, id int64, amount int32) error {
 	err := c.catalogGateway.DecreaseProductQuantity(ctx, id, amount)
 
@@ -91,3 +113,61 @@ func (c *CatalogController) IncreaseProductQuantity(ctx context.Context, id int6
 
 	return nil
 }
+
+// CategoryByID looks the category up in the full catalog, since the catalog
+// service does not expose single categories.
+func (c *CatalogController) CategoryByID(ctx context.Context, id int64) (*model.Category, error) {
+	categories, err := c.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.ID == id {
+			return category, nil
+		}
+	}
+
+	return nil, controller.ErrNotFound
+}
+
+func (c *CatalogController) PutCategory(ctx context.Context, category *model.Category) error {
+	return c.catalogGateway.PutCategory(ctx, category)
+}
+
+func (c *CatalogController) UpdateCategory(ctx context.Context, category *model.Category) error {
+	err := c.catalogGateway.UpdateCategory(ctx, category)
+
+	if err != nil {
+		if errors.Is(err, gateway.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (c *CatalogController) PutProduct(ctx context.Context, product *model.Product) error {
+	return c.catalogGateway.PutProduct(ctx, product)
+}
+
+// UpdateProduct saves the product if its stock is still originalQuantity,
+// the stock shown in the editor. Otherwise it returns ErrEditConflict, so an
+// order placed meanwhile is not undone by the edit.
+func (c *CatalogController) UpdateProduct(ctx context.Context, product *model.Product, originalQuantity int32) error {
+	err := c.catalogGateway.UpdateProduct(ctx, product, originalQuantity)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrNotFound):
+			return controller.ErrNotFound
+		case errors.Is(err, gateway.ErrEditConflict):
+			return controller.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
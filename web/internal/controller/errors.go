@@ -8,4 +8,10 @@ var (
 	ErrDuplicateEmail     = errors.New("duplicate email")
 	ErrNotEnough          = errors.New("not enough")
 	ErrEditConflict       = errors.New("edit conflict")
+	ErrInvalidCode        = errors.New("invalid verification code")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrDuplicateName      = errors.New("duplicate name")
+	ErrPaymentDeclined    = errors.New("payment declined")
+	ErrPaymentUnavailable = errors.New("payment unavailable")
+	ErrInvalidCoupon      = errors.New("invalid coupon")
 )
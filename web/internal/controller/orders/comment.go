@@ -0,0 +1,27 @@
+package orders
+
+import (
+	"context"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// CreateComment adds an internal comment to the order. Only staff may read
+// or write comments; checking that is left to the caller.
+func (c *OrdersController) CreateComment(ctx context.Context, comment *ordersmodel.Comment) (*ordersmodel.Comment, error) {
+	created, err := c.ordersGateway.CreateComment(ctx, comment)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return created, nil
+}
+
+func (c *OrdersController) Comments(ctx context.Context, orderID int64) ([]*ordersmodel.Comment, error) {
+	comments, err := c.ordersGateway.Comments(ctx, orderID)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return comments, nil
+}
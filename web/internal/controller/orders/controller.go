@@ -3,6 +3,7 @@ package orders
 import (
 	"context"
 	"errors"
+	"net/url"
 
 	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
 	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
@@ -12,16 +13,50 @@ import (
 
 type ordersGateway interface {
 	OrderByID(ctx context.Context, id int64) (*ordersmodel.Order, error)
-	OrdersByUserID(ctx context.Context, id int64) ([]*ordersmodel.Order, error)
-	CreateOrder(ctx context.Context, userID int64, price float64, items []*ordersmodel.Item) (int64, error)
+	OrdersByUserID(ctx context.Context, id int64, status, cursor string) (*ordersmodel.OrderPage, error)
+	CreateOrder(ctx context.Context, userID int64, items []*ordersmodel.Item, address *ordersmodel.Address, coupon string, display ordersmodel.Display, notes ordersmodel.Notes) (int64, error)
+	Orders(ctx context.Context, cursor string) (*ordersmodel.OrderPage, error)
+	UpdateOrderStatus(ctx context.Context, id int64, status string) error
+	PayOrder(ctx context.Context, id int64, card ordersmodel.Card) (*ordersmodel.Payment, error)
+	Payments(ctx context.Context, id int64) ([]*ordersmodel.Payment, error)
+	CreateReturn(ctx context.Context, orderID, itemID int64, quantity int32, reason string) (*ordersmodel.Return, error)
+	OrderReturns(ctx context.Context, orderID int64) ([]*ordersmodel.Return, error)
+	Returns(ctx context.Context, status string) ([]*ordersmodel.Return, error)
+	Return(ctx context.Context, id int64) (*ordersmodel.Return, error)
+	ResolveReturn(ctx context.Context, id int64, action, note string) (*ordersmodel.Return, error)
+	MarkReturnRestocked(ctx context.Context, id int64) (*ordersmodel.Return, error)
+	UnmarkReturnRestocked(ctx context.Context, id int64) (*ordersmodel.Return, error)
+	Quote(ctx context.Context, userID int64, items []*ordersmodel.Item, address *ordersmodel.Address, coupon string) (*ordersmodel.Quote, error)
+	Coupons(ctx context.Context) ([]*ordersmodel.Coupon, error)
+	CreateCoupon(ctx context.Context, coupon *ordersmodel.Coupon) (*ordersmodel.Coupon, error)
+	SetCouponActive(ctx context.Context, id int64, active bool) (*ordersmodel.Coupon, error)
+	Invoice(ctx context.Context, orderID int64) (*ordersmodel.Invoice, error)
+	SalesReport(ctx context.Context, query url.Values) (*ordersmodel.SalesReport, error)
+	TopProducts(ctx context.Context, query url.Values) ([]*ordersmodel.ProductSales, error)
+	StatusReport(ctx context.Context, query url.Values) ([]*ordersmodel.StatusSales, error)
+	ReportCSV(ctx context.Context, name string, query url.Values) ([]byte, error)
+	CreateComment(ctx context.Context, comment *ordersmodel.Comment) (*ordersmodel.Comment, error)
+	Comments(ctx context.Context, orderID int64) ([]*ordersmodel.Comment, error)
+	CreateShipment(ctx context.Context, shipment *ordersmodel.Shipment) (*ordersmodel.Shipment, string, error)
+	Shipments(ctx context.Context, orderID int64) ([]*ordersmodel.Shipment, error)
+	UserData(ctx context.Context, userID int64) ([]byte, error)
+	EraseUser(ctx context.Context, userID int64) (int64, error)
+}
+
+// Listener is told about orders placed and order status changes made
+// through the controller, once they have succeeded. It must not block.
+type Listener interface {
+	OrderPlaced(orderID int64)
+	OrderStatusChanged(orderID int64, status string)
 }
 
 type OrdersController struct {
 	ordersGateway ordersGateway
+	listener      Listener
 }
 
-func New(ordersGateway ordersGateway) *OrdersController {
-	return &OrdersController{ordersGateway: ordersGateway}
+func New(ordersGateway ordersGateway, listener Listener) *OrdersController {
+	return &OrdersController{ordersGateway: ordersGateway, listener: listener}
 }
 
 func (c *OrdersController) OrderByID(ctx context.Context, id int64) (*ordersmodel.Order, error) {
@@ -37,32 +72,138 @@ func (c *OrdersController) OrderByID(ctx context.Context, id int64) (*ordersmode
 	return order, nil
 }
 
-func (c *OrdersController) OrdersByUserID(ctx context.Context, id int64) ([]*ordersmodel.Order, error) {
-	orders, err := c.ordersGateway.OrdersByUserID(ctx, id)
+// OrdersByUserID returns a page of the user's orders, newest first, in the
+// status if it is not empty. The cursor of the next page is in the page; an
+// invalid cursor gives ErrInvalidInput.
+func (c *OrdersController) OrdersByUserID(ctx context.Context, id int64, status, cursor string) (*ordersmodel.OrderPage, error) {
+	page, err := c.ordersGateway.OrdersByUserID(ctx, id, status, cursor)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return page, nil
+}
 
+// CreateOrder places the order. The orders service works out its price. An
+// empty coupon places it without a discount; a coupon the orders service
+// refuses gives ErrInvalidCoupon. The display currency is recorded so the
+// order is shown the way the shopper saw it, along with the notes.
+func (c *OrdersController) CreateOrder(ctx context.Context, userID int64, items []*model.Item, address *ordersmodel.Address, coupon string, display ordersmodel.Display, notes ordersmodel.Notes) (int64, error) {
+	id, err := c.ordersGateway.CreateOrder(ctx, userID, orderItems(items), address, coupon, display, notes)
 	if err != nil {
-		if errors.Is(err, gateway.ErrNotFound) {
-			return nil, controller.ErrNotFound
+		switch {
+		case errors.Is(err, gateway.ErrInvalidCoupon):
+			return 0, controller.ErrInvalidCoupon
+		case errors.Is(err, gateway.ErrInvalidInput):
+			return 0, controller.ErrInvalidInput
+		default:
+			return 0, err
+		}
+	}
+
+	c.listener.OrderPlaced(id)
+
+	return id, nil
+}
+
+// Quote asks the orders service what the order would cost with the address
+// and coupon. A coupon that cannot be used gives a quote with an invalid
+// coupon and the reason.
+func (c *OrdersController) Quote(ctx context.Context, userID int64, items []*model.Item, address *ordersmodel.Address, coupon string) (*ordersmodel.Quote, error) {
+	quote, err := c.ordersGateway.Quote(ctx, userID, orderItems(items), address, coupon)
+	if err != nil {
+		if errors.Is(err, gateway.ErrInvalidInput) {
+			return nil, controller.ErrInvalidInput
 		}
 		return nil, err
 	}
 
-	return orders, nil
+	return quote, nil
 }
 
-func (c *OrdersController) CreateOrder(ctx context.Context, userID int64, price float64, items []*model.Item) (int64, error) {
+func orderItems(items []*model.Item) []*ordersmodel.Item {
 	var ordersItems []*ordersmodel.Item
 	for _, item := range items {
 		ordersItems = append(ordersItems, &ordersmodel.Item{
-			ItemID:   item.ID,
-			Quantity: item.Quantity,
+			ItemID:     item.ID,
+			Name:       item.Name,
+			CategoryID: item.CategoryID,
+			Quantity:   item.Quantity,
+			Price:      item.Price,
+			Weight:     item.Weight,
 		})
 	}
+	return ordersItems
+}
 
-	id, err := c.ordersGateway.CreateOrder(ctx, userID, price, ordersItems)
+// Orders returns a page of all orders, newest first. The cursor of the next
+// page is in the page; an invalid cursor gives ErrInvalidInput.
+func (c *OrdersController) Orders(ctx context.Context, cursor string) (*ordersmodel.OrderPage, error) {
+	page, err := c.ordersGateway.Orders(ctx, cursor)
 	if err != nil {
-		return 0, err
+		return nil, returnError(err)
 	}
 
-	return id, nil
+	return page, nil
+}
+
+func (c *OrdersController) UpdateOrderStatus(ctx context.Context, id int64, status string) error {
+	err := c.ordersGateway.UpdateOrderStatus(ctx, id, status)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrNotFound):
+			return controller.ErrNotFound
+		case errors.Is(err, gateway.ErrInvalidInput):
+			return controller.ErrInvalidInput
+		case errors.Is(err, gateway.ErrEditConflict):
+			return controller.ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	c.listener.OrderStatusChanged(id, status)
+
+	return nil
+}
+
+// PayOrder charges the card for the order. A declined or failed payment
+// returns the recorded attempt, which holds the reason.
+func (c *OrdersController) PayOrder(ctx context.Context, id int64, card ordersmodel.Card) (*ordersmodel.Payment, error) {
+	payment, err := c.ordersGateway.PayOrder(ctx, id, card)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrNotFound):
+			return nil, controller.ErrNotFound
+		case errors.Is(err, gateway.ErrEditConflict):
+			return nil, controller.ErrEditConflict
+		case errors.Is(err, gateway.ErrInvalidInput):
+			return nil, controller.ErrInvalidInput
+		case errors.Is(err, gateway.ErrDeclined):
+			return payment, controller.ErrPaymentDeclined
+		case errors.Is(err, gateway.ErrUnavailable):
+			return payment, controller.ErrPaymentUnavailable
+		default:
+			return nil, err
+		}
+	}
+
+	c.listener.OrderStatusChanged(id, ordersmodel.StatusPaid)
+
+	return payment, nil
+}
+
+func (c *OrdersController) Payments(ctx context.Context, id int64) ([]*ordersmodel.Payment, error) {
+	payments, err := c.ordersGateway.Payments(ctx, id)
+
+	if err != nil {
+		if errors.Is(err, gateway.ErrNotFound) {
+			return nil, controller.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return payments, nil
 }
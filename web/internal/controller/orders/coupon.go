@@ -0,0 +1,42 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/gateway"
+)
+
+func (c *OrdersController) Coupons(ctx context.Context) ([]*ordersmodel.Coupon, error) {
+	return c.ordersGateway.Coupons(ctx)
+}
+
+func (c *OrdersController) CreateCoupon(ctx context.Context, coupon *ordersmodel.Coupon) (*ordersmodel.Coupon, error) {
+	created, err := c.ordersGateway.CreateCoupon(ctx, coupon)
+	if err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrEditConflict):
+			return nil, controller.ErrDuplicateName
+		case errors.Is(err, gateway.ErrInvalidInput):
+			return nil, controller.ErrInvalidInput
+		default:
+			return nil, err
+		}
+	}
+
+	return created, nil
+}
+
+func (c *OrdersController) SetCouponActive(ctx context.Context, id int64, active bool) (*ordersmodel.Coupon, error) {
+	coupon, err := c.ordersGateway.SetCouponActive(ctx, id, active)
+	if err != nil {
+		if errors.Is(err, gateway.ErrNotFound) {
+			return nil, controller.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return coupon, nil
+}
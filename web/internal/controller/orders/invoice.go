@@ -0,0 +1,18 @@
+package orders
+
+import (
+	"context"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// Invoice returns the invoice of the order, which the orders service issues
+// on first request. An order that has not been paid gives ErrEditConflict.
+func (c *OrdersController) Invoice(ctx context.Context, orderID int64) (*ordersmodel.Invoice, error) {
+	invoice, err := c.ordersGateway.Invoice(ctx, orderID)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return invoice, nil
+}
@@ -0,0 +1,72 @@
+package orders
+
+import (
+	"context"
+	"net/url"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// Report is everything the sales dashboard shows.
+type Report struct {
+	Sales       *ordersmodel.SalesReport
+	ByQuantity  []*ordersmodel.ProductSales
+	ByRevenue   []*ordersmodel.ProductSales
+	StatusSales []*ordersmodel.StatusSales
+}
+
+// Report fetches the sales reports for the query, which holds the from, to,
+// currency and interval parameters of the orders service. A query the
+// service refuses gives ErrInvalidInput.
+func (c *OrdersController) Report(ctx context.Context, query url.Values) (*Report, error) {
+	var (
+		report Report
+		err    error
+	)
+
+	report.Sales, err = c.ordersGateway.SalesReport(ctx, query)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	rng := url.Values{}
+	for _, key := range []string{"from", "to", "currency"} {
+		if query.Has(key) {
+			rng[key] = query[key]
+		}
+	}
+
+	ranked := url.Values{"limit": {"10"}}
+	for key, values := range rng {
+		ranked[key] = values
+	}
+
+	ranked.Set("by", ordersmodel.RankByQuantity)
+	report.ByQuantity, err = c.ordersGateway.TopProducts(ctx, ranked)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	ranked.Set("by", ordersmodel.RankByRevenue)
+	report.ByRevenue, err = c.ordersGateway.TopProducts(ctx, ranked)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	report.StatusSales, err = c.ordersGateway.StatusReport(ctx, rng)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return &report, nil
+}
+
+// ReportCSV fetches the sales, products or statuses report as CSV.
+func (c *OrdersController) ReportCSV(ctx context.Context, name string, query url.Values) ([]byte, error) {
+	csv, err := c.ordersGateway.ReportCSV(ctx, name, query)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return csv, nil
+}
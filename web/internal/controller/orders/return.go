@@ -0,0 +1,97 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/gateway"
+)
+
+func (c *OrdersController) CreateReturn(ctx context.Context, orderID, itemID int64, quantity int32, reason string) (*ordersmodel.Return, error) {
+	ret, err := c.ordersGateway.CreateReturn(ctx, orderID, itemID, quantity, reason)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return ret, nil
+}
+
+func (c *OrdersController) OrderReturns(ctx context.Context, orderID int64) ([]*ordersmodel.Return, error) {
+	returns, err := c.ordersGateway.OrderReturns(ctx, orderID)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return returns, nil
+}
+
+func (c *OrdersController) Returns(ctx context.Context, status string) ([]*ordersmodel.Return, error) {
+	returns, err := c.ordersGateway.Returns(ctx, status)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return returns, nil
+}
+
+func (c *OrdersController) Return(ctx context.Context, id int64) (*ordersmodel.Return, error) {
+	ret, err := c.ordersGateway.Return(ctx, id)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return ret, nil
+}
+
+// ApproveReturn accepts the return request and refunds the returned units.
+// Restocking is left to the caller.
+func (c *OrdersController) ApproveReturn(ctx context.Context, id int64, note string) (*ordersmodel.Return, error) {
+	ret, err := c.ordersGateway.ResolveReturn(ctx, id, "approve", note)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return ret, nil
+}
+
+func (c *OrdersController) RejectReturn(ctx context.Context, id int64, note string) (*ordersmodel.Return, error) {
+	ret, err := c.ordersGateway.ResolveReturn(ctx, id, "reject", note)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return ret, nil
+}
+
+func (c *OrdersController) MarkReturnRestocked(ctx context.Context, id int64) (*ordersmodel.Return, error) {
+	ret, err := c.ordersGateway.MarkReturnRestocked(ctx, id)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return ret, nil
+}
+
+func (c *OrdersController) UnmarkReturnRestocked(ctx context.Context, id int64) (*ordersmodel.Return, error) {
+	ret, err := c.ordersGateway.UnmarkReturnRestocked(ctx, id)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return ret, nil
+}
+
+func returnError(err error) error {
+	switch {
+	case errors.Is(err, gateway.ErrNotFound):
+		return controller.ErrNotFound
+	case errors.Is(err, gateway.ErrInvalidInput):
+		return controller.ErrInvalidInput
+	case errors.Is(err, gateway.ErrEditConflict):
+		return controller.ErrEditConflict
+	default:
+		return err
+	}
+}
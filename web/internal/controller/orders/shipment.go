@@ -0,0 +1,31 @@
+package orders
+
+import (
+	"context"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// CreateShipment records a shipment of the order. The order moves to
+// partially shipped or shipped, depending on what is left to ship, and the
+// listener is told about the new status. An order that cannot be shipped
+// gives ErrEditConflict, more units than are left to ship ErrInvalidInput.
+func (c *OrdersController) CreateShipment(ctx context.Context, shipment *ordersmodel.Shipment) (*ordersmodel.Shipment, error) {
+	created, status, err := c.ordersGateway.CreateShipment(ctx, shipment)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	c.listener.OrderStatusChanged(shipment.OrderID, status)
+
+	return created, nil
+}
+
+func (c *OrdersController) Shipments(ctx context.Context, orderID int64) ([]*ordersmodel.Shipment, error) {
+	shipments, err := c.ordersGateway.Shipments(ctx, orderID)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return shipments, nil
+}
@@ -0,0 +1,27 @@
+package orders
+
+import (
+	"context"
+)
+
+// UserData fetches the orders, payments, returns and shipments of the user
+// as JSON, for data access requests.
+func (c *OrdersController) UserData(ctx context.Context, userID int64) ([]byte, error) {
+	data, err := c.ordersGateway.UserData(ctx, userID)
+	if err != nil {
+		return nil, returnError(err)
+	}
+
+	return data, nil
+}
+
+// EraseUser anonymises the orders of the user and returns how many there
+// are.
+func (c *OrdersController) EraseUser(ctx context.Context, userID int64) (int64, error) {
+	orders, err := c.ordersGateway.EraseUser(ctx, userID)
+	if err != nil {
+		return 0, returnError(err)
+	}
+
+	return orders, nil
+}
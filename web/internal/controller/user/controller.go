@@ -2,11 +2,24 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
 	"github.com/Maksim-Kot/Tech-store-web/internal/model"
 	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+	"github.com/Maksim-Kot/Tech-store-web/internal/totp"
+)
+
+const (
+	totpIssuer = "Tech Store"
+
+	recoveryCodeCount = 10
 )
 
 type userRepo interface {
@@ -14,6 +27,14 @@ type userRepo interface {
 	Authenticate(ctx context.Context, email, password string) (int64, error)
 	Exists(ctx context.Context, id int64) (bool, error)
 	Get(ctx context.Context, id int64) (*model.User, error)
+	EnableTOTP(ctx context.Context, id int64, secret string, recoveryCodes []string) error
+	DisableTOTP(ctx context.Context, id int64) error
+	TOTPSecret(ctx context.Context, id int64) (string, error)
+	UseTOTPStep(ctx context.Context, id int64, step int64) error
+	UseRecoveryCode(ctx context.Context, id int64, codeHash string) error
+	NotificationPreferences(ctx context.Context, userID int64) (*model.NotificationPreferences, error)
+	SetNotificationPreferences(ctx context.Context, prefs *model.NotificationPreferences) error
+	EraseUser(ctx context.Context, id int64, password string) error
 }
 
 type UserController struct {
@@ -66,3 +87,140 @@ func (c *UserController) Get(ctx context.Context, id int64) (*model.User, error)
 
 	return user, nil
 }
+
+// NewTOTPSecret generates a secret for a user who is enrolling in two-factor
+// authentication. The secret is not stored until it is confirmed with
+// ConfirmTOTP.
+func (c *UserController) NewTOTPSecret() (string, error) {
+	return totp.GenerateSecret()
+}
+
+// TOTPURI returns the otpauth:// URI for the given user and secret.
+func (c *UserController) TOTPURI(ctx context.Context, id int64, secret string) (string, error) {
+	user, err := c.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return totp.URI(totpIssuer, user.Email, secret), nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user has proven
+// that their authenticator app produces valid codes for the secret. It
+// returns the plaintext recovery codes, which are only ever shown once.
+func (c *UserController) ConfirmTOTP(ctx context.Context, id int64, secret, code string) ([]string, error) {
+	step, ok := totp.Match(secret, code, time.Now())
+	if !ok {
+		return nil, controller.ErrInvalidCode
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	err := c.userRepo.EnableTOTP(ctx, id, secret, hashes)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, controller.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// The code that confirmed the secret cannot be used to sign in as well.
+	err = c.userRepo.UseTOTPStep(ctx, id, int64(step))
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking a current
+// code or an unused recovery code.
+func (c *UserController) DisableTOTP(ctx context.Context, id int64, code string) error {
+	err := c.VerifySecondFactor(ctx, id, code)
+	if err != nil {
+		return err
+	}
+
+	return c.userRepo.DisableTOTP(ctx, id)
+}
+
+// VerifySecondFactor accepts either a TOTP code or a recovery code. A
+// recovery code is consumed when it is used. A TOTP code is only accepted
+// once: a code from the same or an earlier time step than the last one
+// accepted is refused, so a code seen over someone's shoulder or in transit
+// cannot be replayed while it is still valid.
+func (c *UserController) VerifySecondFactor(ctx context.Context, id int64, code string) error {
+	secret, err := c.userRepo.TOTPSecret(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	if step, ok := totp.Match(secret, code, time.Now()); ok {
+		err := c.userRepo.UseTOTPStep(ctx, id, int64(step))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return controller.ErrInvalidCode
+			}
+			return err
+		}
+		return nil
+	}
+
+	err = c.userRepo.UseRecoveryCode(ctx, id, hashRecoveryCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrInvalidCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Erase removes the personal data of the user. The account is kept under a
+// placeholder name and a random password, so nobody can sign in to it.
+func (c *UserController) Erase(ctx context.Context, id int64) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+
+	err := c.userRepo.EraseUser(ctx, id, hex.EncodeToString(b))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,15 @@
+package user
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+func (c *UserController) NotificationPreferences(ctx context.Context, userID int64) (*model.NotificationPreferences, error) {
+	return c.userRepo.NotificationPreferences(ctx, userID)
+}
+
+func (c *UserController) SetNotificationPreferences(ctx context.Context, prefs *model.NotificationPreferences) error {
+	return c.userRepo.SetNotificationPreferences(ctx, prefs)
+}
@@ -1,21 +1,30 @@
 package web
 
 import (
+	addresscontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/address"
+	cartcontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/cart"
 	catalogcontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/catalog"
 	orderscontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/orders"
 	usercontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/user"
+	wishlistcontroller "github.com/Maksim-Kot/Tech-store-web/internal/controller/wishlist"
 )
 
 type Controller struct {
-	Catalog *catalogcontroller.CatalogController
-	Orders  *orderscontroller.OrdersController
-	User    *usercontroller.UserController
+	Catalog  *catalogcontroller.CatalogController
+	Orders   *orderscontroller.OrdersController
+	User     *usercontroller.UserController
+	Cart     *cartcontroller.CartController
+	Wishlist *wishlistcontroller.WishlistController
+	Address  *addresscontroller.AddressController
 }
 
-func New(catalog *catalogcontroller.CatalogController, orders *orderscontroller.OrdersController, user *usercontroller.UserController) *Controller {
+func New(catalog *catalogcontroller.CatalogController, orders *orderscontroller.OrdersController, user *usercontroller.UserController, cart *cartcontroller.CartController, wishlist *wishlistcontroller.WishlistController, address *addresscontroller.AddressController) *Controller {
 	return &Controller{
-		Catalog: catalog,
-		Orders:  orders,
-		User:    user,
+		Catalog:  catalog,
+		Orders:   orders,
+		User:     user,
+		Cart:     cart,
+		Wishlist: wishlist,
+		Address:  address,
 	}
 }
@@ -0,0 +1,171 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	catalogmodel "github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+type wishlistRepo interface {
+	Wishlists(ctx context.Context, userID int64) ([]*model.Wishlist, error)
+	Wishlist(ctx context.Context, userID, id int64) (*model.Wishlist, error)
+	CreateWishlist(ctx context.Context, userID int64, name string, isDefault bool) (int64, error)
+	DeleteWishlist(ctx context.Context, userID, id int64) error
+	AddWishlistItem(ctx context.Context, wishlistID, productID int64) error
+	RemoveWishlistItem(ctx context.Context, wishlistID, productID int64) error
+}
+
+type catalog interface {
+	ProductByID(ctx context.Context, id int64) (*catalogmodel.Product, error)
+}
+
+type WishlistController struct {
+	wishlistRepo wishlistRepo
+	catalog      catalog
+	currencies   *currency.Table
+}
+
+func New(wishlistRepo wishlistRepo, catalog catalog, currencies *currency.Table) *WishlistController {
+	return &WishlistController{wishlistRepo: wishlistRepo, catalog: catalog, currencies: currencies}
+}
+
+// Wishlists returns all lists of the user. The default "saved for later"
+// list is created on first use, so it is always the first list returned.
+func (c *WishlistController) Wishlists(ctx context.Context, userID int64) ([]*model.Wishlist, error) {
+	wishlists, err := c.wishlistRepo.Wishlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wishlists) > 0 && wishlists[0].IsDefault {
+		return wishlists, nil
+	}
+
+	_, err = c.wishlistRepo.CreateWishlist(ctx, userID, model.DefaultWishlistName, true)
+	if err != nil && !errors.Is(err, repository.ErrDuplicateName) {
+		return nil, err
+	}
+
+	return c.wishlistRepo.Wishlists(ctx, userID)
+}
+
+func (c *WishlistController) Wishlist(ctx context.Context, userID, id int64) (*model.Wishlist, error) {
+	wishlist, err := c.wishlistRepo.Wishlist(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, controller.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+// Lines returns the items of the wishlist with the current price in the base
+// currency and stock of every product. Products removed from the catalog are
+// marked as missing.
+func (c *WishlistController) Lines(ctx context.Context, wishlist *model.Wishlist) ([]*model.WishlistLine, error) {
+	lines := make([]*model.WishlistLine, 0, len(wishlist.Items))
+
+	for _, item := range wishlist.Items {
+		line := &model.WishlistLine{ProductID: item.ProductID, Added: item.Added}
+
+		product, err := c.catalog.ProductByID(ctx, item.ProductID)
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			line.Missing = true
+		case err != nil:
+			return nil, err
+		default:
+			price, err := c.currencies.Convert(product.Price, c.currencies.Base())
+			if err != nil {
+				return nil, fmt.Errorf("product %d: %w", product.ID, err)
+			}
+
+			line.Name = product.Name
+			line.Price = price
+			line.Available = product.Quantity
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func (c *WishlistController) Create(ctx context.Context, userID int64, name string) (int64, error) {
+	id, err := c.wishlistRepo.CreateWishlist(ctx, userID, name, false)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateName) {
+			return 0, controller.ErrDuplicateName
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Delete removes a named list with all its items. The default list cannot
+// be deleted.
+func (c *WishlistController) Delete(ctx context.Context, userID, id int64) error {
+	wishlist, err := c.Wishlist(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if wishlist.IsDefault {
+		return controller.ErrInvalidInput
+	}
+
+	err = c.wishlistRepo.DeleteWishlist(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// AddItem saves the product in the user's list. A zero wishlistID stands for
+// the default list. Adding a product that is already in the list is a no-op.
+func (c *WishlistController) AddItem(ctx context.Context, userID, wishlistID, productID int64) error {
+	if wishlistID == 0 {
+		wishlists, err := c.Wishlists(ctx, userID)
+		if err != nil {
+			return err
+		}
+		wishlistID = wishlists[0].ID
+	} else if _, err := c.Wishlist(ctx, userID, wishlistID); err != nil {
+		return err
+	}
+
+	if _, err := c.catalog.ProductByID(ctx, productID); err != nil {
+		return err
+	}
+
+	return c.wishlistRepo.AddWishlistItem(ctx, wishlistID, productID)
+}
+
+func (c *WishlistController) RemoveItem(ctx context.Context, userID, wishlistID, productID int64) error {
+	if _, err := c.Wishlist(ctx, userID, wishlistID); err != nil {
+		return err
+	}
+
+	err := c.wishlistRepo.RemoveWishlistItem(ctx, wishlistID, productID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return controller.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package currency converts amounts between the shop's base currency and the
+// currencies shoppers can choose to see prices in. Rates come from the
+// configuration.
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Table holds the exchange rates of the display currencies. A rate is how
+// many units of the currency one unit of the base currency buys.
+type Table struct {
+	base  string
+	rates map[string]float64
+}
+
+// New builds a table from the configured rates. The base currency always has
+// rate 1 and need not be listed.
+func New(base string, rates map[string]float64) (*Table, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	if !money.ValidCurrency(base) {
+		return nil, fmt.Errorf("currency: invalid base currency %q", base)
+	}
+
+	t := &Table{base: base, rates: map[string]float64{base: 1}}
+
+	for code, rate := range rates {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !money.ValidCurrency(code) {
+			return nil, fmt.Errorf("currency: invalid currency %q", code)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("currency: rate of %s must be positive", code)
+		}
+		if code != base {
+			t.rates[code] = rate
+		}
+	}
+
+	return t, nil
+}
+
+// Base is the currency of the catalog and of orders.
+func (t *Table) Base() string {
+	return t.base
+}
+
+// Codes lists the display currencies, the base currency first.
+func (t *Table) Codes() []string {
+	codes := make([]string, 0, len(t.rates))
+	for code := range t.rates {
+		if code != t.base {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+
+	return append([]string{t.base}, codes...)
+}
+
+// Supported reports whether prices can be shown in the currency.
+func (t *Table) Supported(code string) bool {
+	_, ok := t.rates[code]
+	return ok
+}
+
+// Rate returns how many units of the currency one base unit buys.
+func (t *Table) Rate(code string) (float64, error) {
+	rate, ok := t.rates[code]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+	return rate, nil
+}
+
+// Convert converts an amount to another currency, rounding to the nearest
+// minor unit.
+func (t *Table) Convert(amount money.Money, to string) (money.Money, error) {
+	if amount.Currency == to {
+		return amount, nil
+	}
+
+	fromRate, err := t.Rate(amount.Currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+	toRate, err := t.Rate(to)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return amount.Convert(toRate/fromRate, to), nil
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/Maksim-Kot/Commons/discovery"
 	"github.com/Maksim-Kot/Commons/httputil"
+	"github.com/Maksim-Kot/Commons/money"
 	"github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
 	"github.com/Maksim-Kot/Tech-store-web/internal/gateway"
 )
@@ -22,14 +24,26 @@ const (
 	productURL            = baseURL + "/product/%d"
 	decreaseProductURL    = baseURL + "/product/%d/decrease/%d"
 	increaseProductURL    = baseURL + "/product/%d/increase/%d"
+	createCategoryURL     = baseURL + "/category"
+	updateCategoryURL     = baseURL + "/category/%d"
+	createProductURL      = baseURL + "/product"
+	updateProductURL      = baseURL + "/product/%d"
 )
 
 type Gateway struct {
-	registry discovery.Registry
+	balancer *httputil.Balancer
+	client   *http.Client
 }
 
-func New(registry discovery.Registry) *Gateway {
-	return &Gateway{registry}
+// New creates a gateway that spreads its requests over the instances of the
+// service as cfg says.
+func New(registry discovery.Registry, cfg httputil.BalancerConfig) (*Gateway, error) {
+	balancer, err := httputil.NewBalancer(serviceName, registry, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gateway{balancer: balancer, client: &http.Client{Transport: balancer}}, nil
 }
 
 type categoriesResponse struct {
@@ -44,8 +58,12 @@ type productResponse struct {
 	Product *model.Product `json:"product"`
 }
 
+type categoryResponse struct {
+	Category *model.Category `json:"category"`
+}
+
 func (g *Gateway) Catalog(ctx context.Context) ([]*model.Category, error) {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +75,7 @@ func (g *Gateway) Catalog(ctx context.Context) ([]*model.Category, error) {
 	}
 	log.Printf("[gateway] GET %s (catalog service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +94,7 @@ func (g *Gateway) Catalog(ctx context.Context) ([]*model.Category, error) {
 }
 
 func (g *Gateway) ProductsByCategoryID(ctx context.Context, id int64) ([]*model.Product, error) {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +106,7 @@ func (g *Gateway) ProductsByCategoryID(ctx context.Context, id int64) ([]*model.
 	}
 	log.Printf("[gateway] GET %s (catalog service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +130,7 @@ func (g *Gateway) ProductsByCategoryID(ctx context.Context, id int64) ([]*model.
 }
 
 func (g *Gateway) ProductByID(ctx context.Context, id int64) (*model.Product, error) {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +142,7 @@ func (g *Gateway) ProductByID(ctx context.Context, id int64) (*model.Product, er
 	}
 	log.Printf("[gateway] GET %s (catalog service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +166,7 @@ func (g *Gateway) ProductByID(ctx context.Context, id int64) (*model.Product, er
 }
 
 func (g *Gateway) DecreaseProductQuantity(ctx context.Context, id int64, amount int32) error {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return err
 	}
@@ -160,7 +178,7 @@ func (g *Gateway) DecreaseProductQuantity(ctx context.Context, id int64, amount
 	}
 	log.Printf("[gateway] POST %s (catalog service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -183,7 +201,7 @@ func (g *Gateway) DecreaseProductQuantity(ctx context.Context, id int64, amount
 }
 
 func (g *Gateway) IncreaseProductQuantity(ctx context.Context, id int64, amount int32) error {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return err
 	}
@@ -195,7 +213,7 @@ func (g *Gateway) IncreaseProductQuantity(ctx context.Context, id int64, amount
 	}
 	log.Printf("[gateway] POST %s (catalog service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -212,3 +230,140 @@ func (g *Gateway) IncreaseProductQuantity(ctx context.Context, id int64, amount
 
 	return nil
 }
+
+func (g *Gateway) PutCategory(ctx context.Context, category *model.Category) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf(createCategoryURL, addr)
+
+	return g.sendCategory(ctx, http.MethodPost, url, http.StatusCreated, category)
+}
+
+func (g *Gateway) UpdateCategory(ctx context.Context, category *model.Category) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf(updateCategoryURL, addr, category.ID)
+
+	return g.sendCategory(ctx, http.MethodPut, url, http.StatusOK, category)
+}
+
+func (g *Gateway) PutProduct(ctx context.Context, product *model.Product) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf(createProductURL, addr)
+
+	return g.sendProduct(ctx, http.MethodPost, url, http.StatusCreated, product, nil)
+}
+
+// UpdateProduct saves the product if its stock is still originalQuantity,
+// and fails with ErrEditConflict if it has changed since.
+func (g *Gateway) UpdateProduct(ctx context.Context, product *model.Product, originalQuantity int32) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf(updateProductURL, addr, product.ID)
+
+	return g.sendProduct(ctx, http.MethodPut, url, http.StatusOK, product, &originalQuantity)
+}
+
+// sendCategory writes the category to the catalog service and updates it
+// with the stored representation from the response.
+func (g *Gateway) sendCategory(ctx context.Context, method, url string, expected int, category *model.Category) error {
+	input := struct {
+		Name string `json:"name"`
+	}{
+		Name: category.Name,
+	}
+
+	var wrapper categoryResponse
+	if err := g.sendJSON(ctx, method, url, expected, input, &wrapper); err != nil {
+		return err
+	}
+
+	if wrapper.Category != nil {
+		*category = *wrapper.Category
+	}
+
+	return nil
+}
+
+// sendProduct writes the product to the catalog service and updates it
+// with the stored representation from the response. An update sends the
+// stock it started from as originalQuantity.
+func (g *Gateway) sendProduct(ctx context.Context, method, url string, expected int, product *model.Product, originalQuantity *int32) error {
+	input := struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Price       money.Money     `json:"price"`
+		Quantity    int32           `json:"quantity"`
+		ImageURL    string          `json:"image_url,omitempty"`
+		Attributes  json.RawMessage `json:"attributes"`
+		CategoryID  int64           `json:"category_id"`
+		Weight      float64         `json:"weight"`
+
+		OriginalQuantity *int32 `json:"original_quantity,omitempty"`
+	}{
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Quantity:    product.Quantity,
+		ImageURL:    product.ImageURL,
+		Attributes:  product.Attributes,
+		CategoryID:  product.CategoryID,
+		Weight:      product.Weight,
+
+		OriginalQuantity: originalQuantity,
+	}
+
+	var wrapper productResponse
+	if err := g.sendJSON(ctx, method, url, expected, input, &wrapper); err != nil {
+		return err
+	}
+
+	if wrapper.Product != nil {
+		*product = *wrapper.Product
+	}
+
+	return nil
+}
+
+func (g *Gateway) sendJSON(ctx context.Context, method, url string, expected int, input, output any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Printf("[gateway] %s %s (catalog service)", method, url)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return gateway.ErrNotFound
+		case http.StatusConflict:
+			return gateway.ErrEditConflict
+		default:
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(output)
+}
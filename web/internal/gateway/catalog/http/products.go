@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+)
+
+const (
+	productsURL = baseURL + "/products"
+
+	// lookupBatch is the most IDs the catalog looks up in one request.
+	lookupBatch = 100
+)
+
+type productLookupResponse struct {
+	Products []*model.Product `json:"products"`
+	Missing  []int64          `json:"missing"`
+}
+
+// ProductsByIDs looks up the products with the IDs in as few requests as the
+// catalog allows. It returns the products in the order of the IDs, and the
+// IDs of the products that do not exist.
+func (g *Gateway) ProductsByIDs(ctx context.Context, ids []int64) ([]*model.Product, []int64, error) {
+	var products []*model.Product
+	var missing []int64
+
+	for start := 0; start < len(ids); start += lookupBatch {
+		batch := ids[start:min(start+lookupBatch, len(ids))]
+
+		found, gone, err := g.lookupProducts(ctx, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		products = append(products, found...)
+		missing = append(missing, gone...)
+	}
+
+	return products, missing, nil
+}
+
+func (g *Gateway) lookupProducts(ctx context.Context, ids []int64) ([]*model.Product, []int64, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = strconv.FormatInt(id, 10)
+	}
+	u := fmt.Sprintf(productsURL, addr) + "?" + url.Values{"ids": {strings.Join(list, ",")}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[gateway] GET %s (catalog service)", u)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var wrapper productLookupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+		return nil, nil, err
+	}
+
+	return wrapper.Products, wrapper.Missing, nil
+}
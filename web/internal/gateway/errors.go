@@ -3,7 +3,11 @@ package gateway
 import "errors"
 
 var (
-	ErrNotFound     = errors.New("not found")
-	ErrNotEnough    = errors.New("not enough")
-	ErrEditConflict = errors.New("edit conflict")
+	ErrNotFound      = errors.New("not found")
+	ErrNotEnough     = errors.New("not enough")
+	ErrEditConflict  = errors.New("edit conflict")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrDeclined      = errors.New("declined")
+	ErrUnavailable   = errors.New("unavailable")
+	ErrInvalidCoupon = errors.New("invalid coupon")
 )
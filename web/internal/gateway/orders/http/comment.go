@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const orderCommentsURL = baseURL + "/order/%d/comments"
+
+func (g *Gateway) CreateComment(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := map[string]any{
+		"author_id": comment.AuthorID,
+		"author":    comment.Author,
+		"body":      comment.Body,
+	}
+
+	var wrapper struct {
+		Comment *model.Comment `json:"comment"`
+	}
+	err = g.send(ctx, http.MethodPost, fmt.Sprintf(orderCommentsURL, addr, comment.OrderID), http.StatusCreated, input, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Comment, nil
+}
+
+func (g *Gateway) Comments(ctx context.Context, orderID int64) ([]*model.Comment, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper struct {
+		Comments []*model.Comment `json:"comments"`
+	}
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(orderCommentsURL, addr, orderID), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Comments, nil
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const (
+	couponsURL      = baseURL + "/coupons"
+	couponActiveURL = baseURL + "/coupon/%d/active"
+)
+
+type couponResponse struct {
+	Coupon *model.Coupon `json:"coupon"`
+}
+
+type couponsResponse struct {
+	Coupons []*model.Coupon `json:"coupons"`
+}
+
+func (g *Gateway) Coupons(ctx context.Context) ([]*model.Coupon, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper couponsResponse
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(couponsURL, addr), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Coupons, nil
+}
+
+func (g *Gateway) CreateCoupon(ctx context.Context, coupon *model.Coupon) (*model.Coupon, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := map[string]any{
+		"code":           coupon.Code,
+		"kind":           coupon.Kind,
+		"value":          coupon.Value,
+		"min_basket":     coupon.MinBasket,
+		"category_ids":   coupon.CategoryIDs,
+		"product_ids":    coupon.ProductIDs,
+		"expires_at":     coupon.ExpiresAt,
+		"usage_limit":    coupon.UsageLimit,
+		"per_user_limit": coupon.PerUserLimit,
+	}
+
+	var wrapper couponResponse
+	err = g.send(ctx, http.MethodPost, fmt.Sprintf(couponsURL, addr), http.StatusCreated, input, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Coupon, nil
+}
+
+func (g *Gateway) SetCouponActive(ctx context.Context, id int64, active bool) (*model.Coupon, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper couponResponse
+	err = g.send(ctx, http.MethodPut, fmt.Sprintf(couponActiveURL, addr, id), http.StatusOK, map[string]bool{"active": active}, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Coupon, nil
+}
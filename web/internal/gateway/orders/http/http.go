@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/Maksim-Kot/Commons/discovery"
 	"github.com/Maksim-Kot/Commons/httputil"
@@ -19,26 +20,35 @@ const (
 
 	baseURL          = "http://%s/v1"
 	createOrderURL   = baseURL + "/order"
+	orderQuoteURL    = baseURL + "/orders/quote"
 	orderByIdURL     = baseURL + "/order/%d"
 	orderByUserIdURL = baseURL + "/orders/user/%d"
+	ordersURL        = baseURL + "/orders"
+	orderStatusURL   = baseURL + "/order/%d/status"
+	orderPaymentURL  = baseURL + "/order/%d/payment"
+	orderPaymentsURL = baseURL + "/order/%d/payments"
 )
 
 type Gateway struct {
-	registry discovery.Registry
+	balancer *httputil.Balancer
+	client   *http.Client
 }
 
-func New(registry discovery.Registry) *Gateway {
-	return &Gateway{registry}
+// New creates a gateway that spreads its requests over the instances of the
+// service as cfg says.
+func New(registry discovery.Registry, cfg httputil.BalancerConfig) (*Gateway, error) {
+	balancer, err := httputil.NewBalancer(serviceName, registry, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gateway{balancer: balancer, client: &http.Client{Transport: balancer}}, nil
 }
 
 type orderResponse struct {
 	Order *model.Order `json:"order"`
 }
 
-type ordersResponse struct {
-	Orders []*model.Order `json:"orders"`
-}
-
 type createOrderResponse struct {
 	Order struct {
 		ID int64 `json:"id"`
@@ -46,7 +56,7 @@ type createOrderResponse struct {
 }
 
 func (g *Gateway) OrderByID(ctx context.Context, id int64) (*model.Order, error) {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +68,7 @@ func (g *Gateway) OrderByID(ctx context.Context, id int64) (*model.Order, error)
 	}
 	log.Printf("[gateway] GET %s (orders service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -81,57 +91,86 @@ func (g *Gateway) OrderByID(ctx context.Context, id int64) (*model.Order, error)
 	return wrapper.Order, nil
 }
 
-func (g *Gateway) OrdersByUserID(ctx context.Context, id int64) ([]*model.Order, error) {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+// OrdersByUserID returns a page of the user's orders, newest first. An empty
+// status means any status; an empty cursor the first page.
+func (g *Gateway) OrdersByUserID(ctx context.Context, id int64, status, cursor string) (*model.OrderPage, error) {
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
-	url := fmt.Sprintf(orderByUserIdURL, addr, id)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	u := fmt.Sprintf(orderByUserIdURL, addr, id)
+
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var page model.OrderPage
+	err = g.send(ctx, http.MethodGet, u, http.StatusOK, nil, &page)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("[gateway] GET %s (orders service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	return &page, nil
+}
+
+type quoteResponse struct {
+	Quote *model.Quote `json:"quote"`
+}
+
+// Quote asks what the order would cost if it were placed now.
+func (g *Gateway) Quote(ctx context.Context, userID int64, items []*model.Item, address *model.Address, coupon string) (*model.Quote, error) {
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		switch resp.StatusCode {
-		case http.StatusNotFound:
-			return nil, gateway.ErrNotFound
-		default:
-			return nil, fmt.Errorf("unexpected status: %s", resp.Status)
-		}
+	input := map[string]any{
+		"user_id": userID,
+		"items":   items,
+		"address": address,
+		"coupon":  coupon,
 	}
 
-	var wrapper ordersResponse
-	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+	var wrapper quoteResponse
+	err = g.send(ctx, http.MethodPost, fmt.Sprintf(orderQuoteURL, addr), http.StatusOK, input, &wrapper)
+	if err != nil {
 		return nil, err
 	}
 
-	return wrapper.Orders, nil
+	return wrapper.Quote, nil
 }
 
-func (g *Gateway) CreateOrder(ctx context.Context, userID int64, price float64, items []*model.Item) (int64, error) {
-	addr, err := httputil.ServiceAddr(ctx, serviceName, g.registry)
+// CreateOrder places the order with the coupon, if one is given. A coupon the
+// orders service refuses gives ErrInvalidCoupon.
+func (g *Gateway) CreateOrder(ctx context.Context, userID int64, items []*model.Item, address *model.Address, coupon string, display model.Display, notes model.Notes) (int64, error) {
+	addr, err := g.balancer.Pick(ctx)
 	if err != nil {
 		return 0, err
 	}
 	url := fmt.Sprintf(createOrderURL, addr)
 
 	orderReq := struct {
-		UserID int64         `json:"user_id"`
-		Price  float64       `json:"price"`
-		Items  []*model.Item `json:"items"`
+		UserID  int64          `json:"user_id"`
+		Items   []*model.Item  `json:"items"`
+		Address *model.Address `json:"address"`
+		Coupon  string         `json:"coupon,omitempty"`
+		Display model.Display  `json:"display"`
+		model.Notes
 	}{
-		UserID: userID,
-		Price:  price,
-		Items:  items,
+		UserID:  userID,
+		Items:   items,
+		Address: address,
+		Coupon:  coupon,
+		Display: display,
+		Notes:   notes,
 	}
 
 	body, err := json.Marshal(orderReq)
@@ -147,14 +186,27 @@ func (g *Gateway) CreateOrder(ctx context.Context, userID int64, price float64,
 
 	log.Printf("[gateway] POST %s (orders service)", url)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		return 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return 0, fmt.Errorf("unexpected status: %s", resp.Status)
+		switch resp.StatusCode {
+		case http.StatusUnprocessableEntity:
+			var failure struct {
+				Error map[string]string `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&failure); err == nil && failure.Error["coupon"] != "" {
+				return 0, fmt.Errorf("%w: %s", gateway.ErrInvalidCoupon, failure.Error["coupon"])
+			}
+			return 0, gateway.ErrInvalidInput
+		case http.StatusBadRequest:
+			return 0, gateway.ErrInvalidInput
+		default:
+			return 0, fmt.Errorf("unexpected status: %s", resp.Status)
+		}
 	}
 
 	var wrapper createOrderResponse
@@ -164,3 +216,169 @@ func (g *Gateway) CreateOrder(ctx context.Context, userID int64, price float64,
 
 	return wrapper.Order.ID, nil
 }
+
+// Orders returns a page of every user's orders, newest first. An empty
+// cursor means the first page.
+func (g *Gateway) Orders(ctx context.Context, cursor string) (*model.OrderPage, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf(ordersURL, addr)
+	if cursor != "" {
+		u += "?" + url.Values{"cursor": {cursor}}.Encode()
+	}
+
+	var page model.OrderPage
+	err = g.send(ctx, http.MethodGet, u, http.StatusOK, nil, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (g *Gateway) UpdateOrderStatus(ctx context.Context, id int64, status string) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf(orderStatusURL, addr, id)
+
+	body, err := json.Marshal(map[string]string{"status": status})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Printf("[gateway] PUT %s (orders service)", url)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return gateway.ErrNotFound
+		case http.StatusBadRequest:
+			return gateway.ErrInvalidInput
+		case http.StatusConflict:
+			return gateway.ErrEditConflict
+		default:
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+	}
+
+	return nil
+}
+
+type paymentResponse struct {
+	Payment *model.Payment `json:"payment"`
+}
+
+type paymentsResponse struct {
+	Payments []*model.Payment `json:"payments"`
+}
+
+// PayOrder charges the card for the order. When the payment is declined or
+// the provider is unavailable, the recorded attempt is returned along with
+// the error.
+func (g *Gateway) PayOrder(ctx context.Context, id int64, card model.Card) (*model.Payment, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf(orderPaymentURL, addr, id)
+
+	body, err := json.Marshal(map[string]model.Card{"card": card})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Printf("[gateway] POST %s (orders service)", url)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var wrapper paymentResponse
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusPaymentRequired, http.StatusServiceUnavailable:
+		if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusPaymentRequired {
+			return wrapper.Payment, gateway.ErrDeclined
+		}
+		return wrapper.Payment, gateway.ErrUnavailable
+	case http.StatusNotFound:
+		return nil, gateway.ErrNotFound
+	case http.StatusConflict:
+		return nil, gateway.ErrEditConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return nil, gateway.ErrInvalidInput
+	default:
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+		return nil, err
+	}
+
+	return wrapper.Payment, nil
+}
+
+func (g *Gateway) Payments(ctx context.Context, id int64) ([]*model.Payment, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf(orderPaymentsURL, addr, id)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gateway] GET %s (orders service)", url)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return nil, gateway.ErrNotFound
+		default:
+			return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+	}
+
+	var wrapper paymentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
+		return nil, err
+	}
+
+	return wrapper.Payments, nil
+}
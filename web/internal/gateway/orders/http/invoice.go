@@ -0,0 +1,30 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const orderInvoiceURL = baseURL + "/order/%d/invoice"
+
+type invoiceResponse struct {
+	Invoice *model.Invoice `json:"invoice"`
+}
+
+func (g *Gateway) Invoice(ctx context.Context, orderID int64) (*model.Invoice, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper invoiceResponse
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(orderInvoiceURL, addr, orderID), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Invoice, nil
+}
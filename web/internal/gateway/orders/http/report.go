@@ -0,0 +1,83 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const reportURL = baseURL + "/reports/%s"
+
+type salesReportResponse struct {
+	Report *model.SalesReport `json:"report"`
+}
+
+type topProductsResponse struct {
+	Products []*model.ProductSales `json:"products"`
+}
+
+type statusReportResponse struct {
+	Statuses []*model.StatusSales `json:"statuses"`
+}
+
+// SalesReport fetches the sales report. The query holds the from, to,
+// currency and interval parameters of the orders service.
+func (g *Gateway) SalesReport(ctx context.Context, query url.Values) (*model.SalesReport, error) {
+	var wrapper salesReportResponse
+	if err := g.report(ctx, "sales", query, &wrapper); err != nil {
+		return nil, err
+	}
+
+	return wrapper.Report, nil
+}
+
+func (g *Gateway) TopProducts(ctx context.Context, query url.Values) ([]*model.ProductSales, error) {
+	var wrapper topProductsResponse
+	if err := g.report(ctx, "products", query, &wrapper); err != nil {
+		return nil, err
+	}
+
+	return wrapper.Products, nil
+}
+
+func (g *Gateway) StatusReport(ctx context.Context, query url.Values) ([]*model.StatusSales, error) {
+	var wrapper statusReportResponse
+	if err := g.report(ctx, "statuses", query, &wrapper); err != nil {
+		return nil, err
+	}
+
+	return wrapper.Statuses, nil
+}
+
+// ReportCSV fetches one of the sales, products and statuses reports as CSV.
+func (g *Gateway) ReportCSV(ctx context.Context, name string, query url.Values) ([]byte, error) {
+	csv := url.Values{"format": {"csv"}}
+	for key, values := range query {
+		csv[key] = values
+	}
+
+	buf := new(bytes.Buffer)
+	if err := g.report(ctx, name, csv, buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *Gateway) report(ctx context.Context, name string, query url.Values, output any) error {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf(reportURL, addr, name)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	return g.send(ctx, http.MethodGet, u, http.StatusOK, nil, output)
+}
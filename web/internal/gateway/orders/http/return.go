@@ -0,0 +1,199 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/gateway"
+)
+
+const (
+	orderReturnsURL = baseURL + "/order/%d/returns"
+	returnsURL      = baseURL + "/returns"
+	returnURL       = baseURL + "/return/%d"
+	returnActionURL = baseURL + "/return/%d/%s"
+)
+
+type returnResponse struct {
+	Return *model.Return `json:"return"`
+}
+
+type returnsResponse struct {
+	Returns []*model.Return `json:"returns"`
+}
+
+func (g *Gateway) CreateReturn(ctx context.Context, orderID, itemID int64, quantity int32, reason string) (*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := map[string]any{
+		"item_id":  itemID,
+		"quantity": quantity,
+		"reason":   reason,
+	}
+
+	var wrapper returnResponse
+	err = g.send(ctx, http.MethodPost, fmt.Sprintf(orderReturnsURL, addr, orderID), http.StatusCreated, input, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Return, nil
+}
+
+func (g *Gateway) OrderReturns(ctx context.Context, orderID int64) ([]*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper returnsResponse
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(orderReturnsURL, addr, orderID), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Returns, nil
+}
+
+func (g *Gateway) Returns(ctx context.Context, status string) ([]*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf(returnsURL, addr)
+	if status != "" {
+		u += "?" + url.Values{"status": {status}}.Encode()
+	}
+
+	var wrapper returnsResponse
+	err = g.send(ctx, http.MethodGet, u, http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Returns, nil
+}
+
+func (g *Gateway) Return(ctx context.Context, id int64) (*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper returnResponse
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(returnURL, addr, id), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Return, nil
+}
+
+// ResolveReturn approves or rejects a return request; action is either
+// "approve" or "reject".
+func (g *Gateway) ResolveReturn(ctx context.Context, id int64, action, note string) (*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper returnResponse
+	err = g.send(ctx, http.MethodPut, fmt.Sprintf(returnActionURL, addr, id, action), http.StatusOK, map[string]string{"note": note}, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Return, nil
+}
+
+func (g *Gateway) MarkReturnRestocked(ctx context.Context, id int64) (*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper returnResponse
+	err = g.send(ctx, http.MethodPut, fmt.Sprintf(returnActionURL, addr, id, "restocked"), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Return, nil
+}
+
+func (g *Gateway) UnmarkReturnRestocked(ctx context.Context, id int64) (*model.Return, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper returnResponse
+	err = g.send(ctx, http.MethodDelete, fmt.Sprintf(returnActionURL, addr, id, "restocked"), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Return, nil
+}
+
+// send performs a request against the orders service. A nil input sends no
+// body; the response is decoded into output when the expected status comes
+// back.
+func (g *Gateway) send(ctx context.Context, method, url string, expected int, input, output any) error {
+	var body io.Reader
+	if input != nil {
+		js, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(js)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	log.Printf("[gateway] %s %s (orders service)", method, url)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return gateway.ErrNotFound
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return gateway.ErrInvalidInput
+		case http.StatusConflict:
+			return gateway.ErrEditConflict
+		default:
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+	}
+
+	// A writer takes the body as it is, for responses that are not JSON.
+	if w, ok := output.(io.Writer); ok {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(output)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+const orderShipmentsURL = baseURL + "/order/%d/shipments"
+
+// CreateShipment records the shipment and returns it together with the
+// status the order has moved to.
+func (g *Gateway) CreateShipment(ctx context.Context, shipment *model.Shipment) (*model.Shipment, string, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	input := map[string]any{
+		"carrier":         shipment.Carrier,
+		"tracking_number": shipment.TrackingNumber,
+		"lines":           shipment.Lines,
+	}
+
+	var wrapper struct {
+		Shipment *model.Shipment `json:"shipment"`
+		Order    struct {
+			Status string `json:"status"`
+		} `json:"order"`
+	}
+	err = g.send(ctx, http.MethodPost, fmt.Sprintf(orderShipmentsURL, addr, shipment.OrderID), http.StatusCreated, input, &wrapper)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return wrapper.Shipment, wrapper.Order.Status, nil
+}
+
+func (g *Gateway) Shipments(ctx context.Context, orderID int64) ([]*model.Shipment, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrapper struct {
+		Shipments []*model.Shipment `json:"shipments"`
+	}
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(orderShipmentsURL, addr, orderID), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapper.Shipments, nil
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	userDataURL  = baseURL + "/user/%d/data"
+	userEraseURL = baseURL + "/user/%d/erase"
+)
+
+// UserData fetches everything the orders service holds about the user, as
+// the JSON it responds with.
+func (g *Gateway) UserData(ctx context.Context, userID int64) ([]byte, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	err = g.send(ctx, http.MethodGet, fmt.Sprintf(userDataURL, addr, userID), http.StatusOK, nil, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EraseUser anonymises the orders of the user and returns how many there
+// are.
+func (g *Gateway) EraseUser(ctx context.Context, userID int64) (int64, error) {
+	addr, err := g.balancer.Pick(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var wrapper struct {
+		User struct {
+			Orders int64 `json:"orders"`
+		} `json:"user"`
+	}
+	err = g.send(ctx, http.MethodPost, fmt.Sprintf(userEraseURL, addr, userID), http.StatusOK, nil, &wrapper)
+	if err != nil {
+		return 0, err
+	}
+
+	return wrapper.User.Orders, nil
+}
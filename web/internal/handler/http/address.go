@@ -0,0 +1,254 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+type addressForm struct {
+	Name                string `form:"name"`
+	Line1               string `form:"line1"`
+	Line2               string `form:"line2"`
+	City                string `form:"city"`
+	Region              string `form:"region"`
+	PostalCode          string `form:"postal_code"`
+	Country             string `form:"country"`
+	Phone               string `form:"phone"`
+	IsDefault           bool   `form:"is_default"`
+	validator.Validator `form:"-"`
+}
+
+func newAddressForm(address *model.Address) addressForm {
+	return addressForm{
+		Name:       address.Name,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Phone:      address.Phone,
+		IsDefault:  address.IsDefault,
+	}
+}
+
+func (f *addressForm) address() ordersmodel.Address {
+	address := ordersmodel.Address{
+		Name:       f.Name,
+		Line1:      f.Line1,
+		Line2:      f.Line2,
+		City:       f.City,
+		Region:     f.Region,
+		PostalCode: f.PostalCode,
+		Country:    f.Country,
+		Phone:      f.Phone,
+	}
+	address.Normalize()
+
+	return address
+}
+
+// validate applies the same per-country rules the orders service enforces.
+func (f *addressForm) validate() {
+	address := f.address()
+	for field, message := range address.Validate() {
+		f.AddFieldError(field, "This field "+message)
+	}
+}
+
+func (h *Handler) Addresses(w http.ResponseWriter, r *http.Request) {
+	h.renderAddresses(w, r, http.StatusOK, addressForm{})
+}
+
+func (h *Handler) renderAddresses(w http.ResponseWriter, r *http.Request, status int, form addressForm) {
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	addresses, err := h.Ctrl.Address.Addresses(r.Context(), userID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Addresses = addresses
+	data.Countries = ordersmodel.Countries()
+	data.Form = form
+
+	h.render(w, status, "addresses.html", data)
+}
+
+func (h *Handler) AddressCreatePost(w http.ResponseWriter, r *http.Request) {
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	var form addressForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate()
+
+	if !form.Valid() {
+		h.renderAddresses(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	address := &model.Address{
+		UserID:    userID,
+		IsDefault: form.IsDefault,
+		Address:   form.address(),
+	}
+
+	err = h.Ctrl.Address.Create(r.Context(), address)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Address saved")
+
+	http.Redirect(w, r, "/account/addresses", http.StatusSeeOther)
+}
+
+func (h *Handler) Address(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	address, err := h.Ctrl.Address.Address(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Address = address
+	data.Countries = ordersmodel.Countries()
+	data.Form = newAddressForm(address)
+
+	h.render(w, http.StatusOK, "address.html", data)
+}
+
+func (h *Handler) AddressPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	address, err := h.Ctrl.Address.Address(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	var form addressForm
+
+	err = h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate()
+
+	if !form.Valid() {
+		data := h.newTemplateData(r)
+		data.Address = address
+		data.Countries = ordersmodel.Countries()
+		data.Form = form
+		h.render(w, http.StatusUnprocessableEntity, "address.html", data)
+		return
+	}
+
+	address.Address = form.address()
+	address.IsDefault = address.IsDefault || form.IsDefault
+
+	err = h.Ctrl.Address.Update(r.Context(), address)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Address updated")
+
+	http.Redirect(w, r, "/account/addresses", http.StatusSeeOther)
+}
+
+func (h *Handler) AddressDeletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	err = h.Ctrl.Address.Delete(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Address deleted")
+
+	http.Redirect(w, r, "/account/addresses", http.StatusSeeOther)
+}
+
+func (h *Handler) AddressDefaultPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	err = h.Ctrl.Address.SetDefault(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Default address changed")
+
+	http.Redirect(w, r, "/account/addresses", http.StatusSeeOther)
+}
@@ -0,0 +1,534 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Maksim-Kot/Commons/money"
+	catalogmodel "github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+func (h *Handler) AdminDashboard(w http.ResponseWriter, r *http.Request) {
+	data := h.newTemplateData(r)
+
+	h.render(w, http.StatusOK, "admin.html", data)
+}
+
+type categoryForm struct {
+	Name                string `form:"name"`
+	validator.Validator `form:"-"`
+}
+
+func (h *Handler) AdminCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.Ctrl.Catalog.Catalog(r.Context())
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Categories = categories
+	data.Form = categoryForm{}
+
+	h.render(w, http.StatusOK, "admin_categories.html", data)
+}
+
+func (h *Handler) AdminCategoryCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form categoryForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+
+	if !form.Valid() {
+		categories, err := h.Ctrl.Catalog.Catalog(r.Context())
+		if err != nil {
+			h.ServerError(w, err)
+			return
+		}
+
+		data := h.newTemplateData(r)
+		data.Categories = categories
+		data.Form = form
+		h.render(w, http.StatusUnprocessableEntity, "admin_categories.html", data)
+		return
+	}
+
+	category := &catalogmodel.Category{Name: form.Name}
+
+	err = h.Ctrl.Catalog.PutCategory(r.Context(), category)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Category created")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/category/%d", category.ID), http.StatusSeeOther)
+}
+
+func (h *Handler) AdminCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	category, err := h.Ctrl.Catalog.CategoryByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.renderAdminCategory(w, r, http.StatusOK, category, categoryForm{Name: category.Name})
+}
+
+func (h *Handler) AdminCategoryPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	var form categoryForm
+
+	err = h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+
+	category := &catalogmodel.Category{ID: id, Name: form.Name}
+
+	if !form.Valid() {
+		h.renderAdminCategory(w, r, http.StatusUnprocessableEntity, category, form)
+		return
+	}
+
+	err = h.Ctrl.Catalog.UpdateCategory(r.Context(), category)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Category updated")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/category/%d", id), http.StatusSeeOther)
+}
+
+func (h *Handler) renderAdminCategory(w http.ResponseWriter, r *http.Request, status int, category *catalogmodel.Category, form categoryForm) {
+	products, err := h.Ctrl.Catalog.ProductsByCategoryID(r.Context(), category.ID)
+	if err != nil && !errors.Is(err, controller.ErrNotFound) {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Category = category
+	data.Products = products
+	data.Form = form
+
+	h.render(w, status, "admin_category.html", data)
+}
+
+// productForm is the product editor. Price is a decimal amount in Currency.
+// OriginalQuantity is the stock the edit started from, which the catalog
+// checks is still current before saving.
+type productForm struct {
+	ID                  int64   `form:"-"`
+	Name                string  `form:"name"`
+	Description         string  `form:"description"`
+	Price               string  `form:"price"`
+	Currency            string  `form:"currency"`
+	Quantity            int32   `form:"quantity"`
+	OriginalQuantity    int32   `form:"original_quantity"`
+	ImageURL            string  `form:"image_url"`
+	Attributes          string  `form:"attributes"`
+	CategoryID          int64   `form:"category_id"`
+	Weight              float64 `form:"weight"`
+	validator.Validator `form:"-"`
+}
+
+// validate checks the form. The currency must be one of the table, as the
+// cart converts prices to the base currency with it.
+func (f *productForm) validate(currencies *currency.Table) {
+	f.CheckField(validator.NotBlank(f.Name), "name", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Name, 255), "name", "This field cannot be more than 255 characters long")
+	_, err := money.Parse(f.Price, f.Currency)
+	f.CheckField(err == nil, "price", "Enter an amount such as 12.50")
+	f.Currency = catalogmodel.NormalizeCurrency(f.Currency)
+	f.CheckField(money.ValidCurrency(f.Currency), "currency", "Enter a three-letter currency code")
+	f.CheckField(currencies.Supported(f.Currency), "currency", "Prices can only be in "+strings.Join(currencies.Codes(), ", "))
+	f.CheckField(f.Quantity >= 0, "quantity", "This field must not be negative")
+	f.CheckField(f.CategoryID > 0, "category_id", "Please choose a category")
+	f.CheckField(f.Weight >= 0, "weight", "This field must not be negative")
+	f.CheckField(json.Valid([]byte(f.Attributes)), "attributes", "This field must contain a valid JSON object")
+}
+
+// product builds the product from a validated form.
+func (f *productForm) product() *catalogmodel.Product {
+	price, _ := money.Parse(f.Price, f.Currency)
+
+	return &catalogmodel.Product{
+		ID:          f.ID,
+		Name:        f.Name,
+		Description: f.Description,
+		Price:       price,
+		Quantity:    f.Quantity,
+		ImageURL:    f.ImageURL,
+		Attributes:  json.RawMessage(f.Attributes),
+		CategoryID:  f.CategoryID,
+		Weight:      f.Weight,
+	}
+}
+
+func (h *Handler) AdminProductCreate(w http.ResponseWriter, r *http.Request) {
+	form := productForm{Attributes: "{}", Currency: h.Currencies.Base()}
+
+	categoryID, err := strconv.ParseInt(r.URL.Query().Get("category"), 10, 64)
+	if err == nil {
+		form.CategoryID = categoryID
+	}
+
+	h.renderAdminProduct(w, r, http.StatusOK, form)
+}
+
+func (h *Handler) AdminProductCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form productForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate(h.Currencies)
+
+	if !form.Valid() {
+		h.renderAdminProduct(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	product := form.product()
+
+	err = h.Ctrl.Catalog.PutProduct(r.Context(), product)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Product created")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/product/%d", product.ID), http.StatusSeeOther)
+}
+
+func (h *Handler) AdminProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	product, err := h.Ctrl.Catalog.ProductByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	form := productForm{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price.Decimal(),
+		Currency:    product.Price.Currency,
+		Quantity:    product.Quantity,
+		ImageURL:    product.ImageURL,
+		Attributes:  string(product.Attributes),
+		CategoryID:  product.CategoryID,
+		Weight:      product.Weight,
+
+		OriginalQuantity: product.Quantity,
+	}
+
+	h.renderAdminProduct(w, r, http.StatusOK, form)
+}
+
+func (h *Handler) AdminProductPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	var form productForm
+
+	err = h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+	form.ID = id
+
+	form.validate(h.Currencies)
+
+	if !form.Valid() {
+		h.renderAdminProduct(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	err = h.Ctrl.Catalog.UpdateProduct(r.Context(), form.product(), form.OriginalQuantity)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrEditConflict):
+			h.adminProductConflict(w, r, form)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Product updated")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/product/%d", id), http.StatusSeeOther)
+}
+
+// adminProductConflict shows the editor again when the stock changed while
+// it was open, with the current stock to check the edit against.
+func (h *Handler) adminProductConflict(w http.ResponseWriter, r *http.Request, form productForm) {
+	product, err := h.Ctrl.Catalog.ProductByID(r.Context(), form.ID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	form.OriginalQuantity = product.Quantity
+	form.AddFieldError("quantity", fmt.Sprintf("The stock changed to %d while you were editing; check the quantity and save again", product.Quantity))
+
+	h.renderAdminProduct(w, r, http.StatusConflict, form)
+}
+
+func (h *Handler) renderAdminProduct(w http.ResponseWriter, r *http.Request, status int, form productForm) {
+	categories, err := h.Ctrl.Catalog.Catalog(r.Context())
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Categories = categories
+	data.Form = form
+
+	h.render(w, status, "admin_product.html", data)
+}
+
+// AdminOrders pages through all orders, newest first. The cursors only lead
+// forwards, so the query also carries the cursors of the pages before the
+// current one, in prev, for the link back.
+func (h *Handler) AdminOrders(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	cursor := qs.Get("cursor")
+	trail := qs["prev"]
+
+	page, err := h.Ctrl.Orders.Orders(r.Context(), cursor)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	var orders []*model.Order
+
+	for _, order := range page.Orders {
+		orders = append(orders, &model.Order{
+			ID:        order.ID,
+			UserID:    order.UserID,
+			Price:     order.Price,
+			Status:    order.Status,
+			CreatedAt: order.CreatedAt,
+		})
+	}
+
+	data := h.newTemplateData(r)
+	data.Orders = orders
+	data.FirstPage = cursor == ""
+
+	if page.NextCursor != "" {
+		next := url.Values{"cursor": {page.NextCursor}, "prev": append(slices.Clip(trail), cursor)}
+		data.NextPage = "/admin/orders?" + next.Encode()
+	}
+
+	if cursor != "" {
+		prev := url.Values{}
+		if n := len(trail); n > 0 {
+			if trail[n-1] != "" {
+				prev.Set("cursor", trail[n-1])
+			}
+			if n > 1 {
+				prev["prev"] = trail[:n-1]
+			}
+		}
+		data.PrevPage = "/admin/orders?" + prev.Encode()
+	}
+
+	h.render(w, http.StatusOK, "admin_orders.html", data)
+}
+
+func (h *Handler) AdminOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	purchase, err := h.Ctrl.Orders.OrderByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	order := model.Order{
+		ID:        purchase.ID,
+		UserID:    purchase.UserID,
+		Status:    purchase.Status,
+		Address:   purchase.Address,
+		Promotion: purchase.Promotion,
+		Notes:     purchase.Notes,
+		CreatedAt: purchase.CreatedAt,
+		Price:     purchase.Price,
+		Breakdown: purchase.Breakdown,
+		Display:   purchase.Display,
+	}
+
+	products, err := h.orderProducts(r.Context(), purchase.Items)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+	order.Products = products
+
+	payments, err := h.Ctrl.Orders.Payments(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	returns, err := h.Ctrl.Orders.OrderReturns(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	comments, err := h.Ctrl.Orders.Comments(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	shipments, err := h.Ctrl.Orders.Shipments(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Order = &order
+	data.Breakdown = &order.Breakdown
+	data.Payments = payments
+	data.Returns = orderReturns(&order, returns)
+	data.Comments = comments
+	data.Shipments = orderShipments(&order, shipments)
+	// The shipment form is only shown while there is something to ship.
+	if slices.Contains(ordersmodel.ShippableStatuses, order.Status) {
+		data.Carriers = ordersmodel.Carriers()
+	}
+	// Only the statuses staff can move the order to are offered.
+	data.Statuses = ordersmodel.StatusChanges[order.Status]
+	data.showBase()
+
+	h.render(w, http.StatusOK, "admin_order.html", data)
+}
+
+func (h *Handler) AdminOrderStatusPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	status := r.PostForm.Get("status")
+	if !validator.PermittedValue(status, ordersmodel.Statuses...) {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = h.Ctrl.Orders.UpdateOrderStatus(r.Context(), id, status)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "The status was not changed: the order has moved on since, or its payment could not be refunded")
+			http.Redirect(w, r, fmt.Sprintf("/admin/order/%d", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Order status updated")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/order/%d", id), http.StatusSeeOther)
+}
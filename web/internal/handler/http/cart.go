@@ -0,0 +1,170 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// sessionCart returns the cart of an anonymous visitor, which lives in the
+// session until they log in.
+func (h *Handler) sessionCart(r *http.Request) *model.Cart {
+	cart, ok := h.SessionManager.Get(r.Context(), "cart").(model.Cart)
+	if !ok || cart.Items == nil {
+		return &model.Cart{Items: make(map[int64]model.Item)}
+	}
+	return &cart
+}
+
+func (h *Handler) saveSessionCart(r *http.Request, cart *model.Cart) {
+	if len(cart.Items) == 0 {
+		h.SessionManager.Remove(r.Context(), "cart")
+		return
+	}
+	h.SessionManager.Put(r.Context(), "cart", *cart)
+}
+
+// loadCart returns the stored cart of an authenticated user or the session
+// cart of an anonymous visitor.
+func (h *Handler) loadCart(r *http.Request) (*model.Cart, error) {
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if id != 0 && h.IsAuthenticated(r) {
+		return h.Ctrl.Cart.Cart(r.Context(), id)
+	}
+	return h.sessionCart(r), nil
+}
+
+// mergeSessionCart moves the anonymous session cart into the stored cart of
+// the user who has just logged in.
+func (h *Handler) mergeSessionCart(r *http.Request, userID int64) error {
+	cart := h.sessionCart(r)
+	if len(cart.Items) == 0 {
+		return nil
+	}
+
+	err := h.Ctrl.Cart.Merge(r.Context(), userID, cart)
+	if err != nil {
+		return err
+	}
+
+	h.SessionManager.Remove(r.Context(), "cart")
+
+	return nil
+}
+
+func (h *Handler) UpdateCart(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	quantity, err := strconv.ParseInt(r.FormValue("quantity"), 10, 32)
+	if err != nil || quantity < 0 {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if userID != 0 && h.IsAuthenticated(r) {
+		err = h.Ctrl.Cart.SetQuantity(r.Context(), userID, id, int32(quantity))
+		if err != nil {
+			if !errors.Is(err, controller.ErrNotFound) {
+				h.ServerError(w, err)
+				return
+			}
+			h.SessionManager.Put(r.Context(), "flash", "Item not found in cart")
+			http.Redirect(w, r, "/cart", http.StatusSeeOther)
+			return
+		}
+	} else {
+		cart := h.sessionCart(r)
+
+		item, exists := cart.Items[id]
+		if !exists {
+			h.SessionManager.Put(r.Context(), "flash", "Item not found in cart")
+			http.Redirect(w, r, "/cart", http.StatusSeeOther)
+			return
+		}
+
+		if quantity == 0 {
+			delete(cart.Items, id)
+		} else {
+			item.Quantity = int32(quantity)
+			cart.Items[id] = item
+		}
+
+		h.saveSessionCart(r, cart)
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Cart updated")
+
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
+
+// checkoutCart loads and checks the cart for checkout. Checkout is only
+// allowed once the shopper has accepted every change to the cart, so the
+// shopper is sent back to the cart page otherwise. It reports whether the
+// handler can go on with the returned cart.
+func (h *Handler) checkoutCart(w http.ResponseWriter, r *http.Request) (*model.CheckedCart, bool) {
+	cart, err := h.loadCart(r)
+	if err != nil {
+		h.ServerError(w, err)
+		return nil, false
+	}
+
+	if len(cart.Items) == 0 {
+		h.SessionManager.Put(r.Context(), "flash", "Cart is empty")
+		http.Redirect(w, r, "/catalog", http.StatusSeeOther)
+		return nil, false
+	}
+
+	checked, err := h.Ctrl.Cart.Check(r.Context(), cart)
+	if err != nil {
+		h.ServerError(w, err)
+		return nil, false
+	}
+
+	if checked.Changed() {
+		h.SessionManager.Put(r.Context(), "flash", "Some items in your cart have changed. Please review them before placing your order.")
+		http.Redirect(w, r, "/cart", http.StatusSeeOther)
+		return nil, false
+	}
+
+	return checked, true
+}
+
+// AcceptCartChanges applies every flagged change to the cart: unavailable
+// items are removed, quantities are capped to the stock and prices are
+// updated to the current ones.
+func (h *Handler) AcceptCartChanges(w http.ResponseWriter, r *http.Request) {
+	cart, err := h.loadCart(r)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	checked, err := h.Ctrl.Cart.Check(r.Context(), cart)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if userID != 0 && h.IsAuthenticated(r) {
+		err = h.Ctrl.Cart.Accept(r.Context(), checked)
+		if err != nil {
+			h.ServerError(w, err)
+			return
+		}
+	} else {
+		h.saveSessionCart(r, checked.Accepted())
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Your cart has been updated")
+
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+)
+
+// AdminOrderCommentPost adds an internal comment to the order, signed with
+// the name of the staff member writing it.
+func (h *Handler) AdminOrderCommentPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	body := strings.TrimSpace(r.PostFormValue("body"))
+
+	switch {
+	case body == "":
+		h.SessionManager.Put(r.Context(), "flash", "The comment is empty")
+		http.Redirect(w, r, fmt.Sprintf("/admin/order/%d", id), http.StatusSeeOther)
+		return
+	case utf8.RuneCountInString(body) > ordersmodel.MaxCommentLength:
+		h.SessionManager.Put(r.Context(), "flash", "The comment is longer than 2000 characters")
+		http.Redirect(w, r, fmt.Sprintf("/admin/order/%d", id), http.StatusSeeOther)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	user, err := h.Ctrl.User.Get(r.Context(), userID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	comment := &ordersmodel.Comment{
+		OrderID:  id,
+		AuthorID: userID,
+		Author:   user.Name,
+		Body:     body,
+	}
+
+	_, err = h.Ctrl.Orders.CreateComment(r.Context(), comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Comment added")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/order/%d#comments", id), http.StatusSeeOther)
+}
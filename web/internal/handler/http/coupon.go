@@ -0,0 +1,188 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+// couponForm is the new coupon form. Value is a whole percentage or a
+// decimal amount in the base currency, depending on Kind, and MinBasket a
+// decimal amount. Categories and Products are comma-separated IDs; ExpiresAt
+// is a date, the coupon expiring at the end of that day.
+type couponForm struct {
+	Code                string `form:"code"`
+	Kind                string `form:"kind"`
+	Value               string `form:"value"`
+	MinBasket           string `form:"min_basket"`
+	Categories          string `form:"categories"`
+	Products            string `form:"products"`
+	ExpiresAt           string `form:"expires_at"`
+	UsageLimit          int    `form:"usage_limit"`
+	PerUserLimit        int    `form:"per_user_limit"`
+	validator.Validator `form:"-"`
+}
+
+// coupon builds the coupon from the form, recording the problems with the
+// fields that cannot be parsed or that the coupon rules reject.
+func (f *couponForm) coupon() *ordersmodel.Coupon {
+	coupon := &ordersmodel.Coupon{
+		Code:         f.Code,
+		Kind:         f.Kind,
+		UsageLimit:   f.UsageLimit,
+		PerUserLimit: f.PerUserLimit,
+	}
+	coupon.Normalize()
+
+	var err error
+	switch coupon.Kind {
+	case ordersmodel.CouponPercent:
+		coupon.Value, err = strconv.ParseInt(strings.TrimSpace(f.Value), 10, 64)
+		f.CheckField(err == nil, "value", "Enter a whole percentage")
+	case ordersmodel.CouponFixed:
+		var value money.Money
+		value, err = money.Parse(f.Value, "")
+		coupon.Value = value.Amount
+		f.CheckField(err == nil, "value", "Enter an amount such as 12.50")
+	}
+
+	if strings.TrimSpace(f.MinBasket) != "" {
+		var minBasket money.Money
+		minBasket, err = money.Parse(f.MinBasket, "")
+		coupon.MinBasket = minBasket.Amount
+		f.CheckField(err == nil, "min_basket", "Enter an amount such as 12.50")
+	}
+
+	var ok bool
+	coupon.CategoryIDs, ok = parseIDs(f.Categories)
+	f.CheckField(ok, "categories", "Enter category IDs separated by commas")
+	coupon.ProductIDs, ok = parseIDs(f.Products)
+	f.CheckField(ok, "products", "Enter product IDs separated by commas")
+
+	if f.ExpiresAt != "" {
+		day, err := time.ParseInLocation("2006-01-02", f.ExpiresAt, time.Local)
+		if err != nil {
+			f.AddFieldError("expires_at", "Enter a date as YYYY-MM-DD")
+		} else {
+			expiresAt := day.AddDate(0, 0, 1)
+			coupon.ExpiresAt = &expiresAt
+		}
+	}
+
+	for field, message := range coupon.Validate() {
+		f.AddFieldError(field, capitalize(message))
+	}
+
+	return coupon
+}
+
+func parseIDs(list string) ([]int64, bool) {
+	var ids []int64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || id < 1 {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+func (h *Handler) AdminCoupons(w http.ResponseWriter, r *http.Request) {
+	h.renderAdminCoupons(w, r, http.StatusOK, couponForm{Kind: ordersmodel.CouponPercent})
+}
+
+func (h *Handler) renderAdminCoupons(w http.ResponseWriter, r *http.Request, status int, form couponForm) {
+	coupons, err := h.Ctrl.Orders.Coupons(r.Context())
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Coupons = coupons
+	data.Form = form
+
+	h.render(w, status, "admin_coupons.html", data)
+}
+
+func (h *Handler) AdminCouponCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form couponForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	coupon := form.coupon()
+	if !form.Valid() {
+		h.renderAdminCoupons(w, r, http.StatusUnprocessableEntity, form)
+		return
+	}
+
+	_, err = h.Ctrl.Orders.CreateCoupon(r.Context(), coupon)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrDuplicateName):
+			form.AddFieldError("code", "A coupon with this code already exists")
+			h.renderAdminCoupons(w, r, http.StatusUnprocessableEntity, form)
+		case errors.Is(err, controller.ErrInvalidInput):
+			form.AddNonFieldError("The coupon was rejected by the orders service")
+			h.renderAdminCoupons(w, r, http.StatusUnprocessableEntity, form)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", fmt.Sprintf("Coupon %s created", coupon.Code))
+
+	http.Redirect(w, r, "/admin/coupons", http.StatusSeeOther)
+}
+
+// AdminCouponActivePost enables or disables a coupon.
+func (h *Handler) AdminCouponActivePost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	active, err := strconv.ParseBool(r.PostFormValue("active"))
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.Ctrl.Orders.SetCouponActive(r.Context(), id, active)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	if coupon.Active {
+		h.SessionManager.Put(r.Context(), "flash", fmt.Sprintf("Coupon %s enabled", coupon.Code))
+	} else {
+		h.SessionManager.Put(r.Context(), "flash", fmt.Sprintf("Coupon %s disabled", coupon.Code))
+	}
+
+	http.Redirect(w, r, "/admin/coupons", http.StatusSeeOther)
+}
@@ -6,12 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Maksim-Kot/Commons/money"
 	"github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
 	"github.com/Maksim-Kot/Tech-store-web/internal/contexkeys"
+	webmodel "github.com/Maksim-Kot/Tech-store-web/internal/model"
 
 	"github.com/go-playground/form/v4"
 )
@@ -31,7 +35,7 @@ type processedProduct struct {
 	ID          int64          `json:"id"`
 	Name        string         `json:"name"`
 	Description string         `json:"description,omitempty"`
-	Price       float64        `json:"price"`
+	Price       money.Money    `json:"price"`
 	Quantity    int32          `json:"quantity"`
 	ImageURL    string         `json:"image_url,omitempty"`
 	Attributes  map[string]any `json:"attributes"`
@@ -87,12 +91,34 @@ func (h *Handler) render(w http.ResponseWriter, status int, page string, data *t
 
 func (h *Handler) newTemplateData(r *http.Request) *templateData {
 	return &templateData{
+		Display:         h.display(r),
+		Currencies:      h.Currencies.Codes(),
+		currencies:      h.Currencies,
 		CurrentYear:     time.Now().Year(),
 		Flash:           h.SessionManager.PopString(r.Context(), "flash"),
 		IsAuthenticated: h.IsAuthenticated(r),
+		IsStaff:         h.HasRole(r, webmodel.RoleStaff, webmodel.RoleAdmin),
+		IsAdmin:         h.HasRole(r, webmodel.RoleAdmin),
 	}
 }
 
+// displayCurrency returns the currency the shopper chose to see prices in,
+// or the base currency.
+func (h *Handler) displayCurrency(r *http.Request) string {
+	code := h.SessionManager.GetString(r.Context(), "currency")
+	if !h.Currencies.Supported(code) {
+		return h.Currencies.Base()
+	}
+	return code
+}
+
+// display returns the shopper's display currency with its current rate.
+func (h *Handler) display(r *http.Request) ordersmodel.Display {
+	code := h.displayCurrency(r)
+	rate, _ := h.Currencies.Rate(code)
+	return ordersmodel.Display{Currency: code, Rate: rate}
+}
+
 func (h *Handler) decodePostForm(r *http.Request, dst any) error {
 	err := r.ParseForm()
 	if err != nil {
@@ -113,6 +139,15 @@ func (h *Handler) decodePostForm(r *http.Request, dst any) error {
 	return nil
 }
 
+// HasRole reports whether the authenticated user has any of the given roles.
+func (h *Handler) HasRole(r *http.Request, roles ...string) bool {
+	role, ok := r.Context().Value(contexkeys.UserRoleContextKey).(string)
+	if !ok {
+		return false
+	}
+	return slices.Contains(roles, role)
+}
+
 func (h *Handler) IsAuthenticated(r *http.Request) bool {
 	isAuthenticated, ok := r.Context().Value(contexkeys.IsAuthenticatedContextKey).(bool)
 	if !ok {
@@ -120,3 +155,12 @@ func (h *Handler) IsAuthenticated(r *http.Request) bool {
 	}
 	return isAuthenticated
 }
+
+// capitalize upper-cases the first letter of a message from a backend
+// service so it reads as a sentence on the page.
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
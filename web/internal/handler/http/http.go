@@ -1,14 +1,20 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
 	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
 	"github.com/Maksim-Kot/Tech-store-web/internal/controller/web"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
 	"github.com/Maksim-Kot/Tech-store-web/internal/model"
 	"github.com/Maksim-Kot/Tech-store-web/internal/session"
 	"github.com/Maksim-Kot/Tech-store-web/internal/stocktx"
@@ -22,9 +28,10 @@ type Handler struct {
 	templateCache  map[string]*template.Template
 	formDecoder    *form.Decoder
 	SessionManager session.Manager
+	Currencies     *currency.Table
 }
 
-func New(ctrl *web.Controller, sm session.Manager) (*Handler, error) {
+func New(ctrl *web.Controller, sm session.Manager, currencies *currency.Table) (*Handler, error) {
 	cache, err := newTemplateCache()
 	if err != nil {
 		return nil, err
@@ -35,6 +42,7 @@ func New(ctrl *web.Controller, sm session.Manager) (*Handler, error) {
 		templateCache:  cache,
 		formDecoder:    form.NewDecoder(),
 		SessionManager: sm,
+		Currencies:     currencies,
 	}, nil
 }
 
@@ -113,6 +121,15 @@ func (h *Handler) Product(w http.ResponseWriter, r *http.Request) {
 	data := h.newTemplateData(r)
 	data.Product = processedProduct
 
+	if data.IsAuthenticated {
+		userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+		data.Wishlists, err = h.Ctrl.Wishlist.Wishlists(r.Context(), userID)
+		if err != nil {
+			h.ServerError(w, err)
+			return
+		}
+	}
+
 	h.render(w, http.StatusOK, "product.html", data)
 }
 
@@ -220,12 +237,37 @@ func (h *Handler) UserLoginPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, err := h.Ctrl.User.Get(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
 	err = h.SessionManager.RenewToken(r.Context())
 	if err != nil {
 		h.ServerError(w, err)
 		return
 	}
 
+	if user.TOTPEnabled {
+		h.SessionManager.Put(r.Context(), "twoFactorUserID", id)
+		h.SessionManager.Remove(r.Context(), "twoFactorAttempts")
+		http.Redirect(w, r, "/user/login/2fa", http.StatusSeeOther)
+		return
+	}
+
+	h.completeLogin(w, r, id)
+}
+
+// completeLogin marks the session as authenticated and sends the user back
+// to the page they originally asked for.
+func (h *Handler) completeLogin(w http.ResponseWriter, r *http.Request, id int64) {
+	err := h.mergeSessionCart(r, id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
 	h.SessionManager.Put(r.Context(), "authenticatedUserID", id)
 
 	path := h.SessionManager.PopString(r.Context(), "redirectPathAfterLogin")
@@ -251,6 +293,38 @@ func (h *Handler) UserLogoutPost(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// SetCurrencyPost remembers the currency the shopper wants to see prices in
+// and sends them back to the page they came from.
+func (h *Handler) SetCurrencyPost(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(r.PostForm.Get("currency")))
+	if !h.Currencies.Supported(code) {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "currency", code)
+
+	http.Redirect(w, r, backPath(r), http.StatusSeeOther)
+}
+
+// backPath returns the local path of the page the request came from, or the
+// home page. Only the path is kept so the redirect never leaves the site.
+func backPath(r *http.Request) string {
+	ref, err := url.Parse(r.Referer())
+	if err != nil || !strings.HasPrefix(ref.Path, "/") || strings.HasPrefix(ref.Path, "//") {
+		return "/"
+	}
+	if ref.RawQuery != "" {
+		return ref.Path + "?" + ref.RawQuery
+	}
+	return ref.Path
+}
+
 func (h *Handler) AccountView(w http.ResponseWriter, r *http.Request) {
 	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
 
@@ -271,21 +345,26 @@ func (h *Handler) AccountView(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) ShowCart(w http.ResponseWriter, r *http.Request) {
-	var cart model.Cart
-	cartData := h.SessionManager.Get(r.Context(), "cart")
-	if cartData != nil {
-		cart = cartData.(model.Cart)
+	cart, err := h.loadCart(r)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	checked, err := h.Ctrl.Cart.Check(r.Context(), cart)
+	if err != nil {
+		h.ServerError(w, err)
+		return
 	}
 
 	data := h.newTemplateData(r)
-	data.Cart = &cart
+	data.Cart = checked
 
 	h.render(w, http.StatusOK, "cart.html", data)
 }
 
 func (h *Handler) AddToCart(w http.ResponseWriter, r *http.Request) {
 	idStr := r.FormValue("id")
-	name := r.FormValue("name")
 	quantityStr := r.FormValue("quantity")
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -300,32 +379,57 @@ func (h *Handler) AddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var cart model.Cart
-	cartData := h.SessionManager.Get(r.Context(), "cart")
-	if cartData != nil {
-		cart = cartData.(model.Cart)
-		if cart.Items == nil {
-			cart.Items = make(map[int64]model.Item)
+	if quantity < 1 {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.Ctrl.Catalog.ProductByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
 		}
-	} else {
-		cart = model.Cart{
-			Items: make(map[int64]model.Item),
+		return
+	}
+
+	price, err := h.Currencies.Convert(product.Price, h.Currencies.Base())
+	if err != nil {
+		if !errors.Is(err, currency.ErrUnknownCurrency) {
+			h.ServerError(w, err)
+			return
 		}
+		h.SessionManager.Put(r.Context(), "flash", "This product cannot be bought at the moment")
+		http.Redirect(w, r, fmt.Sprintf("/product/%s", idStr), http.StatusSeeOther)
+		return
 	}
 
-	item, exists := cart.Items[id]
-	if !exists {
-		cart.Items[id] = model.Item{
-			ID:       id,
-			Name:     name,
-			Quantity: int32(quantity),
+	newItem := model.Item{
+		ID:       product.ID,
+		Name:     product.Name,
+		Quantity: int32(quantity),
+		Price:    price,
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if userID != 0 && h.IsAuthenticated(r) {
+		err = h.Ctrl.Cart.AddItem(r.Context(), userID, newItem)
+		if err != nil {
+			h.ServerError(w, err)
+			return
 		}
 	} else {
-		item.Quantity += int32(quantity)
-		cart.Items[id] = item
-	}
+		cart := h.sessionCart(r)
 
-	h.SessionManager.Put(r.Context(), "cart", cart)
+		if item, exists := cart.Items[id]; exists {
+			newItem.Quantity += item.Quantity
+		}
+		cart.Items[id] = newItem
+
+		h.saveSessionCart(r, cart)
+	}
 
 	h.SessionManager.Put(r.Context(), "flash", "Added to cart")
 
@@ -339,14 +443,29 @@ func (h *Handler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cartData := h.SessionManager.Get(r.Context(), "cart")
-	if cartData == nil {
-		h.SessionManager.Put(r.Context(), "flash", "Cart is empty")
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if userID != 0 && h.IsAuthenticated(r) {
+		err = h.Ctrl.Cart.RemoveItem(r.Context(), userID, id)
+		switch {
+		case err == nil:
+			h.SessionManager.Put(r.Context(), "flash", "Item removed from cart")
+		case errors.Is(err, controller.ErrNotFound):
+			h.SessionManager.Put(r.Context(), "flash", "Item not found in cart")
+		default:
+			h.ServerError(w, err)
+			return
+		}
+
 		http.Redirect(w, r, "/cart", http.StatusSeeOther)
 		return
 	}
 
-	cart := cartData.(model.Cart)
+	cart := h.sessionCart(r)
+	if len(cart.Items) == 0 {
+		h.SessionManager.Put(r.Context(), "flash", "Cart is empty")
+		http.Redirect(w, r, "/cart", http.StatusSeeOther)
+		return
+	}
 
 	if _, exists := cart.Items[id]; exists {
 		delete(cart.Items, id)
@@ -355,11 +474,7 @@ func (h *Handler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
 		h.SessionManager.Put(r.Context(), "flash", "Item not found in cart")
 	}
 
-	if len(cart.Items) == 0 {
-		h.SessionManager.Remove(r.Context(), "cart")
-	} else {
-		h.SessionManager.Put(r.Context(), "cart", cart)
-	}
+	h.saveSessionCart(r, cart)
 
 	http.Redirect(w, r, "/cart", http.StatusSeeOther)
 }
@@ -390,29 +505,77 @@ func (h *Handler) Order(w http.ResponseWriter, r *http.Request) {
 	}
 
 	order := model.Order{
+		ID:        purchase.ID,
 		Status:    purchase.Status,
+		Address:   purchase.Address,
+		Promotion: purchase.Promotion,
+		Notes:     purchase.Notes,
 		CreatedAt: purchase.CreatedAt,
 		Price:     purchase.Price,
+		Breakdown: purchase.Breakdown,
+		Display:   purchase.Display,
+	}
+
+	products, err := h.orderProducts(r.Context(), purchase.Items)
+	if err != nil {
+		h.ServerError(w, err)
+		return
 	}
+	order.Products = products
+
+	shipments, err := h.Ctrl.Orders.Shipments(r.Context(), purchase.ID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Order = &order
+	data.Breakdown = &order.Breakdown
+	data.Shipments = orderShipments(&order, shipments)
+	data.showOrder(purchase)
 
-	for _, item := range purchase.Items {
-		product, err := h.Ctrl.Catalog.ProductByID(r.Context(), item.ItemID)
+	if purchase.Status == ordersmodel.StatusDelivered {
+		returns, err := h.Ctrl.Orders.OrderReturns(r.Context(), purchase.ID)
 		if err != nil {
 			h.ServerError(w, err)
 			return
 		}
+		data.Returns = orderReturns(&order, returns)
+	}
 
-		order.Products = append(order.Products, &model.Product{
-			ID:       product.ID,
-			Name:     product.Name,
+	h.render(w, http.StatusOK, "order.html", data)
+}
+
+// orderProducts returns the lines of an order with the current product
+// names, looked up in one round trip. Products no longer in the catalog keep
+// the name they were ordered under.
+func (h *Handler) orderProducts(ctx context.Context, items []ordersmodel.Item) ([]*model.Product, error) {
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ItemID
+	}
+
+	current, _, err := h.Ctrl.Catalog.ProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, 0, len(items))
+	for _, item := range items {
+		name := itemName(item)
+		if product, ok := current[item.ItemID]; ok {
+			name = product.Name
+		}
+
+		products = append(products, &model.Product{
+			ID:       item.ItemID,
+			Name:     name,
 			Quantity: item.Quantity,
 		})
 	}
 
-	data := h.newTemplateData(r)
-	data.Order = &order
-
-	h.render(w, http.StatusOK, "order.html", data)
+	return products, nil
 }
 
 func (h *Handler) OrdersByUser(w http.ResponseWriter, r *http.Request) {
@@ -422,11 +585,19 @@ func (h *Handler) OrdersByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	purchases, err := h.Ctrl.Orders.OrdersByUserID(r.Context(), id)
+	// The history shows orders in every status unless one is asked for.
+	qs := r.URL.Query()
+	status := qs.Get("status")
+	if status != "" && !validator.PermittedValue(status, ordersmodel.Statuses...) {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.Ctrl.Orders.OrdersByUserID(r.Context(), id, status, qs.Get("cursor"))
 	if err != nil {
 		switch {
-		case errors.Is(err, controller.ErrNotFound):
-			h.NotFound(w)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
 		default:
 			h.ServerError(w, err)
 		}
@@ -435,10 +606,11 @@ func (h *Handler) OrdersByUser(w http.ResponseWriter, r *http.Request) {
 
 	var orders []*model.Order
 
-	for _, order := range purchases {
+	for _, order := range page.Orders {
 		orders = append(orders, &model.Order{
 			ID:        order.ID,
 			Price:     order.Price,
+			Display:   order.Display,
 			Status:    order.Status,
 			CreatedAt: order.CreatedAt,
 		})
@@ -446,47 +618,90 @@ func (h *Handler) OrdersByUser(w http.ResponseWriter, r *http.Request) {
 
 	data := h.newTemplateData(r)
 	data.Orders = orders
+	data.Statuses = ordersmodel.Statuses
+	data.Form = status
+	data.NextCursor = page.NextCursor
+	data.FirstPage = qs.Get("cursor") == ""
 
 	h.render(w, http.StatusOK, "orders.html", data)
 }
 
-func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
-	cartData := h.SessionManager.Get(r.Context(), "cart")
-	if cartData == nil {
-		h.SessionManager.Put(r.Context(), "flash", "Cart is empty")
-		http.Redirect(w, r, "/catalog", http.StatusSeeOther)
-		return
-	}
+// checkoutForm holds the delivery address chosen at checkout, either one
+// from the address book or a new one entered on the purchase page, and the
+// coupon code. Apply is set when the shopper only asks to see the discount
+// and Refresh when they ask for the total to the chosen address.
+type checkoutForm struct {
+	AddressID   int64  `form:"address_id"`
+	SaveAddress bool   `form:"save_address"`
+	Coupon      string `form:"coupon"`
+	Apply       bool   `form:"apply"`
+	Refresh     bool   `form:"refresh"`
+	Note        string `form:"note"`
+	GiftMessage string `form:"gift_message"`
+	addressForm
+}
+
+// notes returns the note and gift message entered at checkout.
+func (f *checkoutForm) notes() ordersmodel.Notes {
+	notes := ordersmodel.Notes{Note: f.Note, GiftMessage: f.GiftMessage}
+	notes.Normalize()
+	return notes
+}
 
-	cart := cartData.(model.Cart)
+func (f *checkoutForm) validateNotes() {
+	notes := f.notes()
+	for field, message := range notes.Validate() {
+		f.AddFieldError(field, "This field "+message)
+	}
+}
 
+func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
 	if id == 0 {
 		h.NotFound(w)
 		return
 	}
 
-	order := model.Order{}
+	checked, ok := h.checkoutCart(w, r)
+	if !ok {
+		return
+	}
 
-	for _, item := range cart.Items {
-		product, err := h.Ctrl.Catalog.ProductByID(r.Context(), item.ID)
-		if err != nil {
-			h.ServerError(w, err)
-			return
-		}
+	addresses, err := h.Ctrl.Address.Addresses(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
 
+	form := checkoutForm{SaveAddress: true}
+	if len(addresses) > 0 {
+		form.AddressID = addresses[0].ID
+	}
+
+	quote, err := h.checkoutQuote(r, checked, &form)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.renderPurchase(w, r, http.StatusOK, checked, addresses, form, quote)
+}
+
+func (h *Handler) renderPurchase(w http.ResponseWriter, r *http.Request, status int, checked *model.CheckedCart, addresses []*model.Address, form checkoutForm, quote *ordersmodel.Quote) {
+	order := model.Order{}
+
+	for _, line := range checked.Lines {
 		order.Products = append(order.Products, &model.Product{
-			ID:         product.ID,
-			Name:       product.Name,
-			Quantity:   item.Quantity,
-			Price:      product.Price,
-			TotalPrice: product.Price * float64(item.Quantity),
+			ID:         line.ID,
+			Name:       line.Name,
+			Quantity:   line.OrderQuantity(),
+			Price:      line.CurrentPrice,
+			TotalPrice: line.Total(),
 		})
-
-		order.Price += product.Price * float64(item.Quantity)
 	}
+	order.Price = checked.Total()
 
-	user, err := h.Ctrl.User.Get(r.Context(), id)
+	user, err := h.Ctrl.User.Get(r.Context(), checked.UserID)
 	if err != nil {
 		switch {
 		case errors.Is(err, controller.ErrNotFound):
@@ -500,40 +715,176 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	data := h.newTemplateData(r)
 	data.User = user
 	data.Order = &order
+	data.Addresses = addresses
+	data.Countries = ordersmodel.Countries()
+	data.Form = form
+	data.Quote = quote
+	if quote != nil {
+		data.Breakdown = &quote.Breakdown
+	}
+
+	h.render(w, status, "purchase.html", data)
+}
+
+// rerenderPurchase shows the purchase page again with the shopper's input.
+func (h *Handler) rerenderPurchase(w http.ResponseWriter, r *http.Request, status int, checked *model.CheckedCart, form checkoutForm, quote *ordersmodel.Quote) {
+	addresses, err := h.Ctrl.Address.Addresses(r.Context(), checked.UserID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.renderPurchase(w, r, status, checked, addresses, form, quote)
+}
+
+// checkoutItems returns the order lines of the checked cart at the current
+// prices.
+func checkoutItems(checked *model.CheckedCart) []*model.Item {
+	var items []*model.Item
+	for _, line := range checked.Lines {
+		items = append(items, &model.Item{
+			ID:         line.ID,
+			Quantity:   line.OrderQuantity(),
+			Price:      line.CurrentPrice,
+			CategoryID: line.CategoryID,
+			Weight:     line.Weight,
+		})
+	}
+	return items
+}
+
+// checkoutQuote asks the orders service what the cart costs with the entered
+// coupon, delivered to the chosen address. A coupon that cannot be used adds
+// a field error to the form.
+func (h *Handler) checkoutQuote(r *http.Request, checked *model.CheckedCart, form *checkoutForm) (*ordersmodel.Quote, error) {
+	form.Coupon = strings.TrimSpace(form.Coupon)
+	if form.Coupon == "" && form.Apply {
+		form.AddFieldError("coupon", "Enter a coupon code")
+	}
+
+	address, err := h.quoteAddress(r, checked.UserID, form)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := h.Ctrl.Orders.Quote(r.Context(), checked.UserID, checkoutItems(checked), address, form.Coupon)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.Coupon != nil && !quote.Coupon.Valid {
+		form.AddFieldError("coupon", capitalize(quote.Coupon.Reason))
+	}
+
+	return quote, nil
+}
+
+// quoteAddress returns the address to price the order for: the chosen one
+// from the address book or the new one as far as it has been entered. It is
+// nil when there is nothing to go by yet, and the default rates apply.
+func (h *Handler) quoteAddress(r *http.Request, userID int64, form *checkoutForm) (*ordersmodel.Address, error) {
+	if form.AddressID > 0 {
+		address, err := h.Ctrl.Address.Address(r.Context(), userID, form.AddressID)
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			return nil, nil
+		case err != nil:
+			return nil, err
+		}
+		return &address.Address, nil
+	}
+
+	if strings.TrimSpace(form.Country) == "" {
+		return nil, nil
+	}
 
-	h.render(w, http.StatusOK, "purchase.html", data)
+	address := form.address()
+	return &address, nil
 }
 
-type OrderForm struct {
-	UserID            int64   `form:"user_id"`
-	Total             float64 `form:"total"`
-	ProductIDs        []int64 `form:"product_id"`
-	ProductQuantities []int32 `form:"product_quantity"`
+// checkoutAddress returns the delivery address for the order, saving a newly
+// entered one in the address book when asked to. It reports false after
+// re-rendering the purchase page with the validation errors.
+func (h *Handler) checkoutAddress(w http.ResponseWriter, r *http.Request, checked *model.CheckedCart, form checkoutForm, quote *ordersmodel.Quote) (*ordersmodel.Address, bool) {
+	if form.AddressID > 0 {
+		address, err := h.Ctrl.Address.Address(r.Context(), checked.UserID, form.AddressID)
+		if err == nil {
+			return &address.Address, true
+		}
+		if !errors.Is(err, controller.ErrNotFound) {
+			h.ServerError(w, err)
+			return nil, false
+		}
+		form.AddNonFieldError("The selected address no longer exists")
+	} else {
+		form.validate()
+	}
+
+	if !form.Valid() {
+		h.rerenderPurchase(w, r, http.StatusUnprocessableEntity, checked, form, quote)
+		return nil, false
+	}
+
+	address := form.address()
+
+	if form.SaveAddress {
+		entry := &model.Address{UserID: checked.UserID, Address: address}
+		if err := h.Ctrl.Address.Create(r.Context(), entry); err != nil {
+			h.ServerError(w, err)
+			return nil, false
+		}
+	}
+
+	return &address, true
 }
 
 func (h *Handler) CreateOrderPost(w http.ResponseWriter, r *http.Request) {
-	var form OrderForm
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if userID == 0 {
+		h.NotFound(w)
+		return
+	}
+
+	checked, ok := h.checkoutCart(w, r)
+	if !ok {
+		return
+	}
+
+	var form checkoutForm
 
 	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validateNotes()
+
+	quote, err := h.checkoutQuote(r, checked, &form)
 	if err != nil {
 		h.ServerError(w, err)
 		return
 	}
 
-	var orderItems []*model.Item
-	var txItems []stocktx.Item
-	for i := range form.ProductIDs {
-		id := form.ProductIDs[i]
-		quantity := form.ProductQuantities[i]
+	if form.Apply || form.Refresh || !form.Valid() {
+		status := http.StatusOK
+		if !form.Valid() {
+			status = http.StatusUnprocessableEntity
+		}
+		h.rerenderPurchase(w, r, status, checked, form, quote)
+		return
+	}
 
-		orderItems = append(orderItems, &model.Item{
-			ID:       id,
-			Quantity: quantity,
-		})
+	address, ok := h.checkoutAddress(w, r, checked, form, quote)
+	if !ok {
+		return
+	}
 
+	var txItems []stocktx.Item
+	for _, line := range checked.Lines {
 		txItems = append(txItems, stocktx.Item{
-			ProductID: id,
-			Amount:    quantity,
+			ProductID: line.ID,
+			Amount:    line.OrderQuantity(),
 		})
 	}
 
@@ -545,14 +896,25 @@ func (h *Handler) CreateOrderPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.Ctrl.Orders.CreateOrder(r.Context(), form.UserID, form.Total, orderItems)
+	id, err := h.Ctrl.Orders.CreateOrder(r.Context(), userID, checkoutItems(checked), address, form.Coupon, h.display(r), form.notes())
 	if err != nil {
 		txManager.Rollback(r.Context(), reserved)
+
+		if errors.Is(err, controller.ErrInvalidCoupon) {
+			form.AddFieldError("coupon", "This coupon can no longer be applied to your order")
+			h.rerenderPurchase(w, r, http.StatusUnprocessableEntity, checked, form, nil)
+			return
+		}
+
 		h.ServerError(w, err)
 		return
 	}
 
 	h.SessionManager.Remove(r.Context(), "cart")
 
-	http.Redirect(w, r, fmt.Sprintf("/account/order/%d", id), http.StatusSeeOther)
+	if err := h.Ctrl.Cart.Clear(r.Context(), userID); err != nil {
+		log.Printf("[cart] failed to clear cart of user %d after order %d: %v", userID, id, err)
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/account/order/%d/pay", id), http.StatusSeeOther)
 }
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Maksim-Kot/Commons/money"
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/pdf"
+)
+
+// sellerName heads every invoice.
+const sellerName = "Tech Store"
+
+func (h *Handler) OrderInvoice(w http.ResponseWriter, r *http.Request) {
+	invoice, ok := h.userInvoice(w, r)
+	if !ok {
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Invoice = invoice
+
+	h.render(w, http.StatusOK, "invoice.html", data)
+}
+
+func (h *Handler) OrderInvoicePDF(w http.ResponseWriter, r *http.Request) {
+	invoice, ok := h.userInvoice(w, r)
+	if !ok {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := invoicePDF(invoice).WriteTo(buf); err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoice.Number+".pdf"))
+
+	buf.WriteTo(w)
+}
+
+// userInvoice returns the invoice of the authenticated user's order. Orders
+// that have not been paid have no invoice; the user is sent back to the
+// order page. It reports false after writing a response.
+func (h *Handler) userInvoice(w http.ResponseWriter, r *http.Request) (*ordersmodel.Invoice, bool) {
+	order, ok := h.userOrder(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	invoice, err := h.Ctrl.Orders.Invoice(r.Context(), order.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "An invoice is issued once the order has been paid")
+			http.Redirect(w, r, fmt.Sprintf("/account/order/%d", order.ID), http.StatusSeeOther)
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return nil, false
+	}
+
+	return invoice, true
+}
+
+// itemName is the name of an invoiced product. Orders placed before product
+// names were stored with them show the product ID instead.
+func itemName(item ordersmodel.Item) string {
+	if item.Name == "" {
+		return fmt.Sprintf("Product #%d", item.ItemID)
+	}
+	return item.Name
+}
+
+// invoicePDF lays the invoice out on A4 pages. Like the HTML invoice it is
+// drawn from the order snapshot only.
+func invoicePDF(invoice *ordersmodel.Invoice) *pdf.Document {
+	const (
+		left   = 56.0
+		right  = pdf.PageWidth - 56
+		bottom = pdf.PageHeight - 72
+		size   = 10.0
+		line   = 15.0
+	)
+
+	// Right edges of the number columns of the lines table.
+	var (
+		qtyX      = right - 250.0
+		priceX    = right - 170.0
+		discountX = right - 85.0
+	)
+
+	order := invoice.Order
+	doc := pdf.New("Invoice " + invoice.Number)
+	page := doc.AddPage()
+	y := 72.0
+
+	page.Text(left, y, pdf.Bold, 20, "Invoice "+invoice.Number)
+	page.TextRight(right, y, pdf.Bold, 14, sellerName)
+	y += 2 * line
+
+	page.Text(left, y, pdf.Regular, size, "Issued: "+invoice.IssuedAt.Format("02 Jan 2006"))
+	y += line
+	page.Text(left, y, pdf.Regular, size, fmt.Sprintf("Order: #%d of %s", order.ID, order.CreatedAt.Format("02 Jan 2006")))
+	y += line
+	page.Text(left, y, pdf.Regular, size, "Currency: "+order.Price.Currency)
+	y += 2 * line
+
+	if address := order.Address; address != nil {
+		page.Text(left, y, pdf.Bold, size, "Bill to")
+		y += line
+		for _, text := range addressLines(address) {
+			page.Text(left, y, pdf.Regular, size, text)
+			y += line
+		}
+		y += line
+	}
+
+	header := func() {
+		page.Text(left, y, pdf.Bold, size, "Product")
+		page.TextRight(qtyX, y, pdf.Bold, size, "Qty")
+		page.TextRight(priceX, y, pdf.Bold, size, "Unit price")
+		page.TextRight(discountX, y, pdf.Bold, size, "Discount")
+		page.TextRight(right, y, pdf.Bold, size, "Amount")
+		y += 6
+		page.Line(left, y, right, y, 0.5)
+		y += line
+	}
+	header()
+
+	nameWidth := qtyX - 40 - left
+	for _, item := range order.Items {
+		if y > bottom {
+			page = doc.AddPage()
+			y = 72
+			header()
+		}
+
+		page.Text(left, y, pdf.Regular, size, fit(itemName(item), nameWidth, size))
+		page.TextRight(qtyX, y, pdf.Regular, size, fmt.Sprint(item.Quantity))
+		page.TextRight(priceX, y, pdf.Regular, size, item.Price.Decimal())
+		page.TextRight(discountX, y, pdf.Regular, size, item.Discount.Decimal())
+		page.TextRight(right, y, pdf.Regular, size, item.Total().Sub(item.Discount).Decimal())
+		y += line
+	}
+
+	if y > bottom-6*line {
+		page = doc.AddPage()
+		y = 72
+	}
+
+	y -= line - 6
+	page.Line(left, y, right, y, 0.5)
+	y += line
+
+	total := func(label string, amount money.Money, font pdf.Font) {
+		page.TextRight(discountX, y, font, size, label)
+		page.TextRight(right, y, font, size, amount.String())
+		y += line
+	}
+
+	if order.Subtotal.Amount != 0 {
+		total("Subtotal", order.Subtotal, pdf.Regular)
+		if order.Discount.Amount != 0 {
+			label := "Discount"
+			if order.Promotion != nil {
+				label += " (" + order.Promotion.Code + ")"
+			}
+			total(label, money.New(-order.Discount.Amount, order.Discount.Currency), pdf.Regular)
+		}
+		total("Shipping", order.Shipping, pdf.Regular)
+		total(fmt.Sprintf("Tax (%.2f%%)", order.TaxRate), order.Tax, pdf.Regular)
+	}
+	total("Total", order.Price, pdf.Bold)
+
+	y += line
+	page.Text(left, y, pdf.Regular, size, "Paid in full. Thank you for shopping with "+sellerName+".")
+
+	return doc
+}
+
+// addressLines formats an address the way the address partial does.
+func addressLines(a *ordersmodel.Address) []string {
+	lines := []string{a.Name, a.Line1}
+	if a.Line2 != "" {
+		lines = append(lines, a.Line2)
+	}
+
+	city := a.City
+	if a.Region != "" {
+		city += ", " + a.Region
+	}
+	lines = append(lines, strings.TrimSpace(city+" "+a.PostalCode), a.Country)
+
+	if a.Phone != "" {
+		lines = append(lines, a.Phone)
+	}
+	return lines
+}
+
+// fit shortens s with an ellipsis until it is at most width points wide.
+func fit(s string, width, size float64) string {
+	if pdf.Width(s, pdf.Regular, size) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.Width(string(runes)+"…", pdf.Regular, size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
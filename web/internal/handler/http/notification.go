@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+type notificationsForm struct {
+	OrderPlaced bool `form:"order_placed"`
+	OrderStatus bool `form:"order_status"`
+}
+
+func (h *Handler) Notifications(w http.ResponseWriter, r *http.Request) {
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	prefs, err := h.Ctrl.User.NotificationPreferences(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Form = notificationsForm{
+		OrderPlaced: prefs.OrderPlaced,
+		OrderStatus: prefs.OrderStatus,
+	}
+
+	h.render(w, http.StatusOK, "notifications.html", data)
+}
+
+func (h *Handler) NotificationsPost(w http.ResponseWriter, r *http.Request) {
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	var form notificationsForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	prefs := &model.NotificationPreferences{
+		UserID:      id,
+		OrderPlaced: form.OrderPlaced,
+		OrderStatus: form.OrderStatus,
+	}
+
+	err = h.Ctrl.User.SetNotificationPreferences(r.Context(), prefs)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Notification preferences saved")
+
+	http.Redirect(w, r, "/account/view", http.StatusSeeOther)
+}
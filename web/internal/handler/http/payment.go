@@ -0,0 +1,150 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+type paymentForm struct {
+	Holder              string `form:"holder"`
+	Number              string `form:"number"`
+	ExpMonth            int    `form:"exp_month"`
+	ExpYear             int    `form:"exp_year"`
+	CVC                 string `form:"cvc"`
+	validator.Validator `form:"-"`
+}
+
+func (f *paymentForm) card() ordersmodel.Card {
+	card := ordersmodel.Card{
+		Holder:   f.Holder,
+		Number:   f.Number,
+		ExpMonth: f.ExpMonth,
+		ExpYear:  f.ExpYear,
+		CVC:      f.CVC,
+	}
+	card.Normalize()
+
+	return card
+}
+
+func (f *paymentForm) validate() {
+	card := f.card()
+	for field, message := range card.Validate() {
+		f.AddFieldError(field, "This field "+message)
+	}
+}
+
+// userOrder returns the order if it belongs to the authenticated user. It
+// reports false after writing a not found or server error response.
+func (h *Handler) userOrder(w http.ResponseWriter, r *http.Request) (*ordersmodel.Order, bool) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return nil, false
+	}
+
+	order, err := h.Ctrl.Orders.OrderByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return nil, false
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+	if order.UserID != userID {
+		h.NotFound(w)
+		return nil, false
+	}
+
+	return order, true
+}
+
+func (h *Handler) OrderPayment(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.userOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if order.Status != ordersmodel.StatusCreated {
+		http.Redirect(w, r, fmt.Sprintf("/account/order/%d", order.ID), http.StatusSeeOther)
+		return
+	}
+
+	h.renderPayment(w, r, http.StatusOK, order, paymentForm{})
+}
+
+func (h *Handler) renderPayment(w http.ResponseWriter, r *http.Request, status int, order *ordersmodel.Order, form paymentForm) {
+	// Card details are never sent back to the browser.
+	form.Number = ""
+	form.CVC = ""
+
+	data := h.newTemplateData(r)
+	data.Order = &model.Order{
+		ID:      order.ID,
+		Price:   order.Price,
+		Display: order.Display,
+		Status:  order.Status,
+	}
+	data.Form = form
+
+	h.render(w, status, "payment.html", data)
+}
+
+func (h *Handler) OrderPaymentPost(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.userOrder(w, r)
+	if !ok {
+		return
+	}
+
+	var form paymentForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate()
+
+	if !form.Valid() {
+		h.renderPayment(w, r, http.StatusUnprocessableEntity, order, form)
+		return
+	}
+
+	payment, err := h.Ctrl.Orders.PayOrder(r.Context(), order.ID, form.card())
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "This order can no longer be paid")
+			http.Redirect(w, r, fmt.Sprintf("/account/order/%d", order.ID), http.StatusSeeOther)
+		case errors.Is(err, controller.ErrInvalidInput):
+			form.AddNonFieldError("Please check your card details")
+			h.renderPayment(w, r, http.StatusUnprocessableEntity, order, form)
+		case errors.Is(err, controller.ErrPaymentDeclined):
+			form.AddNonFieldError(fmt.Sprintf("Your payment was declined: %s", payment.Error))
+			h.renderPayment(w, r, http.StatusUnprocessableEntity, order, form)
+		case errors.Is(err, controller.ErrPaymentUnavailable):
+			form.AddNonFieldError("We could not reach the payment service. Please try again in a few minutes")
+			h.renderPayment(w, r, http.StatusServiceUnavailable, order, form)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Payment received, thank you!")
+
+	http.Redirect(w, r, fmt.Sprintf("/account/order/%d", order.ID), http.StatusSeeOther)
+}
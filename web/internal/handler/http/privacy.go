@@ -0,0 +1,229 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+// privacyForm picks the user a data access or erasure request is about.
+// Erasing also asks for the email address of the user, typed out, so that a
+// wrong ID does not erase someone else.
+type privacyForm struct {
+	UserID              string `form:"user_id"`
+	Email               string `form:"email"`
+	validator.Validator `form:"-"`
+}
+
+// privacyUser looks up the user the form is about, recording an error for
+// the field when the ID is not a number or there is no such user.
+func (h *Handler) privacyUser(ctx context.Context, form *privacyForm, field string) (*model.User, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(form.UserID), 10, 64)
+	if err != nil || id < 1 {
+		form.AddFieldError(field, "Enter a user ID")
+		return nil, nil
+	}
+
+	user, err := h.Ctrl.User.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, controller.ErrNotFound) {
+			form.AddFieldError(field, "There is no user with this ID")
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (h *Handler) AdminPrivacy(w http.ResponseWriter, r *http.Request) {
+	data := h.newTemplateData(r)
+	data.Form = privacyForm{}
+
+	h.render(w, http.StatusOK, "admin_privacy.html", data)
+}
+
+// AdminPrivacyExport downloads everything held about a user as a ZIP of
+// JSON files, one per kind of data.
+func (h *Handler) AdminPrivacyExport(w http.ResponseWriter, r *http.Request) {
+	form := privacyForm{UserID: r.URL.Query().Get("user_id")}
+
+	user, err := h.privacyUser(r.Context(), &form, "export_user_id")
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	if !form.Valid() {
+		data := h.newTemplateData(r)
+		data.Form = form
+		h.render(w, http.StatusUnprocessableEntity, "admin_privacy.html", data)
+		return
+	}
+
+	archive, err := h.userDataArchive(r.Context(), user)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("user-%d.zip", user.ID)))
+	w.Write(archive)
+}
+
+// userDataArchive collects the data of the user from this service and the
+// orders service into a ZIP. The password hash and two-factor secret are
+// left out: they are of no use to the user and would only weaken the
+// account.
+func (h *Handler) userDataArchive(ctx context.Context, user *model.User) ([]byte, error) {
+	profile := struct {
+		ID        int64     `json:"id"`
+		Name      string    `json:"name"`
+		Email     string    `json:"email"`
+		Role      string    `json:"role"`
+		TwoFactor bool      `json:"two_factor"`
+		Created   time.Time `json:"created"`
+	}{user.ID, user.Name, user.Email, user.Role, user.TOTPEnabled, user.Created}
+
+	sessions, err := h.SessionManager.UserSessions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := h.Ctrl.Cart.Cart(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]model.Item, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		items = append(items, item)
+	}
+	slices.SortFunc(items, func(a, b model.Item) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	wishlists, err := h.Ctrl.Wishlist.Wishlists(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := h.Ctrl.Address.Addresses(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := h.Ctrl.User.NotificationPreferences(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := h.Ctrl.Orders.UserData(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"sessions.json", sessions},
+		{"cart.json", items},
+		{"wishlists.json", wishlists},
+		{"addresses.json", addresses},
+		{"notifications.json", notifications},
+		{"orders.json", json.RawMessage(orders)},
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// AdminPrivacyErasePost anonymises a customer. The orders service goes
+// first, so that a failure there leaves the account as it was and the
+// request can be repeated. Staff and admin accounts are not erased this
+// way; their role has to be taken away first.
+func (h *Handler) AdminPrivacyErasePost(w http.ResponseWriter, r *http.Request) {
+	var form privacyForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.privacyUser(r.Context(), &form, "user_id")
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	if user != nil {
+		form.CheckField(strings.EqualFold(strings.TrimSpace(form.Email), user.Email), "email", "This is not the email address of the user")
+		form.CheckField(user.HasRole(model.RoleCustomer), "user_id", "Only customer accounts can be erased")
+	}
+
+	if !form.Valid() {
+		data := h.newTemplateData(r)
+		data.Form = form
+		h.render(w, http.StatusUnprocessableEntity, "admin_privacy.html", data)
+		return
+	}
+
+	orders, err := h.Ctrl.Orders.EraseUser(r.Context(), user.ID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	err = h.Ctrl.User.Erase(r.Context(), user.ID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	err = h.SessionManager.DestroyUserSessions(r.Context(), user.ID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", fmt.Sprintf("User %d erased, %d orders anonymised", user.ID, orders))
+
+	http.Redirect(w, r, "/admin/privacy", http.StatusSeeOther)
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// OrderReorderPost puts the lines of one of the user's orders back in their
+// cart and tells them what could not be added in full.
+func (h *Handler) OrderReorderPost(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.userOrder(w, r)
+	if !ok {
+		return
+	}
+
+	lines := make([]model.Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, model.Item{ID: item.ItemID, Name: itemName(item), Quantity: item.Quantity})
+	}
+
+	result, err := h.Ctrl.Cart.Reorder(r.Context(), order.UserID, lines)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", reorderMessage(result))
+
+	if result.Added == 0 {
+		http.Redirect(w, r, fmt.Sprintf("/account/order/%d", order.ID), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
+
+func reorderMessage(result *model.Reorder) string {
+	var msg []string
+
+	switch result.Added {
+	case 0:
+		msg = append(msg, "Nothing from this order could be added to your cart.")
+	case 1:
+		msg = append(msg, "Added 1 product to your cart.")
+	default:
+		msg = append(msg, fmt.Sprintf("Added %d products to your cart.", result.Added))
+	}
+
+	if len(result.Gone) > 0 {
+		msg = append(msg, "No longer sold: "+strings.Join(result.Gone, ", ")+".")
+	}
+	if len(result.SoldOut) > 0 {
+		msg = append(msg, "Out of stock: "+strings.Join(result.SoldOut, ", ")+".")
+	}
+	if len(result.Capped) > 0 {
+		msg = append(msg, "Only part of the quantity is in stock: "+strings.Join(result.Capped, ", ")+".")
+	}
+
+	return strings.Join(msg, " ")
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/chart"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller/orders"
+)
+
+// reportParams are the query parameters the report pages pass on to the
+// orders service.
+var reportParams = []string{"from", "to", "interval", "by"}
+
+type reportForm struct {
+	From     string
+	To       string
+	Interval string
+	query    url.Values
+}
+
+// CSV links the report of the page as CSV; extra are key-value pairs added
+// to the query.
+func (f reportForm) CSV(name string, extra ...string) template.URL {
+	query := url.Values{}
+	for key, values := range f.query {
+		query[key] = values
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		query.Set(extra[i], extra[i+1])
+	}
+
+	u := "/admin/reports/" + name + "/csv"
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return template.URL(u)
+}
+
+// reportView is the sales dashboard: the reports and their charts.
+type reportView struct {
+	*orders.Report
+	Revenue    template.HTML
+	OrderCount template.HTML
+	ByQuantity template.HTML
+	ByRevenue  template.HTML
+	ByStatus   template.HTML
+}
+
+func (h *Handler) AdminReports(w http.ResponseWriter, r *http.Request) {
+	query := reportQuery(r)
+
+	report, err := h.Ctrl.Orders.Report(r.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Report = newReportView(report)
+	// The range the service picked shows in the form; the end it reports is
+	// exclusive.
+	data.Form = reportForm{
+		From:     report.Sales.From.Format(time.DateOnly),
+		To:       report.Sales.To.AddDate(0, 0, -1).Format(time.DateOnly),
+		Interval: report.Sales.Interval,
+		query:    query,
+	}
+
+	h.render(w, http.StatusOK, "admin_reports.html", data)
+}
+
+// AdminReportCSV downloads the sales, products or statuses report as CSV.
+func (h *Handler) AdminReportCSV(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name != "sales" && name != "products" && name != "statuses" {
+		h.NotFound(w)
+		return
+	}
+
+	csv, err := h.Ctrl.Orders.ReportCSV(r.Context(), name, reportQuery(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".csv"))
+	w.Write(csv)
+}
+
+// reportQuery keeps the report parameters of the request that are set.
+func reportQuery(r *http.Request) url.Values {
+	query := url.Values{}
+	for _, key := range reportParams {
+		if value := strings.TrimSpace(r.URL.Query().Get(key)); value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
+
+func newReportView(report *orders.Report) *reportView {
+	sales := report.Sales
+
+	var revenue, count []chart.Bar
+	for _, p := range sales.Periods {
+		label := p.Start.Format("02 Jan")
+		if sales.Interval == ordersmodel.IntervalMonth {
+			label = p.Start.Format("Jan 2006")
+		}
+
+		revenue = append(revenue, chart.Bar{Label: label, Value: major(p.Revenue.Amount), Text: p.Revenue.String()})
+		count = append(count, chart.Bar{Label: label, Value: float64(p.Orders), Text: fmt.Sprint(p.Orders)})
+	}
+
+	var quantity, productRevenue, status []chart.Bar
+	for _, p := range report.ByQuantity {
+		quantity = append(quantity, chart.Bar{Label: productLabel(p), Value: float64(p.Quantity), Text: fmt.Sprint(p.Quantity)})
+	}
+	for _, p := range report.ByRevenue {
+		productRevenue = append(productRevenue, chart.Bar{Label: productLabel(p), Value: major(p.Revenue.Amount), Text: p.Revenue.String()})
+	}
+	for _, s := range report.StatusSales {
+		status = append(status, chart.Bar{Label: s.Status, Value: float64(s.Orders), Text: fmt.Sprint(s.Orders)})
+	}
+
+	return &reportView{
+		Report:     report,
+		Revenue:    chart.Columns(revenue, func(v float64) string { return fmt.Sprintf("%.0f", v) }),
+		OrderCount: chart.Columns(count, func(v float64) string { return fmt.Sprintf("%g", v) }),
+		ByQuantity: chart.Rows(quantity),
+		ByRevenue:  chart.Rows(productRevenue),
+		ByStatus:   chart.Rows(status),
+	}
+}
+
+func productLabel(p *ordersmodel.ProductSales) string {
+	return itemName(ordersmodel.Item{ItemID: p.ItemID, Name: p.Name})
+}
+
+// major converts minor units to major ones for charting.
+func major(amount int64) float64 {
+	return float64(amount) / 100
+}
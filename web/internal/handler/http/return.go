@@ -0,0 +1,302 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+// orderReturns names the products of the return requests and works out how
+// many units of every order line can still be returned.
+func orderReturns(order *model.Order, returns []*ordersmodel.Return) []*model.Return {
+	names := make(map[int64]string, len(order.Products))
+	requested := make(map[int64]int32)
+
+	views := make([]*model.Return, 0, len(returns))
+	for _, ret := range returns {
+		if ret.Status != ordersmodel.ReturnRejected {
+			requested[ret.ItemID] += ret.Quantity
+		}
+	}
+
+	for _, product := range order.Products {
+		names[product.ID] = product.Name
+		product.Returnable = max(product.Quantity-requested[product.ID], 0)
+	}
+
+	for _, ret := range returns {
+		views = append(views, &model.Return{Return: ret, ProductName: names[ret.ItemID]})
+	}
+
+	return views
+}
+
+func (h *Handler) OrderReturnPost(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.userOrder(w, r)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.PostForm.Get("item_id"), 10, 64)
+	if err != nil || itemID < 1 {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	quantity, err := strconv.ParseInt(r.PostForm.Get("quantity"), 10, 32)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	reason := strings.TrimSpace(r.PostForm.Get("reason"))
+
+	redirect := fmt.Sprintf("/account/order/%d", order.ID)
+
+	switch {
+	case quantity < 1:
+		h.SessionManager.Put(r.Context(), "flash", "Choose how many units you want to return")
+	case !validator.NotBlank(reason):
+		h.SessionManager.Put(r.Context(), "flash", "Please tell us why you are returning the product")
+	case !validator.MaxChars(reason, 500):
+		h.SessionManager.Put(r.Context(), "flash", "The reason cannot be more than 500 characters long")
+	default:
+		_, err = h.Ctrl.Orders.CreateReturn(r.Context(), order.ID, itemID, int32(quantity), reason)
+		switch {
+		case err == nil:
+			h.SessionManager.Put(r.Context(), "flash", "Your return request has been sent")
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+			return
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.SessionManager.Put(r.Context(), "flash", "You cannot return more units than you ordered")
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "Only delivered orders can be returned")
+		default:
+			h.ServerError(w, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+func (h *Handler) AdminReturns(w http.ResponseWriter, r *http.Request) {
+	// The queue shows pending requests unless another status, or "all", is
+	// asked for explicitly.
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = ordersmodel.ReturnRequested
+	}
+
+	if !validator.PermittedValue(status, "all", ordersmodel.ReturnRequested, ordersmodel.ReturnApproved, ordersmodel.ReturnRejected) {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	filter := status
+	if filter == "all" {
+		filter = ""
+	}
+
+	returns, err := h.Ctrl.Orders.Returns(r.Context(), filter)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	views := make([]*model.Return, 0, len(returns))
+	for _, ret := range returns {
+		views = append(views, &model.Return{Return: ret})
+	}
+
+	data := h.newTemplateData(r)
+	data.Returns = views
+	data.Statuses = []string{ordersmodel.ReturnRequested, ordersmodel.ReturnApproved, ordersmodel.ReturnRejected, "all"}
+	data.Form = status
+
+	h.render(w, http.StatusOK, "admin_returns.html", data)
+}
+
+func (h *Handler) AdminReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	ret, err := h.Ctrl.Orders.Return(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	view := &model.Return{Return: ret}
+
+	product, err := h.Ctrl.Catalog.ProductByID(r.Context(), ret.ItemID)
+	switch {
+	case err == nil:
+		view.ProductName = product.Name
+	case !errors.Is(err, controller.ErrNotFound):
+		h.ServerError(w, err)
+		return
+	}
+
+	payments, err := h.Ctrl.Orders.Payments(r.Context(), ret.OrderID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Return = view
+	data.Payments = payments
+	data.showBase()
+
+	h.render(w, http.StatusOK, "admin_return.html", data)
+}
+
+func (h *Handler) AdminReturnApprovePost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	ret, err := h.Ctrl.Orders.ApproveReturn(r.Context(), id, r.PostFormValue("note"))
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "The return could not be approved: it was already resolved or the refund failed")
+			http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	if err := h.restockReturn(r, ret); err != nil {
+		log.Printf("[returns] failed to restock return %d: %v", id, err)
+		h.SessionManager.Put(r.Context(), "flash", "Return approved and refunded, but restocking failed. Try restocking again")
+	} else {
+		h.SessionManager.Put(r.Context(), "flash", "Return approved, refunded and restocked")
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+}
+
+func (h *Handler) AdminReturnRejectPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	_, err = h.Ctrl.Orders.RejectReturn(r.Context(), id, r.PostFormValue("note"))
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.ClientError(w, http.StatusBadRequest)
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "The return has already been resolved")
+			http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Return rejected")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+}
+
+// AdminReturnRestockPost retries restocking an approved return whose units
+// did not make it back into the catalog.
+func (h *Handler) AdminReturnRestockPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	ret, err := h.Ctrl.Orders.Return(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	if ret.Status != ordersmodel.ReturnApproved || ret.Restocked {
+		h.SessionManager.Put(r.Context(), "flash", "This return cannot be restocked")
+		http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+		return
+	}
+
+	err = h.restockReturn(r, ret)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "This return has already been restocked")
+			http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Return restocked")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/return/%d", id), http.StatusSeeOther)
+}
+
+// restockReturn puts the returned units back into the catalog. The return is
+// marked as restocked first, which only one request can do, so the stock is
+// never increased twice; if increasing it fails, the mark is taken back so
+// that restocking can be tried again. A return that is already restocked
+// gives controller.ErrEditConflict.
+func (h *Handler) restockReturn(r *http.Request, ret *ordersmodel.Return) error {
+	_, err := h.Ctrl.Orders.MarkReturnRestocked(r.Context(), ret.ID)
+	if err != nil {
+		return err
+	}
+
+	err = h.Ctrl.Catalog.IncreaseProductQuantity(r.Context(), ret.ItemID, ret.Quantity)
+	if err != nil {
+		if _, undoErr := h.Ctrl.Orders.UnmarkReturnRestocked(r.Context(), ret.ID); undoErr != nil {
+			log.Printf("[returns] return %d is marked restocked but its stock was not increased: %v", ret.ID, undoErr)
+		}
+		return err
+	}
+
+	return nil
+}
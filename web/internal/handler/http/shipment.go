@@ -0,0 +1,141 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// orderShipments names the products of the shipments and works out how many
+// units of every order line are still to be shipped.
+func orderShipments(order *model.Order, shipments []*ordersmodel.Shipment) []*model.Shipment {
+	names := make(map[int64]string, len(order.Products))
+	shipped := make(map[int64]int32)
+
+	for _, shipment := range shipments {
+		for _, line := range shipment.Lines {
+			shipped[line.ItemID] += line.Quantity
+		}
+	}
+
+	for _, product := range order.Products {
+		names[product.ID] = product.Name
+		product.Unshipped = max(product.Quantity-shipped[product.ID], 0)
+	}
+
+	views := make([]*model.Shipment, 0, len(shipments))
+	for _, shipment := range shipments {
+		view := &model.Shipment{Shipment: shipment}
+		for _, line := range shipment.Lines {
+			view.Products = append(view.Products, &model.Product{
+				ID:       line.ItemID,
+				Name:     names[line.ItemID],
+				Quantity: line.Quantity,
+			})
+		}
+		views = append(views, view)
+	}
+
+	return views
+}
+
+// AdminOrderShipmentPost records a shipment of the units entered for every
+// line of the order.
+func (h *Handler) AdminOrderShipmentPost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil || id < 1 {
+		h.NotFound(w)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.Ctrl.Orders.OrderByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	shipment := &ordersmodel.Shipment{
+		OrderID:        id,
+		Carrier:        r.PostForm.Get("carrier"),
+		TrackingNumber: r.PostForm.Get("tracking_number"),
+	}
+
+	for _, item := range order.Items {
+		value := r.PostForm.Get(fmt.Sprintf("quantity_%d", item.ItemID))
+		if value == "" {
+			continue
+		}
+
+		quantity, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || quantity < 0 {
+			h.ClientError(w, http.StatusBadRequest)
+			return
+		}
+		if quantity > 0 {
+			shipment.Lines = append(shipment.Lines, ordersmodel.ShipmentLine{ItemID: item.ItemID, Quantity: int32(quantity)})
+		}
+	}
+
+	shipment.Normalize()
+	if errs := shipment.Validate(); len(errs) > 0 {
+		h.SessionManager.Put(r.Context(), "flash", shipmentError(errs))
+		http.Redirect(w, r, fmt.Sprintf("/admin/order/%d#shipments", id), http.StatusSeeOther)
+		return
+	}
+
+	_, err = h.Ctrl.Orders.CreateShipment(r.Context(), shipment)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrEditConflict):
+			h.SessionManager.Put(r.Context(), "flash", "This order cannot be shipped in its current status")
+			http.Redirect(w, r, fmt.Sprintf("/admin/order/%d", id), http.StatusSeeOther)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.SessionManager.Put(r.Context(), "flash", "The shipment has more units than are left to ship")
+			http.Redirect(w, r, fmt.Sprintf("/admin/order/%d#shipments", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Shipment recorded")
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/order/%d#shipments", id), http.StatusSeeOther)
+}
+
+// shipmentError turns the validation errors of a shipment into a message
+// for the staff member who entered it.
+func shipmentError(errs map[string]string) string {
+	messages := map[string]string{
+		"carrier":         "Choose a carrier",
+		"tracking_number": "Enter a tracking number of up to 50 characters",
+		"lines":           "Enter the quantity shipped of at least one product",
+	}
+
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	return messages[fields[0]]
+}
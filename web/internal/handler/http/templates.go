@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/Maksim-Kot/Commons/money"
 	catalogmodel "github.com/Maksim-Kot/Tech-store-catalog/pkg/model"
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/currency"
 	"github.com/Maksim-Kot/Tech-store-web/internal/model"
 	"github.com/Maksim-Kot/Tech-store-web/ui"
 )
@@ -14,15 +17,101 @@ import (
 type templateData struct {
 	CurrentYear     int
 	Categories      []*catalogmodel.Category
+	Category        *catalogmodel.Category
 	Products        []*catalogmodel.Product
 	Product         *processedProduct
 	Form            any
 	Flash           string
 	IsAuthenticated bool
+	IsStaff         bool
+	IsAdmin         bool
 	User            *model.User
-	Cart            *model.Cart
+	Cart            *model.CheckedCart
 	Orders          []*model.Order
 	Order           *model.Order
+	TOTPSecret      string
+	TOTPURI         string
+	RecoveryCodes   []string
+	Statuses        []string
+	Wishlists       []*model.Wishlist
+	Wishlist        *model.Wishlist
+	WishlistLines   []*model.WishlistLine
+	Addresses       []*model.Address
+	Address         *model.Address
+	Countries       map[string]string
+	Carriers        map[string]string
+	Payments        []*ordersmodel.Payment
+	Returns         []*model.Return
+	Return          *model.Return
+	Comments        []*ordersmodel.Comment
+	Shipments       []*model.Shipment
+	Quote           *ordersmodel.Quote
+	Coupons         []*ordersmodel.Coupon
+	Breakdown       *ordersmodel.Breakdown
+	Invoice         *ordersmodel.Invoice
+	Report          *reportView
+	// NextCursor pages through a list; FirstPage is set on its first page.
+	NextCursor string
+	FirstPage  bool
+	// NextPage and PrevPage link to the pages around the current one of a
+	// list that can be paged both ways.
+	NextPage string
+	PrevPage string
+	// Display is the currency prices are shown in, Currencies the ones the
+	// shopper can choose from.
+	Display    ordersmodel.Display
+	Currencies []string
+	currencies *currency.Table
+}
+
+// Base is the currency of the catalog and orders.
+func (d *templateData) Base() string {
+	return d.currencies.Base()
+}
+
+// Money formats an amount in the display currency. Amounts in the base
+// currency are converted at the display rate, amounts in other currencies go
+// through the base currency first. Amounts in a currency without a rate are
+// shown as they are.
+func (d *templateData) Money(amount money.Money) string {
+	if amount.Currency == "" {
+		amount.Currency = d.currencies.Base()
+	}
+	if amount.Currency == d.Display.Currency {
+		return amount.String()
+	}
+
+	base, err := d.currencies.Convert(amount, d.currencies.Base())
+	if err != nil {
+		return amount.String()
+	}
+	return base.Convert(d.Display.Rate, d.Display.Currency).String()
+}
+
+// MoneyAt formats an amount of an order in the display currency recorded
+// with the order, at the recorded rate. Orders placed before display
+// currencies were recorded are shown in their own currency.
+func (d *templateData) MoneyAt(amount money.Money, display ordersmodel.Display) string {
+	if display.Currency == "" || display.Rate <= 0 || display.Currency == amount.Currency {
+		return amount.String()
+	}
+	return amount.Convert(display.Rate, display.Currency).String()
+}
+
+// showOrder makes the page show amounts the way the shopper saw them when
+// the order was placed.
+func (d *templateData) showOrder(order *ordersmodel.Order) {
+	if order.Display.Currency == "" || order.Display.Rate <= 0 {
+		d.showBase()
+		return
+	}
+	d.Display = order.Display
+}
+
+// showBase makes the page show amounts in the base currency, the one orders
+// are settled in.
+func (d *templateData) showBase() {
+	d.Display = ordersmodel.Display{Currency: d.currencies.Base(), Rate: 1}
 }
 
 func humanDate(t time.Time) string {
@@ -31,6 +120,8 @@ func humanDate(t time.Time) string {
 
 var functions = template.FuncMap{
 	"humanDate": humanDate,
+	"amount":    money.New,
+	"itemName":  itemName,
 }
 
 func newTemplateCache() (map[string]*template.Template, error) {
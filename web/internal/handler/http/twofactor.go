@@ -0,0 +1,242 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// maxTwoFactorAttempts is how many wrong codes can be entered before the
+// password has to be given again.
+const maxTwoFactorAttempts = 5
+
+type twoFactorForm struct {
+	Code                string `form:"code"`
+	validator.Validator `form:"-"`
+}
+
+func (h *Handler) UserLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if h.SessionManager.GetInt64(r.Context(), "twoFactorUserID") == 0 {
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Form = twoFactorForm{}
+
+	h.render(w, http.StatusOK, "login_2fa.html", data)
+}
+
+// UserLoginTwoFactorPost completes a login with the second factor. The wrong
+// codes are counted in the session, and after maxTwoFactorAttempts of them the
+// pending login is dropped, so that the codes cannot be guessed one after
+// another.
+func (h *Handler) UserLoginTwoFactorPost(w http.ResponseWriter, r *http.Request) {
+	id := h.SessionManager.GetInt64(r.Context(), "twoFactorUserID")
+	if id == 0 {
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	var form twoFactorForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Code), "code", "This field cannot be blank")
+
+	if form.Valid() {
+		err = h.Ctrl.User.VerifySecondFactor(r.Context(), id, form.Code)
+		if err != nil {
+			if !errors.Is(err, controller.ErrInvalidCode) {
+				h.ServerError(w, err)
+				return
+			}
+			form.AddFieldError("code", "The code is incorrect or has expired")
+
+			attempts := h.SessionManager.GetInt64(r.Context(), "twoFactorAttempts") + 1
+			if attempts >= maxTwoFactorAttempts {
+				h.SessionManager.Remove(r.Context(), "twoFactorUserID")
+				h.SessionManager.Remove(r.Context(), "twoFactorAttempts")
+				h.SessionManager.Put(r.Context(), "flash", "Too many incorrect codes, please log in again")
+				http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+				return
+			}
+			h.SessionManager.Put(r.Context(), "twoFactorAttempts", attempts)
+		}
+	}
+
+	if !form.Valid() {
+		data := h.newTemplateData(r)
+		data.Form = form
+		h.render(w, http.StatusUnprocessableEntity, "login_2fa.html", data)
+		return
+	}
+
+	err = h.SessionManager.RenewToken(r.Context())
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Remove(r.Context(), "twoFactorUserID")
+	h.SessionManager.Remove(r.Context(), "twoFactorAttempts")
+
+	h.completeLogin(w, r, id)
+}
+
+func (h *Handler) AccountTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	user, err := h.Ctrl.User.Get(r.Context(), id)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	if user.TOTPEnabled {
+		h.SessionManager.Put(r.Context(), "flash", "Two-factor authentication is already enabled")
+		http.Redirect(w, r, "/account/view", http.StatusSeeOther)
+		return
+	}
+
+	secret, err := h.Ctrl.User.NewTOTPSecret()
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	uri, err := h.Ctrl.User.TOTPURI(r.Context(), id, secret)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "totpSecret", secret)
+
+	data := h.newTemplateData(r)
+	data.Form = twoFactorForm{}
+	data.TOTPSecret = secret
+	data.TOTPURI = uri
+
+	h.render(w, http.StatusOK, "twofactor_setup.html", data)
+}
+
+// AccountTwoFactorQRCode renders the otpauth URI of the secret that is
+// currently being enrolled as a PNG image.
+func (h *Handler) AccountTwoFactorQRCode(w http.ResponseWriter, r *http.Request) {
+	secret := h.SessionManager.GetString(r.Context(), "totpSecret")
+	if secret == "" {
+		h.NotFound(w)
+		return
+	}
+
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	uri, err := h.Ctrl.User.TOTPURI(r.Context(), id, secret)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(png)
+}
+
+func (h *Handler) AccountTwoFactorSetupPost(w http.ResponseWriter, r *http.Request) {
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	secret := h.SessionManager.GetString(r.Context(), "totpSecret")
+	if secret == "" {
+		http.Redirect(w, r, "/account/2fa/setup", http.StatusSeeOther)
+		return
+	}
+
+	var form twoFactorForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Code), "code", "This field cannot be blank")
+
+	var codes []string
+	if form.Valid() {
+		codes, err = h.Ctrl.User.ConfirmTOTP(r.Context(), id, secret, form.Code)
+		if err != nil {
+			if !errors.Is(err, controller.ErrInvalidCode) {
+				h.ServerError(w, err)
+				return
+			}
+			form.AddFieldError("code", "The code is incorrect or has expired")
+		}
+	}
+
+	if !form.Valid() {
+		uri, err := h.Ctrl.User.TOTPURI(r.Context(), id, secret)
+		if err != nil {
+			h.ServerError(w, err)
+			return
+		}
+
+		data := h.newTemplateData(r)
+		data.Form = form
+		data.TOTPSecret = secret
+		data.TOTPURI = uri
+		h.render(w, http.StatusUnprocessableEntity, "twofactor_setup.html", data)
+		return
+	}
+
+	h.SessionManager.Remove(r.Context(), "totpSecret")
+
+	data := h.newTemplateData(r)
+	data.RecoveryCodes = codes
+
+	h.render(w, http.StatusOK, "twofactor_recovery.html", data)
+}
+
+func (h *Handler) AccountTwoFactorDisablePost(w http.ResponseWriter, r *http.Request) {
+	id := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	var form twoFactorForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = h.Ctrl.User.DisableTOTP(r.Context(), id, form.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrInvalidCode):
+			h.SessionManager.Put(r.Context(), "flash", "The code is incorrect or has expired")
+		case errors.Is(err, controller.ErrNotFound):
+			h.SessionManager.Put(r.Context(), "flash", "Two-factor authentication is not enabled")
+		default:
+			h.ServerError(w, err)
+			return
+		}
+		http.Redirect(w, r, "/account/view", http.StatusSeeOther)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Two-factor authentication has been disabled")
+
+	http.Redirect(w, r, "/account/view", http.StatusSeeOther)
+}
@@ -0,0 +1,281 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/validator"
+)
+
+type wishlistForm struct {
+	Name                string `form:"name"`
+	validator.Validator `form:"-"`
+}
+
+func (h *Handler) Wishlists(w http.ResponseWriter, r *http.Request) {
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	wishlists, err := h.Ctrl.Wishlist.Wishlists(r.Context(), userID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Wishlists = wishlists
+	data.Form = wishlistForm{}
+
+	h.render(w, http.StatusOK, "wishlists.html", data)
+}
+
+func (h *Handler) WishlistCreatePost(w http.ResponseWriter, r *http.Request) {
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	var form wishlistForm
+
+	err := h.decodePostForm(r, &form)
+	if err != nil {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+
+	var id int64
+	if form.Valid() {
+		id, err = h.Ctrl.Wishlist.Create(r.Context(), userID, form.Name)
+		if err != nil {
+			if !errors.Is(err, controller.ErrDuplicateName) {
+				h.ServerError(w, err)
+				return
+			}
+			form.AddFieldError("name", "You already have a list with this name")
+		}
+	}
+
+	if !form.Valid() {
+		wishlists, err := h.Ctrl.Wishlist.Wishlists(r.Context(), userID)
+		if err != nil {
+			h.ServerError(w, err)
+			return
+		}
+
+		data := h.newTemplateData(r)
+		data.Wishlists = wishlists
+		data.Form = form
+		h.render(w, http.StatusUnprocessableEntity, "wishlists.html", data)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "List created")
+
+	http.Redirect(w, r, fmt.Sprintf("/account/wishlist/%d", id), http.StatusSeeOther)
+}
+
+func (h *Handler) Wishlist(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	wishlist, err := h.Ctrl.Wishlist.Wishlist(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	lines, err := h.Ctrl.Wishlist.Lines(r.Context(), wishlist)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	data := h.newTemplateData(r)
+	data.Wishlist = wishlist
+	data.WishlistLines = lines
+
+	h.render(w, http.StatusOK, "wishlist.html", data)
+}
+
+func (h *Handler) WishlistDeletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	err = h.Ctrl.Wishlist.Delete(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		case errors.Is(err, controller.ErrInvalidInput):
+			h.SessionManager.Put(r.Context(), "flash", "The default list cannot be deleted")
+			http.Redirect(w, r, fmt.Sprintf("/account/wishlist/%d", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "List deleted")
+
+	http.Redirect(w, r, "/account/wishlists", http.StatusSeeOther)
+}
+
+// WishlistAddPost saves a product in one of the user's lists. Without a
+// wishlist_id the product goes to the default list.
+func (h *Handler) WishlistAddPost(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(r.FormValue("product_id"), 10, 64)
+	if err != nil || productID < 1 {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	var wishlistID int64
+	if v := r.FormValue("wishlist_id"); v != "" {
+		wishlistID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || wishlistID < 1 {
+			h.ClientError(w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	err = h.Ctrl.Wishlist.AddItem(r.Context(), userID, wishlistID, productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Saved to your list")
+
+	http.Redirect(w, r, fmt.Sprintf("/product/%d", productID), http.StatusSeeOther)
+}
+
+func (h *Handler) WishlistRemovePost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	productID, err := strconv.ParseInt(r.FormValue("product_id"), 10, 64)
+	if err != nil || productID < 1 {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	err = h.Ctrl.Wishlist.RemoveItem(r.Context(), userID, id, productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Removed from your list")
+
+	http.Redirect(w, r, fmt.Sprintf("/account/wishlist/%d", id), http.StatusSeeOther)
+}
+
+// WishlistMovePost puts one unit of a saved product into the cart and
+// removes it from the list.
+func (h *Handler) WishlistMovePost(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		h.NotFound(w)
+		return
+	}
+
+	productID, err := strconv.ParseInt(r.FormValue("product_id"), 10, 64)
+	if err != nil || productID < 1 {
+		h.ClientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := h.SessionManager.GetInt64(r.Context(), "authenticatedUserID")
+
+	wishlist, err := h.Ctrl.Wishlist.Wishlist(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.NotFound(w)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	if !wishlist.Contains(productID) {
+		h.NotFound(w)
+		return
+	}
+
+	product, err := h.Ctrl.Catalog.ProductByID(r.Context(), productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrNotFound):
+			h.SessionManager.Put(r.Context(), "flash", "This product is no longer available")
+			http.Redirect(w, r, fmt.Sprintf("/account/wishlist/%d", id), http.StatusSeeOther)
+		default:
+			h.ServerError(w, err)
+		}
+		return
+	}
+
+	if product.Quantity < 1 {
+		h.SessionManager.Put(r.Context(), "flash", "This product is out of stock")
+		http.Redirect(w, r, fmt.Sprintf("/account/wishlist/%d", id), http.StatusSeeOther)
+		return
+	}
+
+	item := model.Item{
+		ID:       product.ID,
+		Name:     product.Name,
+		Quantity: 1,
+		Price:    product.Price,
+	}
+
+	err = h.Ctrl.Cart.AddItem(r.Context(), userID, item)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	err = h.Ctrl.Wishlist.RemoveItem(r.Context(), userID, id, productID)
+	if err != nil {
+		h.ServerError(w, err)
+		return
+	}
+
+	h.SessionManager.Put(r.Context(), "flash", "Moved to cart")
+
+	http.Redirect(w, r, fmt.Sprintf("/account/wishlist/%d", id), http.StatusSeeOther)
+}
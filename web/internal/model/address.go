@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// Address is an entry of a user's address book. The embedded address is what
+// gets copied onto an order at checkout.
+type Address struct {
+	ID        int64
+	UserID    int64
+	IsDefault bool
+	Created   time.Time
+	ordersmodel.Address
+}
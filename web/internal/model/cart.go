@@ -1,12 +1,120 @@
 package model
 
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
 type Item struct {
 	ID       int64
 	Name     string
 	Quantity int32
+	// Price is the unit price in the base currency at the time the item was
+	// put in the cart.
+	Price money.Money
+	// CategoryID and Weight are only known once the item has been checked
+	// against the catalog; they are not stored with the cart.
+	CategoryID int64
+	Weight     float64
 }
 
 type Cart struct {
 	UserID int64
 	Items  map[int64]Item
 }
+
+// Reorder is what adding the lines of a past order to the cart did. Added
+// counts the products put in the cart. Products that are no longer sold or
+// are sold out are left out; Capped ones were added in the quantity still
+// in stock.
+type Reorder struct {
+	Added   int
+	Gone    []string
+	SoldOut []string
+	Capped  []string
+}
+
+// CartLine is a cart item compared with the current state of the catalog.
+type CartLine struct {
+	Item
+	CurrentPrice money.Money
+	Available    int32
+	Missing      bool
+	PriceChanged bool
+	Capped       bool
+}
+
+func (l *CartLine) OutOfStock() bool {
+	return !l.Missing && l.Available == 0
+}
+
+// Changed reports whether the line differs from what the shopper saw when
+// they put the item in the cart.
+func (l *CartLine) Changed() bool {
+	return l.Missing || l.OutOfStock() || l.PriceChanged || l.Capped
+}
+
+// OrderQuantity is the quantity that can actually be ordered.
+func (l *CartLine) OrderQuantity() int32 {
+	if l.Missing {
+		return 0
+	}
+	return min(l.Quantity, l.Available)
+}
+
+func (l *CartLine) Total() money.Money {
+	return l.CurrentPrice.Mul(int64(l.OrderQuantity()))
+}
+
+// CheckedCart is a cart whose lines have been checked against the catalog.
+type CheckedCart struct {
+	UserID int64
+	Lines  []*CartLine
+}
+
+func NewCheckedCart(userID int64, lines []*CartLine) *CheckedCart {
+	slices.SortFunc(lines, func(a, b *CartLine) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return &CheckedCart{UserID: userID, Lines: lines}
+}
+
+func (c *CheckedCart) Changed() bool {
+	return slices.ContainsFunc(c.Lines, (*CartLine).Changed)
+}
+
+func (c *CheckedCart) Total() money.Money {
+	var total money.Money
+	for _, line := range c.Lines {
+		total = total.Add(line.Total())
+	}
+	return total
+}
+
+// Accepted returns the cart as it is after the shopper accepts every change:
+// unavailable lines are dropped, quantities are capped to the stock and
+// prices are updated to the current ones.
+func (c *CheckedCart) Accepted() *Cart {
+	cart := &Cart{
+		UserID: c.UserID,
+		Items:  make(map[int64]Item, len(c.Lines)),
+	}
+
+	for _, line := range c.Lines {
+		quantity := line.OrderQuantity()
+		if quantity < 1 {
+			continue
+		}
+
+		cart.Items[line.ID] = Item{
+			ID:       line.ID,
+			Name:     line.Name,
+			Quantity: quantity,
+			Price:    line.CurrentPrice,
+		}
+	}
+
+	return cart
+}
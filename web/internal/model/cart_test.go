@@ -0,0 +1,99 @@
+package model
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+func byn(amount int64) money.Money {
+	return money.New(amount, "BYN")
+}
+
+// checkedCart is a cart with one line of every kind Check produces.
+func checkedCart() *CheckedCart {
+	return NewCheckedCart(1, []*CartLine{
+		{Item: Item{ID: 9001, Name: "sold out", Quantity: 1, Price: byn(700)}, CurrentPrice: byn(700)},
+		{Item: Item{ID: 42, Name: "capped", Quantity: 5, Price: byn(2000)}, CurrentPrice: byn(2500), Available: 3, PriceChanged: true, Capped: true},
+		{Item: Item{ID: 777, Name: "missing", Quantity: 1, Price: byn(300)}, Missing: true},
+		{Item: Item{ID: 5, Name: "unchanged", Quantity: 2, Price: byn(1000)}, CurrentPrice: byn(1000), Available: 10},
+	})
+}
+
+func TestCartLine(t *testing.T) {
+	tests := []struct {
+		id         int64
+		changed    bool
+		outOfStock bool
+		quantity   int32
+		total      int64
+	}{
+		{5, false, false, 2, 2000},
+		{42, true, false, 3, 7500},
+		{777, true, false, 0, 0},
+		{9001, true, true, 0, 0},
+	}
+
+	lines := map[int64]*CartLine{}
+	for _, line := range checkedCart().Lines {
+		lines[line.ID] = line
+	}
+
+	for _, tt := range tests {
+		line := lines[tt.id]
+
+		if got := line.Changed(); got != tt.changed {
+			t.Errorf("line %d: Changed = %v, want %v", tt.id, got, tt.changed)
+		}
+		if got := line.OutOfStock(); got != tt.outOfStock {
+			t.Errorf("line %d: OutOfStock = %v, want %v", tt.id, got, tt.outOfStock)
+		}
+		if got := line.OrderQuantity(); got != tt.quantity {
+			t.Errorf("line %d: OrderQuantity = %d, want %d", tt.id, got, tt.quantity)
+		}
+		if got := line.Total(); got.Amount != tt.total {
+			t.Errorf("line %d: Total = %v, want %d", tt.id, got, tt.total)
+		}
+	}
+}
+
+func TestCheckedCart(t *testing.T) {
+	checked := checkedCart()
+
+	for i := 1; i < len(checked.Lines); i++ {
+		if checked.Lines[i-1].ID > checked.Lines[i].ID {
+			t.Fatalf("lines are not sorted by product ID")
+		}
+	}
+
+	if !checked.Changed() {
+		t.Error("Changed = false for a cart with changed lines")
+	}
+	if got := checked.Total(); got != byn(9500) {
+		t.Errorf("Total = %v, want 95.00 BYN", got)
+	}
+
+	want := map[int64]Item{
+		5:  {ID: 5, Name: "unchanged", Quantity: 2, Price: byn(1000)},
+		42: {ID: 42, Name: "capped", Quantity: 3, Price: byn(2500)},
+	}
+	accepted := checked.Accepted()
+	if accepted.UserID != 1 || !maps.Equal(accepted.Items, want) {
+		t.Errorf("Accepted = %+v, want %+v", accepted.Items, want)
+	}
+
+	if NewCheckedCart(1, accepted.lines()).Changed() {
+		t.Error("the accepted cart still has changes")
+	}
+}
+
+// lines checks the cart against a catalog in which every product is as the
+// cart has it.
+func (c *Cart) lines() []*CartLine {
+	lines := make([]*CartLine, 0, len(c.Items))
+	for _, item := range c.Items {
+		lines = append(lines, &CartLine{Item: item, CurrentPrice: item.Price, Available: item.Quantity})
+	}
+	return lines
+}
@@ -0,0 +1,16 @@
+package model
+
+// NotificationPreferences are the emails a user wants to get: a confirmation
+// when an order is placed and an update when its status changes. Users who
+// never changed them get both.
+type NotificationPreferences struct {
+	UserID      int64
+	OrderPlaced bool
+	OrderStatus bool
+}
+
+// DefaultNotificationPreferences returns the preferences of a user who never
+// changed them.
+func DefaultNotificationPreferences(userID int64) *NotificationPreferences {
+	return &NotificationPreferences{UserID: userID, OrderPlaced: true, OrderStatus: true}
+}
@@ -1,19 +1,47 @@
 package model
 
-import "time"
+import (
+	"time"
 
+	"github.com/Maksim-Kot/Commons/money"
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+)
+
+// Product is an order line.
 type Product struct {
 	ID         int64
 	Name       string
 	Quantity   int32
-	Price      float64
-	TotalPrice float64
+	Price      money.Money
+	TotalPrice money.Money
+	// Returnable is how many units can still be sent back.
+	Returnable int32
+	// Unshipped is how many units have not been shipped yet.
+	Unshipped int32
 }
 
 type Order struct {
 	ID        int64
+	UserID    int64
 	Products  []*Product
-	Price     float64
+	Price     money.Money
+	Breakdown ordersmodel.Breakdown
+	Display   ordersmodel.Display
 	Status    string
+	Address   *ordersmodel.Address
+	Promotion *ordersmodel.Promotion
+	Notes     ordersmodel.Notes
 	CreatedAt time.Time
 }
+
+// Shipment is a shipment together with the names of the shipped products.
+type Shipment struct {
+	*ordersmodel.Shipment
+	Products []*Product
+}
+
+// Return is a return request together with the name of the returned product.
+type Return struct {
+	*ordersmodel.Return
+	ProductName string
+}
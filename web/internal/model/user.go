@@ -1,11 +1,44 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleStaff    = "staff"
+	RoleAdmin    = "admin"
+)
 
 type User struct {
 	ID             int64
 	Name           string
 	Email          string
 	HashedPassword []byte
+	TOTPEnabled    bool
+	Role           string
 	Created        time.Time
 }
+
+// ErasedUserName is the name an erased user is left with.
+const ErasedUserName = "Deleted user"
+
+// ErasedEmail is the address an erased user is left with. It is unique, so
+// the account row can stay, and can never receive mail.
+func ErasedEmail(id int64) string {
+	return fmt.Sprintf("erased-%d@invalid", id)
+}
+
+// HasRole reports whether the user has any of the given roles.
+func (u *User) HasRole(roles ...string) bool {
+	return slices.Contains(roles, u.Role)
+}
+
+// Session is a session a user is logged in with. Keys are the names of the
+// values stored in it.
+type Session struct {
+	Expires time.Time
+	Keys    []string
+}
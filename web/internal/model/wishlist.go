@@ -0,0 +1,41 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Commons/money"
+)
+
+const DefaultWishlistName = "Saved for later"
+
+type Wishlist struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	IsDefault bool
+	Created   time.Time
+	Items     []WishlistItem
+}
+
+type WishlistItem struct {
+	ProductID int64
+	Added     time.Time
+}
+
+func (w *Wishlist) Contains(productID int64) bool {
+	return slices.ContainsFunc(w.Items, func(item WishlistItem) bool {
+		return item.ProductID == productID
+	})
+}
+
+// WishlistLine is a wishlist item with the current product data from the
+// catalog.
+type WishlistLine struct {
+	ProductID int64
+	Name      string
+	Price     money.Money
+	Available int32
+	Missing   bool
+	Added     time.Time
+}
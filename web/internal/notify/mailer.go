@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-web/config"
+)
+
+// Message is an email with a plain text and an HTML version of its body.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer returns the mailer the configuration asks for.
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Sink {
+	case "smtp":
+		if cfg.SMTP.Host == "" || cfg.From == "" {
+			return nil, fmt.Errorf("notify: smtp needs a host and a from address")
+		}
+		return &SMTPMailer{cfg: cfg.SMTP, from: cfg.From}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, err
+		}
+		return &FileMailer{dir: cfg.Dir, from: cfg.From}, nil
+	case "log", "":
+		return LogMailer{}, nil
+	default:
+		return nil, fmt.Errorf("notify: unknown mail sink %q", cfg.Sink)
+	}
+}
+
+// SMTPMailer sends emails through an SMTP server.
+type SMTPMailer struct {
+	cfg  config.SMTPConfig
+	from string
+}
+
+func (m *SMTPMailer) Send(_ context.Context, msg *Message) error {
+	body, err := msg.encode(m.from)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+	return smtp.SendMail(addr, auth, m.from, []string{msg.To}, body)
+}
+
+// FileMailer writes every email to an .eml file in a directory, where it can
+// be opened with a mail client. It is meant for development.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func (m *FileMailer) Send(_ context.Context, msg *Message) error {
+	body, err := msg.encode(m.from)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), sanitize(msg.To))
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return err
+	}
+
+	log.Printf("[notify] wrote email to %s: %s", msg.To, path)
+	return nil
+}
+
+// LogMailer only logs the emails it is given.
+type LogMailer struct{}
+
+func (LogMailer) Send(_ context.Context, msg *Message) error {
+	log.Printf("[notify] email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// encode formats the message as a multipart/alternative MIME email.
+func (msg *Message) encode(from string) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", headerValue.Replace(from))
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue.Replace(msg.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// headerValue drops line breaks, which would start a new header.
+var headerValue = strings.NewReplacer("\r", "", "\n", "")
+
+// sanitize keeps the characters of an address that are safe in a file name.
+func sanitize(address string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, address)
+}
@@ -0,0 +1,174 @@
+// Package notify emails users about their orders. The orders controller
+// reports orders placed and status changes; the notifier looks up the order
+// and its owner, checks the owner's notification preferences and sends a
+// templated email in the background, so the request that caused it is not
+// held up.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	ordersmodel "github.com/Maksim-Kot/Tech-store-orders/pkg/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// sendTimeout bounds the lookups and the delivery of one email.
+const sendTimeout = 30 * time.Second
+
+type orderSource interface {
+	OrderByID(ctx context.Context, id int64) (*ordersmodel.Order, error)
+}
+
+type userSource interface {
+	Get(ctx context.Context, id int64) (*model.User, error)
+	NotificationPreferences(ctx context.Context, userID int64) (*model.NotificationPreferences, error)
+}
+
+type Notifier struct {
+	mailer    Mailer
+	orders    orderSource
+	users     userSource
+	templates map[string]*emailTemplate
+	baseURL   string
+	wg        sync.WaitGroup
+}
+
+// New returns a notifier that sends emails through the mailer. Links in the
+// emails point at baseURL.
+func New(mailer Mailer, orders orderSource, users userSource, baseURL string) (*Notifier, error) {
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{
+		mailer:    mailer,
+		orders:    orders,
+		users:     users,
+		templates: templates,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+	}, nil
+}
+
+// OrderPlaced sends the order confirmation.
+func (n *Notifier) OrderPlaced(orderID int64) {
+	n.background(func(ctx context.Context) error {
+		return n.notify(ctx, orderID, "order_placed", "", func(p *model.NotificationPreferences) bool {
+			return p.OrderPlaced
+		})
+	})
+}
+
+// OrderStatusChanged tells the owner of the order about its new status.
+func (n *Notifier) OrderStatusChanged(orderID int64, status string) {
+	n.background(func(ctx context.Context) error {
+		return n.notify(ctx, orderID, "order_status", status, func(p *model.NotificationPreferences) bool {
+			return p.OrderStatus
+		})
+	})
+}
+
+// Close waits for the emails still being sent.
+func (n *Notifier) Close() {
+	n.wg.Wait()
+}
+
+// emailData is what the email templates are executed with. Status is the
+// status the email is about, which the order may have left by the time the
+// email is sent.
+type emailData struct {
+	User           *model.User
+	Order          *ordersmodel.Order
+	Status         string
+	StatusText     string
+	OrderURL       string
+	PreferencesURL string
+}
+
+func (n *Notifier) notify(ctx context.Context, orderID int64, name, status string, wants func(*model.NotificationPreferences) bool) error {
+	order, err := n.orders.OrderByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("order %d: %w", orderID, err)
+	}
+
+	prefs, err := n.users.NotificationPreferences(ctx, order.UserID)
+	if err != nil {
+		return fmt.Errorf("preferences of user %d: %w", order.UserID, err)
+	}
+	if !wants(prefs) {
+		return nil
+	}
+
+	user, err := n.users.Get(ctx, order.UserID)
+	if err != nil {
+		return fmt.Errorf("user %d: %w", order.UserID, err)
+	}
+
+	data := &emailData{
+		User:           user,
+		Order:          order,
+		Status:         status,
+		StatusText:     statusText(order.ID, status),
+		OrderURL:       fmt.Sprintf("%s/account/order/%d", n.baseURL, order.ID),
+		PreferencesURL: n.baseURL + "/account/notifications",
+	}
+
+	msg, err := n.templates[name].render(data)
+	if err != nil {
+		return fmt.Errorf("%s email: %w", name, err)
+	}
+	msg.To = user.Email
+
+	if err := n.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("sending %s email to user %d: %w", name, user.ID, err)
+	}
+
+	return nil
+}
+
+// background runs fn in a goroutine that Close waits for. Errors and panics
+// are logged: a failed email must never affect the order.
+func (n *Notifier) background(fn func(ctx context.Context) error) {
+	n.wg.Add(1)
+
+	go func() {
+		defer n.wg.Done()
+
+		defer func() {
+			if err := recover(); err != nil {
+				log.Printf("[notify] panic: %v", err)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+
+		if err := fn(ctx); err != nil {
+			log.Printf("[notify] %v", err)
+		}
+	}()
+}
+
+func statusText(orderID int64, status string) string {
+	switch status {
+	case ordersmodel.StatusPaid:
+		return fmt.Sprintf("We have received the payment for your order #%d.", orderID)
+	case ordersmodel.StatusProcessing:
+		return fmt.Sprintf("We are preparing your order #%d.", orderID)
+	case ordersmodel.StatusPartiallyShipped:
+		return fmt.Sprintf("Part of your order #%d is on its way; the rest will follow.", orderID)
+	case ordersmodel.StatusShipped:
+		return fmt.Sprintf("Good news: your order #%d has been shipped.", orderID)
+	case ordersmodel.StatusDelivered:
+		return fmt.Sprintf("Your order #%d has been delivered. Enjoy!", orderID)
+	case ordersmodel.StatusCancelled:
+		return fmt.Sprintf("Your order #%d has been cancelled.", orderID)
+	default:
+		return fmt.Sprintf("The status of your order #%d is now %s.", orderID, status)
+	}
+}
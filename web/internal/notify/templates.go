@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Maksim-Kot/Tech-store-web/ui"
+)
+
+// emailTemplate is an email in two versions. The .txt file defines the
+// "subject" and the plain "text" body; the .html file defines the "body" of
+// the HTML version, which is laid out by the "email" template of
+// email/base.html.
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+func parseTemplates() (map[string]*emailTemplate, error) {
+	templates := map[string]*emailTemplate{}
+
+	files, err := fs.Glob(ui.Files, "email/*.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".txt")
+
+		text, err := texttemplate.New(name).ParseFS(ui.Files, file)
+		if err != nil {
+			return nil, err
+		}
+
+		html, err := htmltemplate.New(name).ParseFS(ui.Files, "email/base.html", "email/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+
+		templates[name] = &emailTemplate{text: text, html: html}
+	}
+
+	return templates, nil
+}
+
+func (t *emailTemplate) render(data any) (*Message, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "email", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
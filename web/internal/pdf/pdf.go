@@ -0,0 +1,220 @@
+// Package pdf writes simple PDF documents: A4 pages with text set in the
+// standard Helvetica fonts and straight lines. The standard fonts are built
+// into every PDF reader, so nothing is embedded and the output stays small.
+//
+// Text is encoded in WinAnsi. Cyrillic letters are transliterated to Latin
+// ones and any other character the encoding lacks is printed as '?'.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard fonts a document can use.
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var fontNames = [...]string{Regular: "Helvetica", Bold: "Helvetica-Bold"}
+
+// Document is a PDF document being built page by page.
+type Document struct {
+	title string
+	pages []*Page
+}
+
+// New returns an empty document with the title shown by PDF readers.
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// Page is a page of a document. Coordinates are in points from the top left
+// corner of the page.
+type Page struct {
+	content bytes.Buffer
+}
+
+// AddPage appends a blank page to the document and returns it.
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text draws s with its baseline starting at x, y.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font+1, size, x, PageHeight-y, escape(encode(s)))
+}
+
+// TextRight draws s so that it ends at x.
+func (p *Page) TextRight(x, y float64, font Font, size float64, s string) {
+	p.Text(x-Width(s, font, size), y, font, size, s)
+}
+
+// Line draws a straight line of the given width from x1, y1 to x2, y2.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n",
+		width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Width returns the width of s in points when set in the font and size.
+func Width(s string, font Font, size float64) float64 {
+	widths := &helvetica
+	if font == Bold {
+		widths = &helveticaBold
+	}
+
+	var units int
+	for _, b := range encode(s) {
+		if b >= 32 && b < 127 {
+			units += int(widths[b-32])
+		} else {
+			units += 556
+		}
+	}
+
+	return float64(units) * size / 1000
+}
+
+// WriteTo writes the document to w. A document without pages gets one
+// blank page.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 4 are the catalog, the page tree, the two fonts and the
+	// document information; every page adds a page object and its content.
+	const firstPage = 6
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Tech Store) >>", escape(encode(d.title))))
+
+	for i, p := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// escape escapes the characters that end or break a PDF string literal.
+func escape(s []byte) []byte {
+	var out []byte
+	for _, b := range s {
+		switch b {
+		case '\\', '(', ')':
+			out = append(out, '\\', b)
+		case '\r', '\n':
+			out = append(out, ' ')
+		default:
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// encode converts s to WinAnsi.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x80 || (r >= 0xa0 && r <= 0xff) {
+			out = append(out, byte(r))
+			continue
+		}
+		if b, ok := winAnsi[r]; ok {
+			out = append(out, b)
+			continue
+		}
+		if latin, ok := cyrillic[r]; ok {
+			out = append(out, latin...)
+			continue
+		}
+		out = append(out, '?')
+	}
+	return out
+}
+
+// winAnsi maps the characters WinAnsi places in 0x80-0x9f.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+var cyrillic = func() map[rune]string {
+	upper := []string{
+		"A", "B", "V", "G", "D", "E", "Zh", "Z", "I", "Y", "K", "L", "M", "N", "O", "P",
+		"R", "S", "T", "U", "F", "Kh", "Ts", "Ch", "Sh", "Shch", "", "Y", "", "E", "Yu", "Ya",
+	}
+
+	m := map[rune]string{
+		'Ё': "Yo", 'ё': "yo", 'І': "I", 'і': "i", 'Ў': "U", 'ў': "u",
+	}
+	for i, latin := range upper {
+		m['А'+rune(i)] = latin
+		m['а'+rune(i)] = strings.ToLower(latin)
+	}
+	return m
+}()
+
+// Widths of the printable ASCII characters in thousandths of the font size,
+// from the Adobe font metrics of the standard fonts.
+var helvetica = [95]uint16{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBold = [95]uint16{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -6,4 +6,5 @@ var (
 	ErrNotFound           = errors.New("not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrDuplicateEmail     = errors.New("duplicate email")
+	ErrDuplicateName      = errors.New("duplicate name")
 )
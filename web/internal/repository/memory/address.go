@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+func (r *Repository) Addresses(_ context.Context, userID int64) ([]*model.Address, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var addresses []*model.Address
+	for _, a := range r.addresses {
+		if a.UserID == userID {
+			address := *a
+			addresses = append(addresses, &address)
+		}
+	}
+
+	slices.SortFunc(addresses, func(a, b *model.Address) int {
+		switch {
+		case a.IsDefault != b.IsDefault:
+			if a.IsDefault {
+				return -1
+			}
+			return 1
+		case !a.Created.Equal(b.Created):
+			return b.Created.Compare(a.Created)
+		default:
+			return int(b.ID - a.ID)
+		}
+	})
+
+	return addresses, nil
+}
+
+func (r *Repository) Address(_ context.Context, userID, id int64) (*model.Address, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	a, ok := r.addresses[id]
+	if !ok || a.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+
+	address := *a
+	return &address, nil
+}
+
+func (r *Repository) InsertAddress(_ context.Context, address *model.Address) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if address.IsDefault {
+		r.clearDefaultAddress(address.UserID)
+	}
+
+	id := int64(len(r.addresses) + 1)
+	for {
+		if _, exists := r.addresses[id]; !exists {
+			break
+		}
+		id++
+	}
+
+	address.ID = id
+	address.Created = time.Now()
+
+	stored := *address
+	r.addresses[id] = &stored
+
+	return nil
+}
+
+func (r *Repository) UpdateAddress(_ context.Context, address *model.Address) error {
+	r.Lock()
+	defer r.Unlock()
+
+	a, ok := r.addresses[address.ID]
+	if !ok || a.UserID != address.UserID {
+		return repository.ErrNotFound
+	}
+
+	a.Address = address.Address
+
+	return nil
+}
+
+func (r *Repository) DeleteAddress(_ context.Context, userID, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	a, ok := r.addresses[id]
+	if !ok || a.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.addresses, id)
+
+	return nil
+}
+
+func (r *Repository) SetDefaultAddress(_ context.Context, userID, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	a, ok := r.addresses[id]
+	if !ok || a.UserID != userID {
+		return repository.ErrNotFound
+	}
+
+	r.clearDefaultAddress(userID)
+	a.IsDefault = true
+
+	return nil
+}
+
+// clearDefaultAddress must be called with the lock held.
+func (r *Repository) clearDefaultAddress(userID int64) {
+	for _, a := range r.addresses {
+		if a.UserID == userID {
+			a.IsDefault = false
+		}
+	}
+}
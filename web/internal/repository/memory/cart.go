@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+func (r *Repository) Cart(_ context.Context, userID int64) (*model.Cart, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	cart := &model.Cart{
+		UserID: userID,
+		Items:  make(map[int64]model.Item, len(r.carts[userID])),
+	}
+	for id, item := range r.carts[userID] {
+		cart.Items[id] = item
+	}
+
+	return cart, nil
+}
+
+func (r *Repository) AddCartItem(_ context.Context, userID int64, item model.Item) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.carts[userID]; !ok {
+		r.carts[userID] = map[int64]model.Item{}
+	}
+
+	if existing, ok := r.carts[userID][item.ID]; ok {
+		item.Quantity += existing.Quantity
+	}
+	r.carts[userID][item.ID] = item
+
+	return nil
+}
+
+func (r *Repository) ReplaceCart(_ context.Context, cart *model.Cart) error {
+	r.Lock()
+	defer r.Unlock()
+
+	items := make(map[int64]model.Item, len(cart.Items))
+	for id, item := range cart.Items {
+		items[id] = item
+	}
+	r.carts[cart.UserID] = items
+
+	return nil
+}
+
+func (r *Repository) SetCartItemQuantity(_ context.Context, userID, productID int64, quantity int32) error {
+	r.Lock()
+	defer r.Unlock()
+
+	item, ok := r.carts[userID][productID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	item.Quantity = quantity
+	r.carts[userID][productID] = item
+
+	return nil
+}
+
+func (r *Repository) RemoveCartItem(_ context.Context, userID, productID int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.carts[userID][productID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.carts[userID], productID)
+
+	return nil
+}
+
+func (r *Repository) ClearCart(_ context.Context, userID int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.carts, userID)
+
+	return nil
+}
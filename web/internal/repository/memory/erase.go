@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (r *Repository) EraseUser(_ context.Context, id int64, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	user := r.userByID(id)
+	if user == nil {
+		return repository.ErrNotFound
+	}
+
+	delete(r.users, user.Email)
+	user.Name = model.ErasedUserName
+	user.Email = model.ErasedEmail(id)
+	user.HashedPassword = hashedPassword
+	user.TOTPEnabled = false
+	r.users[user.Email] = user
+
+	delete(r.totpSecrets, id)
+	delete(r.totpSteps, id)
+	delete(r.recoveryCodes, id)
+	delete(r.carts, id)
+	delete(r.notificationPreferences, id)
+
+	for wishlistID, w := range r.wishlists {
+		if w.UserID == id {
+			delete(r.wishlists, wishlistID)
+		}
+	}
+
+	for addressID, a := range r.addresses {
+		if a.UserID == id {
+			delete(r.addresses, addressID)
+		}
+	}
+
+	return nil
+}
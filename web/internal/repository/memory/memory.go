@@ -16,11 +16,33 @@ type Repository struct {
 	sync.RWMutex
 	// Contains email -> user instance
 	users map[string]*model.User
+	// Contains user ID -> TOTP secret
+	totpSecrets map[int64]string
+	// Contains user ID -> time step of the last accepted TOTP code
+	totpSteps map[int64]int64
+	// Contains user ID -> set of recovery code hashes
+	recoveryCodes map[int64]map[string]struct{}
+	// Contains user ID -> product ID -> cart item
+	carts map[int64]map[int64]model.Item
+	// Contains wishlist ID -> wishlist
+	wishlists map[int64]*model.Wishlist
+	// Contains address ID -> address
+	addresses map[int64]*model.Address
+	// Contains user ID -> notification preferences
+	notificationPreferences map[int64]model.NotificationPreferences
 }
 
 func New() (*Repository, error) {
 	return &Repository{
-		users: map[string]*model.User{},
+		users:         map[string]*model.User{},
+		totpSecrets:   map[int64]string{},
+		totpSteps:     map[int64]int64{},
+		recoveryCodes: map[int64]map[string]struct{}{},
+		carts:         map[int64]map[int64]model.Item{},
+		wishlists:     map[int64]*model.Wishlist{},
+		addresses:     map[int64]*model.Address{},
+
+		notificationPreferences: map[int64]model.NotificationPreferences{},
 	}, nil
 }
 
@@ -43,6 +65,7 @@ func (r *Repository) Insert(_ context.Context, name, email, password string) err
 		Name:           name,
 		Email:          email,
 		HashedPassword: hashedPassword,
+		Role:           model.RoleCustomer,
 		Created:        time.Now(),
 	}
 
@@ -101,3 +124,87 @@ func (r *Repository) Get(_ context.Context, id int64) (*model.User, error) {
 
 	return nil, repository.ErrNotFound
 }
+
+func (r *Repository) EnableTOTP(_ context.Context, id int64, secret string, recoveryCodes []string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	user := r.userByID(id)
+	if user == nil {
+		return repository.ErrNotFound
+	}
+
+	codes := make(map[string]struct{}, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		codes[code] = struct{}{}
+	}
+
+	user.TOTPEnabled = true
+	r.totpSecrets[id] = secret
+	delete(r.totpSteps, id)
+	r.recoveryCodes[id] = codes
+
+	return nil
+}
+
+func (r *Repository) DisableTOTP(_ context.Context, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if user := r.userByID(id); user != nil {
+		user.TOTPEnabled = false
+	}
+	delete(r.totpSecrets, id)
+	delete(r.totpSteps, id)
+	delete(r.recoveryCodes, id)
+
+	return nil
+}
+
+func (r *Repository) TOTPSecret(_ context.Context, id int64) (string, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	secret, ok := r.totpSecrets[id]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (r *Repository) UseTOTPStep(_ context.Context, id int64, step int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.totpSecrets[id]; !ok {
+		return repository.ErrNotFound
+	}
+	if last, ok := r.totpSteps[id]; ok && step <= last {
+		return repository.ErrNotFound
+	}
+	r.totpSteps[id] = step
+
+	return nil
+}
+
+func (r *Repository) UseRecoveryCode(_ context.Context, id int64, codeHash string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.recoveryCodes[id][codeHash]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.recoveryCodes[id], codeHash)
+
+	return nil
+}
+
+func (r *Repository) userByID(id int64) *model.User {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+func (r *Repository) NotificationPreferences(_ context.Context, userID int64) (*model.NotificationPreferences, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	prefs, ok := r.notificationPreferences[userID]
+	if !ok {
+		return model.DefaultNotificationPreferences(userID), nil
+	}
+
+	return &prefs, nil
+}
+
+func (r *Repository) SetNotificationPreferences(_ context.Context, prefs *model.NotificationPreferences) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.notificationPreferences[prefs.UserID] = *prefs
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+func (r *Repository) Wishlists(_ context.Context, userID int64) ([]*model.Wishlist, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var wishlists []*model.Wishlist
+	for _, w := range r.wishlists {
+		if w.UserID == userID {
+			wishlists = append(wishlists, copyWishlist(w))
+		}
+	}
+
+	slices.SortFunc(wishlists, func(a, b *model.Wishlist) int {
+		if a.IsDefault != b.IsDefault {
+			if a.IsDefault {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return wishlists, nil
+}
+
+func (r *Repository) Wishlist(_ context.Context, userID, id int64) (*model.Wishlist, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	w, ok := r.wishlists[id]
+	if !ok || w.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+
+	return copyWishlist(w), nil
+}
+
+func (r *Repository) CreateWishlist(_ context.Context, userID int64, name string, isDefault bool) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, w := range r.wishlists {
+		if w.UserID == userID && w.Name == name {
+			return 0, repository.ErrDuplicateName
+		}
+	}
+
+	id := int64(len(r.wishlists) + 1)
+	for {
+		if _, exists := r.wishlists[id]; !exists {
+			break
+		}
+		id++
+	}
+
+	r.wishlists[id] = &model.Wishlist{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		IsDefault: isDefault,
+		Created:   time.Now(),
+	}
+
+	return id, nil
+}
+
+func (r *Repository) DeleteWishlist(_ context.Context, userID, id int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	w, ok := r.wishlists[id]
+	if !ok || w.UserID != userID {
+		return repository.ErrNotFound
+	}
+	delete(r.wishlists, id)
+
+	return nil
+}
+
+func (r *Repository) AddWishlistItem(_ context.Context, wishlistID, productID int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	w, ok := r.wishlists[wishlistID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if w.Contains(productID) {
+		return nil
+	}
+
+	w.Items = append([]model.WishlistItem{{ProductID: productID, Added: time.Now()}}, w.Items...)
+
+	return nil
+}
+
+func (r *Repository) RemoveWishlistItem(_ context.Context, wishlistID, productID int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	w, ok := r.wishlists[wishlistID]
+	if !ok || !w.Contains(productID) {
+		return repository.ErrNotFound
+	}
+
+	w.Items = slices.DeleteFunc(w.Items, func(item model.WishlistItem) bool {
+		return item.ProductID == productID
+	})
+
+	return nil
+}
+
+func copyWishlist(w *model.Wishlist) *model.Wishlist {
+	c := *w
+	c.Items = slices.Clone(w.Items)
+	return &c
+}
@@ -0,0 +1,216 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+const addressColumns = `id, user_id, name, line1, line2, city, region, postal_code, country, phone, is_default, created`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAddress(row scanner) (*model.Address, error) {
+	var address model.Address
+
+	err := row.Scan(
+		&address.ID,
+		&address.UserID,
+		&address.Name,
+		&address.Line1,
+		&address.Line2,
+		&address.City,
+		&address.Region,
+		&address.PostalCode,
+		&address.Country,
+		&address.Phone,
+		&address.IsDefault,
+		&address.Created,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (r *Repository) Addresses(ctx context.Context, userID int64) ([]*model.Address, error) {
+	query := `
+		SELECT ` + addressColumns + `
+		FROM addresses
+		WHERE user_id = ?
+		ORDER BY is_default DESC, created DESC, id DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []*model.Address
+
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *Repository) Address(ctx context.Context, userID, id int64) (*model.Address, error) {
+	query := `
+		SELECT ` + addressColumns + `
+		FROM addresses
+		WHERE id = ? AND user_id = ?`
+
+	address, err := scanAddress(r.DB.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// InsertAddress stores a new address and sets its ID. If the address is the
+// default one, the previous default of the user is cleared in the same
+// transaction.
+func (r *Repository) InsertAddress(ctx context.Context, address *model.Address) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if address.IsDefault {
+		if err := clearDefaultAddress(ctx, tx, address.UserID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO addresses (user_id, name, line1, line2, city, region, postal_code, country, phone, is_default, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+
+	args := []any{
+		address.UserID,
+		address.Name,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		address.Phone,
+		address.IsDefault,
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	address.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) UpdateAddress(ctx context.Context, address *model.Address) error {
+	query := `
+		UPDATE addresses
+		SET name = ?, line1 = ?, line2 = ?, city = ?, region = ?, postal_code = ?, country = ?, phone = ?
+		WHERE id = ? AND user_id = ?`
+
+	args := []any{
+		address.Name,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		address.Phone,
+		address.ID,
+		address.UserID,
+	}
+
+	_, err := r.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	// MySQL reports zero affected rows when nothing changed, so existence is
+	// checked separately.
+	_, err = r.Address(ctx, address.UserID, address.ID)
+	return err
+}
+
+func (r *Repository) DeleteAddress(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM addresses WHERE id = ? AND user_id = ?`
+
+	res, err := r.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) SetDefaultAddress(ctx context.Context, userID, id int64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := clearDefaultAddress(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	query := `UPDATE addresses SET is_default = TRUE WHERE id = ? AND user_id = ?`
+
+	res, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func clearDefaultAddress(ctx context.Context, tx *sql.Tx, userID int64) error {
+	_, err := tx.ExecContext(ctx, `UPDATE addresses SET is_default = FALSE WHERE user_id = ?`, userID)
+	return err
+}
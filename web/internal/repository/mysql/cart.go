@@ -0,0 +1,151 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+)
+
+func (r *Repository) Cart(ctx context.Context, userID int64) (*model.Cart, error) {
+	query := `
+		SELECT product_id, name, quantity, price, currency
+		FROM cart_items
+		WHERE user_id = ?`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cart := &model.Cart{
+		UserID: userID,
+		Items:  make(map[int64]model.Item),
+	}
+
+	for rows.Next() {
+		var item model.Item
+		if err := rows.Scan(&item.ID, &item.Name, &item.Quantity, &item.Price, &item.Price.Currency); err != nil {
+			return nil, err
+		}
+		cart.Items[item.ID] = item
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (r *Repository) AddCartItem(ctx context.Context, userID int64, item model.Item) error {
+	query := `
+		INSERT INTO cart_items (user_id, product_id, name, quantity, price, currency, updated)
+		VALUES (?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())
+		ON DUPLICATE KEY UPDATE
+			quantity = quantity + VALUES(quantity),
+			name = VALUES(name),
+			price = VALUES(price),
+			currency = VALUES(currency),
+			updated = UTC_TIMESTAMP()`
+
+	_, err := r.DB.ExecContext(ctx, query, userID, item.ID, item.Name, item.Quantity, item.Price, item.Price.Currency)
+	return err
+}
+
+func (r *Repository) ReplaceCart(ctx context.Context, cart *model.Cart) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = ?`, cart.UserID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cart_items (user_id, product_id, name, quantity, price, currency, updated)
+		VALUES (?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range cart.Items {
+		_, err := stmt.ExecContext(ctx, cart.UserID, item.ID, item.Name, item.Quantity, item.Price, item.Price.Currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) SetCartItemQuantity(ctx context.Context, userID, productID int64, quantity int32) error {
+	query := `
+		UPDATE cart_items
+		SET quantity = ?, updated = UTC_TIMESTAMP()
+		WHERE user_id = ? AND product_id = ?`
+
+	res, err := r.DB.ExecContext(ctx, query, quantity, userID, productID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return r.cartItemExists(ctx, userID, productID)
+	}
+
+	return nil
+}
+
+func (r *Repository) RemoveCartItem(ctx context.Context, userID, productID int64) error {
+	query := `DELETE FROM cart_items WHERE user_id = ? AND product_id = ?`
+
+	res, err := r.DB.ExecContext(ctx, query, userID, productID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) ClearCart(ctx context.Context, userID int64) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = ?`, userID)
+	return err
+}
+
+// cartItemExists distinguishes a missing item from an update that did not
+// change anything, because MySQL reports zero affected rows for both.
+func (r *Repository) cartItemExists(ctx context.Context, userID, productID int64) error {
+	var exists bool
+
+	query := `SELECT EXISTS(SELECT true FROM cart_items WHERE user_id = ? AND product_id = ?)`
+
+	err := r.DB.QueryRowContext(ctx, query, userID, productID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
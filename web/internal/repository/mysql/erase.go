@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// EraseUser removes the personal data of the user. Their addresses, cart,
+// wishlists, notification preferences and two-factor setup are deleted, and
+// the account is renamed and given a random password that nobody knows. The
+// row stays because orders refer to its ID.
+func (r *Repository) EraseUser(ctx context.Context, id int64, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET name = ?, email = ?, hashed_password = ?, totp_secret = NULL, totp_last_step = NULL
+		WHERE id = ?`
+
+	res, err := tx.ExecContext(ctx, query, model.ErasedUserName, model.ErasedEmail(id), string(hashedPassword), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	queries := []string{
+		`DELETE FROM addresses WHERE user_id = ?`,
+		`DELETE FROM cart_items WHERE user_id = ?`,
+		`DELETE FROM wishlists WHERE user_id = ?`,
+		`DELETE FROM notification_preferences WHERE user_id = ?`,
+		`DELETE FROM user_recovery_codes WHERE user_id = ?`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
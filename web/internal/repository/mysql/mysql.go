@@ -114,12 +114,14 @@ func (r *Repository) Exists(ctx context.Context, id int64) (bool, error) {
 func (r *Repository) Get(ctx context.Context, id int64) (*model.User, error) {
 	var user model.User
 
-	query := `SELECT id, name, email, created FROM users WHERE id = ?`
+	query := `SELECT id, name, email, totp_secret IS NOT NULL, role, created FROM users WHERE id = ?`
 
 	err := r.DB.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
+		&user.TOTPEnabled,
+		&user.Role,
 		&user.Created,
 	)
 	if err != nil {
@@ -131,3 +133,127 @@ func (r *Repository) Get(ctx context.Context, id int64) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *Repository) EnableTOTP(ctx context.Context, id int64, secret string, recoveryCodes []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE users SET totp_secret = ?, totp_last_step = NULL WHERE id = ?`, secret, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, code := range recoveryCodes {
+		if _, err := stmt.ExecContext(ctx, id, code); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) DisableTOTP(ctx context.Context, id int64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET totp_secret = NULL, totp_last_step = NULL WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) TOTPSecret(ctx context.Context, id int64) (string, error) {
+	var secret sql.NullString
+
+	query := `SELECT totp_secret FROM users WHERE id = ?`
+
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&secret)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrNotFound
+		}
+		return "", err
+	}
+
+	if !secret.Valid {
+		return "", repository.ErrNotFound
+	}
+
+	return secret.String, nil
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code. It fails with
+// ErrNotFound unless the step is later than the last one recorded.
+func (r *Repository) UseTOTPStep(ctx context.Context, id int64, step int64) error {
+	query := `
+		UPDATE users
+		SET totp_last_step = ?
+		WHERE id = ? AND totp_secret IS NOT NULL
+		AND (totp_last_step IS NULL OR totp_last_step < ?)`
+
+	res, err := r.DB.ExecContext(ctx, query, step, id, step)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) UseRecoveryCode(ctx context.Context, id int64, codeHash string) error {
+	query := `DELETE FROM user_recovery_codes WHERE user_id = ? AND code_hash = ?`
+
+	res, err := r.DB.ExecContext(ctx, query, id, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+)
+
+// NotificationPreferences returns the user's preferences, or the defaults if
+// the user never changed them.
+func (r *Repository) NotificationPreferences(ctx context.Context, userID int64) (*model.NotificationPreferences, error) {
+	prefs := model.NotificationPreferences{UserID: userID}
+
+	query := `SELECT order_placed, order_status FROM notification_preferences WHERE user_id = ?`
+
+	err := r.DB.QueryRowContext(ctx, query, userID).Scan(&prefs.OrderPlaced, &prefs.OrderStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.DefaultNotificationPreferences(userID), nil
+		}
+		return nil, err
+	}
+
+	return &prefs, nil
+}
+
+func (r *Repository) SetNotificationPreferences(ctx context.Context, prefs *model.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, order_placed, order_status)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE order_placed = VALUES(order_placed), order_status = VALUES(order_status)`
+
+	_, err := r.DB.ExecContext(ctx, query, prefs.UserID, prefs.OrderPlaced, prefs.OrderStatus)
+	return err
+}
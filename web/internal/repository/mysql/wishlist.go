@@ -0,0 +1,197 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
+	"github.com/Maksim-Kot/Tech-store-web/internal/repository"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func (r *Repository) Wishlists(ctx context.Context, userID int64) ([]*model.Wishlist, error) {
+	query := `
+		SELECT id, user_id, name, is_default, created
+		FROM wishlists
+		WHERE user_id = ?
+		ORDER BY is_default DESC, name`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wishlists []*model.Wishlist
+	byID := map[int64]*model.Wishlist{}
+
+	for rows.Next() {
+		var wishlist model.Wishlist
+		err := rows.Scan(
+			&wishlist.ID,
+			&wishlist.UserID,
+			&wishlist.Name,
+			&wishlist.IsDefault,
+			&wishlist.Created,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		wishlists = append(wishlists, &wishlist)
+		byID[wishlist.ID] = &wishlist
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemsQuery := `
+		SELECT i.wishlist_id, i.product_id, i.added
+		FROM wishlist_items i
+		JOIN wishlists w ON w.id = i.wishlist_id
+		WHERE w.user_id = ?
+		ORDER BY i.added DESC`
+
+	itemRows, err := r.DB.QueryContext(ctx, itemsQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var wishlistID int64
+		var item model.WishlistItem
+		if err := itemRows.Scan(&wishlistID, &item.ProductID, &item.Added); err != nil {
+			return nil, err
+		}
+
+		if wishlist, ok := byID[wishlistID]; ok {
+			wishlist.Items = append(wishlist.Items, item)
+		}
+	}
+
+	if err = itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return wishlists, nil
+}
+
+func (r *Repository) Wishlist(ctx context.Context, userID, id int64) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+
+	query := `
+		SELECT id, user_id, name, is_default, created
+		FROM wishlists
+		WHERE id = ? AND user_id = ?`
+
+	err := r.DB.QueryRowContext(ctx, query, id, userID).Scan(
+		&wishlist.ID,
+		&wishlist.UserID,
+		&wishlist.Name,
+		&wishlist.IsDefault,
+		&wishlist.Created,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	itemsQuery := `
+		SELECT product_id, added
+		FROM wishlist_items
+		WHERE wishlist_id = ?
+		ORDER BY added DESC`
+
+	rows, err := r.DB.QueryContext(ctx, itemsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.WishlistItem
+		if err := rows.Scan(&item.ProductID, &item.Added); err != nil {
+			return nil, err
+		}
+		wishlist.Items = append(wishlist.Items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &wishlist, nil
+}
+
+func (r *Repository) CreateWishlist(ctx context.Context, userID int64, name string, isDefault bool) (int64, error) {
+	query := `
+		INSERT INTO wishlists (user_id, name, is_default, created)
+		VALUES (?, ?, ?, UTC_TIMESTAMP())`
+
+	res, err := r.DB.ExecContext(ctx, query, userID, name, isDefault)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) {
+			if mySQLError.Number == 1062 && strings.Contains(mySQLError.Message, "wishlists_uc_user_name") {
+				return 0, repository.ErrDuplicateName
+			}
+		}
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *Repository) DeleteWishlist(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM wishlists WHERE id = ? AND user_id = ?`
+
+	res, err := r.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) AddWishlistItem(ctx context.Context, wishlistID, productID int64) error {
+	query := `
+		INSERT IGNORE INTO wishlist_items (wishlist_id, product_id, added)
+		VALUES (?, ?, UTC_TIMESTAMP())`
+
+	_, err := r.DB.ExecContext(ctx, query, wishlistID, productID)
+	return err
+}
+
+func (r *Repository) RemoveWishlistItem(ctx context.Context, wishlistID, productID int64) error {
+	query := `DELETE FROM wishlist_items WHERE wishlist_id = ? AND product_id = ?`
+
+	res, err := r.DB.ExecContext(ctx, query, wishlistID, productID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
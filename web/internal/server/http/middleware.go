@@ -2,11 +2,13 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/Maksim-Kot/Tech-store-web/internal/contexkeys"
+	"github.com/Maksim-Kot/Tech-store-web/internal/controller"
 )
 
 func secureHeaders(next http.Handler) http.Handler {
@@ -68,17 +70,35 @@ func (s *Server) authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		exists, err := s.handler.Ctrl.User.UserExists(r.Context(), id)
+		user, err := s.handler.Ctrl.User.Get(r.Context(), id)
 		if err != nil {
+			if errors.Is(err, controller.ErrNotFound) {
+				next.ServeHTTP(w, r)
+				return
+			}
 			s.handler.ServerError(w, err)
 			return
 		}
 
-		if exists {
-			ctx := context.WithValue(r.Context(), contexkeys.IsAuthenticatedContextKey, true)
-			r = r.WithContext(ctx)
-		}
+		ctx := context.WithValue(r.Context(), contexkeys.IsAuthenticatedContextKey, true)
+		ctx = context.WithValue(ctx, contexkeys.UserRoleContextKey, user.Role)
+		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requireRole only lets through authenticated users that have one of the
+// given roles. It must be chained after requireAuthentication.
+func (s *Server) requireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.handler.HasRole(r, roles...) {
+				s.handler.ClientError(w, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
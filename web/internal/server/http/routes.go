@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 
+	"github.com/Maksim-Kot/Tech-store-web/internal/model"
 	"github.com/Maksim-Kot/Tech-store-web/ui"
 
 	"github.com/justinas/alice"
@@ -25,22 +26,86 @@ func (s *Server) routes() http.Handler {
 	router.Handle("POST /user/signup", dynamic.ThenFunc(s.handler.UserSignupPost))
 	router.Handle("GET /user/login", dynamic.ThenFunc(s.handler.UserLogin))
 	router.Handle("POST /user/login", dynamic.ThenFunc(s.handler.UserLoginPost))
+	router.Handle("GET /user/login/2fa", dynamic.ThenFunc(s.handler.UserLoginTwoFactor))
+	router.Handle("POST /user/login/2fa", dynamic.ThenFunc(s.handler.UserLoginTwoFactorPost))
 
 	router.Handle("GET /cart", dynamic.ThenFunc(s.handler.ShowCart))
 	router.Handle("POST /cart/add", dynamic.ThenFunc(s.handler.AddToCart))
 	router.Handle("GET /cart/remove/{id}", dynamic.ThenFunc(s.handler.RemoveFromCart))
+	router.Handle("POST /cart/update/{id}", dynamic.ThenFunc(s.handler.UpdateCart))
+	router.Handle("POST /cart/accept", dynamic.ThenFunc(s.handler.AcceptCartChanges))
+
+	router.Handle("POST /currency", dynamic.ThenFunc(s.handler.SetCurrencyPost))
 
 	protected := dynamic.Append(s.requireAuthentication)
 
 	router.Handle("GET /account/view", protected.ThenFunc(s.handler.AccountView))
+	router.Handle("GET /account/2fa/setup", protected.ThenFunc(s.handler.AccountTwoFactorSetup))
+	router.Handle("GET /account/2fa/qr.png", protected.ThenFunc(s.handler.AccountTwoFactorQRCode))
+	router.Handle("POST /account/2fa/setup", protected.ThenFunc(s.handler.AccountTwoFactorSetupPost))
+	router.Handle("POST /account/2fa/disable", protected.ThenFunc(s.handler.AccountTwoFactorDisablePost))
+	router.Handle("GET /account/notifications", protected.ThenFunc(s.handler.Notifications))
+	router.Handle("POST /account/notifications", protected.ThenFunc(s.handler.NotificationsPost))
 	router.Handle("GET /account/orders", protected.ThenFunc(s.handler.OrdersByUser))
 	router.Handle("GET /account/order/{id}", protected.ThenFunc(s.handler.Order))
+	router.Handle("GET /account/order/{id}/invoice", protected.ThenFunc(s.handler.OrderInvoice))
+	router.Handle("GET /account/order/{id}/invoice.pdf", protected.ThenFunc(s.handler.OrderInvoicePDF))
+	router.Handle("GET /account/order/{id}/pay", protected.ThenFunc(s.handler.OrderPayment))
+	router.Handle("POST /account/order/{id}/pay", protected.ThenFunc(s.handler.OrderPaymentPost))
+	router.Handle("POST /account/order/{id}/return", protected.ThenFunc(s.handler.OrderReturnPost))
+	router.Handle("POST /account/order/{id}/reorder", protected.ThenFunc(s.handler.OrderReorderPost))
+	router.Handle("GET /account/wishlists", protected.ThenFunc(s.handler.Wishlists))
+	router.Handle("POST /account/wishlists", protected.ThenFunc(s.handler.WishlistCreatePost))
+	router.Handle("GET /account/wishlist/{id}", protected.ThenFunc(s.handler.Wishlist))
+	router.Handle("POST /account/wishlist/{id}/delete", protected.ThenFunc(s.handler.WishlistDeletePost))
+	router.Handle("POST /account/wishlist/{id}/remove", protected.ThenFunc(s.handler.WishlistRemovePost))
+	router.Handle("POST /account/wishlist/{id}/move", protected.ThenFunc(s.handler.WishlistMovePost))
+	router.Handle("POST /wishlist/add", protected.ThenFunc(s.handler.WishlistAddPost))
+	router.Handle("GET /account/addresses", protected.ThenFunc(s.handler.Addresses))
+	router.Handle("POST /account/addresses", protected.ThenFunc(s.handler.AddressCreatePost))
+	router.Handle("GET /account/address/{id}", protected.ThenFunc(s.handler.Address))
+	router.Handle("POST /account/address/{id}", protected.ThenFunc(s.handler.AddressPost))
+	router.Handle("POST /account/address/{id}/delete", protected.ThenFunc(s.handler.AddressDeletePost))
+	router.Handle("POST /account/address/{id}/default", protected.ThenFunc(s.handler.AddressDefaultPost))
 
 	router.Handle("GET /orders/create", dynamic.ThenFunc(s.handler.CreateOrder))
 	router.Handle("POST /orders/create", dynamic.ThenFunc(s.handler.CreateOrderPost))
 
 	router.Handle("POST /user/logout", protected.ThenFunc(s.handler.UserLogoutPost))
 
+	staff := protected.Append(s.requireRole(model.RoleStaff, model.RoleAdmin))
+
+	router.Handle("GET /admin", staff.ThenFunc(s.handler.AdminDashboard))
+	router.Handle("GET /admin/orders", staff.ThenFunc(s.handler.AdminOrders))
+	router.Handle("GET /admin/order/{id}", staff.ThenFunc(s.handler.AdminOrder))
+	router.Handle("POST /admin/order/{id}/status", staff.ThenFunc(s.handler.AdminOrderStatusPost))
+	router.Handle("POST /admin/order/{id}/comments", staff.ThenFunc(s.handler.AdminOrderCommentPost))
+	router.Handle("POST /admin/order/{id}/shipments", staff.ThenFunc(s.handler.AdminOrderShipmentPost))
+	router.Handle("GET /admin/returns", staff.ThenFunc(s.handler.AdminReturns))
+	router.Handle("GET /admin/return/{id}", staff.ThenFunc(s.handler.AdminReturn))
+	router.Handle("POST /admin/return/{id}/approve", staff.ThenFunc(s.handler.AdminReturnApprovePost))
+	router.Handle("POST /admin/return/{id}/reject", staff.ThenFunc(s.handler.AdminReturnRejectPost))
+	router.Handle("POST /admin/return/{id}/restock", staff.ThenFunc(s.handler.AdminReturnRestockPost))
+
+	admin := protected.Append(s.requireRole(model.RoleAdmin))
+
+	router.Handle("GET /admin/categories", admin.ThenFunc(s.handler.AdminCategories))
+	router.Handle("POST /admin/categories", admin.ThenFunc(s.handler.AdminCategoryCreatePost))
+	router.Handle("GET /admin/category/{id}", admin.ThenFunc(s.handler.AdminCategory))
+	router.Handle("POST /admin/category/{id}", admin.ThenFunc(s.handler.AdminCategoryPost))
+	router.Handle("GET /admin/product/create", admin.ThenFunc(s.handler.AdminProductCreate))
+	router.Handle("POST /admin/product/create", admin.ThenFunc(s.handler.AdminProductCreatePost))
+	router.Handle("GET /admin/product/{id}", admin.ThenFunc(s.handler.AdminProduct))
+	router.Handle("POST /admin/product/{id}", admin.ThenFunc(s.handler.AdminProductPost))
+	router.Handle("GET /admin/coupons", admin.ThenFunc(s.handler.AdminCoupons))
+	router.Handle("POST /admin/coupons", admin.ThenFunc(s.handler.AdminCouponCreatePost))
+	router.Handle("POST /admin/coupon/{id}/active", admin.ThenFunc(s.handler.AdminCouponActivePost))
+	router.Handle("GET /admin/reports", admin.ThenFunc(s.handler.AdminReports))
+	router.Handle("GET /admin/reports/{name}/csv", admin.ThenFunc(s.handler.AdminReportCSV))
+	router.Handle("GET /admin/privacy", admin.ThenFunc(s.handler.AdminPrivacy))
+	router.Handle("GET /admin/privacy/export", admin.ThenFunc(s.handler.AdminPrivacyExport))
+	router.Handle("POST /admin/privacy/erase", admin.ThenFunc(s.handler.AdminPrivacyErasePost))
+
 	standard := alice.New(s.recoverPanic, logRequest, secureHeaders)
 
 	return standard.Then(router)
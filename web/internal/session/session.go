@@ -18,11 +18,16 @@ type Manager interface {
 	Put(ctx context.Context, key string, val any)
 	Get(ctx context.Context, key string) any
 	GetInt64(ctx context.Context, key string) int64
+	GetString(ctx context.Context, key string) string
 	PopString(ctx context.Context, key string) string
 	RenewToken(ctx context.Context) error
 	Remove(ctx context.Context, key string)
 	Exists(ctx context.Context, key string) bool
 	LoadAndSave(http.Handler) http.Handler
+	// UserSessions returns the sessions the user is logged in with.
+	UserSessions(ctx context.Context, userID int64) ([]*model.Session, error)
+	// DestroyUserSessions logs the user out of all their sessions.
+	DestroyUserSessions(ctx context.Context, userID int64) error
 }
 
 type scsManager struct {
@@ -63,6 +68,10 @@ func (m *scsManager) GetInt64(ctx context.Context, key string) int64 {
 	return m.sm.GetInt64(ctx, key)
 }
 
+func (m *scsManager) GetString(ctx context.Context, key string) string {
+	return m.sm.GetString(ctx, key)
+}
+
 func (m *scsManager) PopString(ctx context.Context, key string) string {
 	return m.sm.PopString(ctx, key)
 }
@@ -82,3 +91,33 @@ func (m *scsManager) Exists(ctx context.Context, key string) bool {
 func (m *scsManager) LoadAndSave(next http.Handler) http.Handler {
 	return m.sm.LoadAndSave(next)
 }
+
+// UserSessions goes through every stored session, so it is only meant for
+// rare jobs such as data access requests.
+func (m *scsManager) UserSessions(ctx context.Context, userID int64) ([]*model.Session, error) {
+	var sessions []*model.Session
+
+	err := m.sm.Iterate(ctx, func(ctx context.Context) error {
+		if m.sm.GetInt64(ctx, "authenticatedUserID") == userID {
+			sessions = append(sessions, &model.Session{
+				Expires: m.sm.Deadline(ctx),
+				Keys:    m.sm.Keys(ctx),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (m *scsManager) DestroyUserSessions(ctx context.Context, userID int64) error {
+	return m.sm.Iterate(ctx, func(ctx context.Context) error {
+		if m.sm.GetInt64(ctx, "authenticatedUserID") == userID {
+			return m.sm.Destroy(ctx)
+		}
+		return nil
+	})
+}
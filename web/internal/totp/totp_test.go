@@ -0,0 +1,122 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, base32-encoded.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238(t *testing.T) {
+	// The vectors of RFC 6238, Appendix B, are 8 digits long. The 6-digit
+	// code is the same value modulo 10^6, which is its last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; got != want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", time.Unix(59, 0)); err == nil {
+		t.Error("Code accepted an invalid secret")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	// now is the first second of its time step, so a step starts at
+	// now+k*Period and ends a second before the next one.
+	now := time.Unix(1111111110, 0)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		valid  bool
+	}{
+		{"current step", 0, true},
+		{"end of the current step", Period - time.Second, true},
+		{"start of the previous step", -Skew * Period, true},
+		{"end of the step before the previous one", -Skew*Period - time.Second, false},
+		{"end of the next step", (Skew+1)*Period - time.Second, true},
+		{"start of the step after the next one", (Skew + 1) * Period, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, now.Add(tt.offset))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := Validate(rfcSecret, code, now); got != tt.valid {
+				t.Errorf("Validate = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestMatchStep(t *testing.T) {
+	now := time.Unix(1111111110, 0)
+
+	for i := -Skew; i <= Skew; i++ {
+		at := now.Add(time.Duration(i) * Period)
+
+		code, err := Code(rfcSecret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step, ok := Match(rfcSecret, code, now)
+		if !ok || step != counter(at) {
+			t.Errorf("Match of the code %d steps away = %d, %v, want %d, true", i, step, ok, counter(at))
+		}
+	}
+}
+
+func TestValidateMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		valid  bool
+	}{
+		{"surrounding spaces", rfcSecret, " " + code + " ", true},
+		{"lower-case secret with spaces", "gezd gnbv gy3t qojq gezd gnbv gy3t qojq", code, true},
+		{"too short", rfcSecret, code[1:], false},
+		{"too long", rfcSecret, code + "0", false},
+		{"empty", rfcSecret, "", false},
+		{"invalid secret", "not base32!", code, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validate(tt.secret, tt.code, now); got != tt.valid {
+				t.Errorf("Validate(%q, %q) = %v, want %v", tt.secret, tt.code, got, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE cart_items (
+    user_id INTEGER NOT NULL,
+    product_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL,
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'BYN',
+    updated DATETIME NOT NULL,
+    PRIMARY KEY (user_id, product_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+CREATE TABLE notification_preferences (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    order_placed BOOLEAN NOT NULL DEFAULT TRUE,
+    order_status BOOLEAN NOT NULL DEFAULT TRUE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -3,7 +3,17 @@ CREATE TABLE users (
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL,
     hashed_password CHAR(60) NOT NULL,
+    totp_secret VARCHAR(64) NULL,
+    totp_last_step BIGINT NULL,
+    role ENUM('customer', 'staff', 'admin') NOT NULL DEFAULT 'customer',
     created DATETIME NOT NULL
 );
 
-ALTER TABLE users ADD CONSTRAINT users_uc_email UNIQUE (email);
\ No newline at end of file
+ALTER TABLE users ADD CONSTRAINT users_uc_email UNIQUE (email);
+
+CREATE TABLE user_recovery_codes (
+    user_id INTEGER NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    PRIMARY KEY (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
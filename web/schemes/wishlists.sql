@@ -0,0 +1,18 @@
+CREATE TABLE wishlists (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+ALTER TABLE wishlists ADD CONSTRAINT wishlists_uc_user_name UNIQUE (user_id, name);
+
+CREATE TABLE wishlist_items (
+    wishlist_id INTEGER NOT NULL,
+    product_id BIGINT NOT NULL,
+    added DATETIME NOT NULL,
+    PRIMARY KEY (wishlist_id, product_id),
+    FOREIGN KEY (wishlist_id) REFERENCES wishlists(id) ON DELETE CASCADE
+);